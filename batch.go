@@ -0,0 +1,95 @@
+package urlvalues
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// isBatchTarget reports whether v is a pointer to a slice of structs or struct
+// pointers.
+func isBatchTarget(v reflect.Value) bool {
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return false
+	}
+	elem := v.Elem().Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct
+}
+
+// unmarshalBatch decodes the items of indexed keys into the slice pointed to
+// by v. See Unmarshal for details.
+func unmarshalBatch(in input, keys []string, v reflect.Value, pOpts *ParseOptions) error {
+	indexes := batchIndexes(keys, pOpts.prefix)
+	if err := checkSliceLen(len(indexes), fieldOptions{}, *pOpts); err != nil {
+		key := pOpts.prefix + "[]"
+		return renderParseError(&ParseError{
+			Key: key,
+			fe: &FieldError{
+				typeName: v.Elem().Type().String(),
+				value:    key,
+				err:      err,
+			},
+		}, pOpts)
+	}
+
+	sl := v.Elem()
+	elemType := sl.Type().Elem()
+	items := reflect.MakeSlice(sl.Type(), 0, len(indexes))
+
+	var errs Errors
+	for _, index := range indexes {
+		itemOpts := *pOpts
+		itemOpts.prefix = fmt.Sprintf("%s[%d].", pOpts.prefix, index)
+
+		var item reflect.Value
+		if elemType.Kind() == reflect.Ptr {
+			item = reflect.New(elemType.Elem())
+		} else {
+			item = reflect.New(elemType)
+		}
+		if err := unmarshal(in, item.Interface(), &itemOpts); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			items = reflect.Append(items, item)
+		} else {
+			items = reflect.Append(items, item.Elem())
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	sl.Set(items)
+	return nil
+}
+
+// batchIndexes returns the distinct indexes of keys on the form
+// "prefix[index].name", in increasing order.
+func batchIndexes(keys []string, prefix string) []int {
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		rest, ok := strings.CutPrefix(key, prefix+"[")
+		if !ok {
+			continue
+		}
+		index, rest, ok := strings.Cut(rest, "].")
+		if !ok || rest == "" {
+			continue
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	slices.Sort(indexes)
+	return slices.Compact(indexes)
+}
@@ -0,0 +1,113 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshal_Batch(t *testing.T) {
+	type Item struct {
+		Name     string `urlvalue:"name"`
+		Quantity int    `urlvalue:"qty,default:1"`
+	}
+
+	in := url.Values{
+		"items[0].name": {"apple"},
+		"items[0].qty":  {"3"},
+		"items[7].name": {"pear"},
+		"items[2].name": {"banana"},
+		"items[x].name": {"ignored"},
+		"other":         {"ignored"},
+	}
+
+	t.Run("structs", func(t *testing.T) {
+		want := []Item{{"apple", 3}, {"banana", 1}, {"pear", 1}}
+
+		var got []Item
+		if err := urlvalues.Unmarshal(in, &got, urlvalues.WithPrefix("items")); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("struct pointers", func(t *testing.T) {
+		want := []*Item{{"apple", 3}, {"banana", 1}, {"pear", 1}}
+
+		var got []*Item
+		if err := urlvalues.Unmarshal(in, &got, urlvalues.WithPrefix("items")); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		in := url.Values{
+			"[0].qty": {"many"},
+			"[1].qty": {"2"},
+			"[2].qty": {"few"},
+		}
+
+		var got []Item
+		err := urlvalues.Unmarshal(in, &got)
+
+		var errs urlvalues.Errors
+		if !errors.As(err, &errs) || len(errs) != 2 {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want 2 urlvalues.Errors", in, &got, err)
+		}
+		var parseErr *urlvalues.ParseError
+		if !errors.As(errs[1], &parseErr) || parseErr.Key != "[2].qty" {
+			t.Errorf("second error = %v, want parse error of key [2].qty", errs[1])
+		}
+		if got != nil {
+			t.Errorf("got = %v, want <nil>", got)
+		}
+	})
+}
+
+func TestUnmarshal_BatchMaxSliceLen(t *testing.T) {
+	type Item struct {
+		Name string `urlvalue:"name"`
+	}
+
+	in := url.Values{
+		"items[0].name": {"a"},
+		"items[1].name": {"b"},
+		"items[1].qty":  {"2"},
+		"items[5].name": {"c"},
+	}
+
+	var got []Item
+	err := urlvalues.Unmarshal(in, &got, urlvalues.WithPrefix("items"), urlvalues.WithMaxSliceLen(2))
+
+	var tooMany *urlvalues.TooManyValuesError
+	if !errors.As(err, &tooMany) {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.TooManyValuesError", in, &got, err)
+	}
+	if diff := cmp.Diff(tooMany, &urlvalues.TooManyValuesError{Max: 2, Count: 3}); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+	}
+	var parseErr *urlvalues.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Key != "items[]" {
+		t.Errorf("urlvalues.Unmarshal(...) = %v, want parse error of key items[]", err)
+	}
+	if got != nil {
+		t.Errorf("got = %v, want <nil>", got)
+	}
+
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithPrefix("items"), urlvalues.WithMaxSliceLen(3)); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if len(got) != 3 {
+		t.Errorf("len(got) = %d, want 3", len(got))
+	}
+}
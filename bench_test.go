@@ -0,0 +1,109 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nahojer/urlvalues"
+)
+
+func BenchmarkUnmarshal(b *testing.B) {
+	type Target struct {
+		Page     int           `urlvalue:"page,default:1"`
+		PerPage  uint          `urlvalue:"per_page,default:20"`
+		Query    string        `urlvalue:"q"`
+		Ratio    float64       `urlvalue:"ratio"`
+		Verbose  bool          `urlvalue:"verbose"`
+		Since    time.Time     `urlvalue:"since,layout:RFC3339"`
+		Timeout  time.Duration `urlvalue:"timeout"`
+		Language *string       `urlvalue:"lang"`
+	}
+
+	in := url.Values{
+		"page":     {"3"},
+		"per_page": {"50"},
+		"q":        {"gopher"},
+		"ratio":    {"0.75"},
+		"verbose":  {"true"},
+		"since":    {"2023-01-02T15:04:05Z"},
+		"timeout":  {"5s"},
+		"lang":     {"sv"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal_Slices(b *testing.B) {
+	type Target struct {
+		IDs  []int    `urlvalue:"ids"`
+		Tags []string `urlvalue:"tags"`
+	}
+
+	in := url.Values{
+		"ids":  {"1;2;3;4;5;6;7;8"},
+		"tags": {"a", "b", "c", "d"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestUnmarshal_Allocs guards the allocations of the benchmarked fast paths
+// against regressions.
+func TestUnmarshal_Allocs(t *testing.T) {
+	type Scalars struct {
+		Page    int           `urlvalue:"page,default:1"`
+		Query   string        `urlvalue:"q"`
+		Ratio   float64       `urlvalue:"ratio"`
+		Since   time.Time     `urlvalue:"since,layout:RFC3339"`
+		Timeout time.Duration `urlvalue:"timeout"`
+	}
+	type Slices struct {
+		IDs  []int    `urlvalue:"ids"`
+		Tags []string `urlvalue:"tags"`
+	}
+
+	tests := []struct {
+		name      string
+		in        url.Values
+		newTarget func() any
+		max       float64
+	}{
+		{
+			"scalars",
+			url.Values{"page": {"3"}, "q": {"gopher"}, "ratio": {"0.75"}, "since": {"2023-01-02T15:04:05Z"}, "timeout": {"5s"}},
+			func() any { return new(Scalars) },
+			7,
+		},
+		{
+			"slices",
+			url.Values{"ids": {"1;2;3;4;5;6;7;8"}, "tags": {"a", "b", "c", "d"}},
+			func() any { return new(Slices) },
+			15,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testing.AllocsPerRun(100, func() {
+				if err := urlvalues.Unmarshal(tt.in, tt.newTarget()); err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, ...) = %q, want <nil>", tt.in, err)
+				}
+			})
+			if got > tt.max {
+				t.Errorf("urlvalues.Unmarshal(%v, ...) allocs = %v, want <= %v", tt.in, got, tt.max)
+			}
+		})
+	}
+}
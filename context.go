@@ -0,0 +1,28 @@
+package urlvalues
+
+import (
+	"context"
+	"net/url"
+)
+
+// TextUnmarshalerContext is implemented by types that decode themselves from
+// text, like [encoding.TextUnmarshaler], but need the context of the call,
+// for example to look up settings of a tenant within the deadline of the
+// request. It takes precedence over [encoding.TextUnmarshaler].
+type TextUnmarshalerContext interface {
+	UnmarshalTextContext(ctx context.Context, text []byte) error
+}
+
+// UnmarshalContext unmarshals data into the value pointed to by v like
+// [Unmarshal], passing ctx to the UnmarshalTextContext methods of fields
+// implementing [TextUnmarshalerContext]. Decoding stops as soon as ctx is
+// done, in which case the error of ctx is returned.
+//
+// [UnmarshalRequest] and [Bind] use the context of the request.
+func UnmarshalContext(ctx context.Context, data url.Values, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	pOpts.ctx = ctx
+	defer pOpts.observe(v)(&err)
+
+	return unmarshalValues(data, v, pOpts)
+}
@@ -0,0 +1,64 @@
+package urlvalues_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+type tenantKey struct{}
+
+// tenantName is decoded with the tenant found in the context as prefix.
+type tenantName string
+
+func (n *tenantName) UnmarshalTextContext(ctx context.Context, text []byte) error {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	if !ok {
+		return errors.New("no tenant")
+	}
+	*n = tenantName(tenant + "/" + string(text))
+	return nil
+}
+
+func TestUnmarshalContext(t *testing.T) {
+	type Target struct {
+		Name  tenantName   `urlvalue:"name"`
+		Names []tenantName `urlvalue:"names"`
+	}
+
+	in := url.Values{"name": {"alice"}, "names": {"bob;carol"}}
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	want := Target{Name: "acme/alice", Names: []tenantName{"acme/bob", "acme/carol"}}
+
+	var got Target
+	if err := urlvalues.UnmarshalContext(ctx, in, &got); err != nil {
+		t.Fatalf("urlvalues.UnmarshalContext(%v, %v, %v) = %q, want <nil>", ctx, in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.UnmarshalContext(...) -got +want\n%s", diff)
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		var got Target
+		if err := urlvalues.UnmarshalContext(ctx, in, &got); !errors.Is(err, context.Canceled) {
+			t.Fatalf("urlvalues.UnmarshalContext(%v, %v, %v) = %v, want %q", ctx, in, &got, err, context.Canceled)
+		}
+		if diff := cmp.Diff(got, Target{}); diff != "" {
+			t.Errorf("urlvalues.UnmarshalContext(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("without context", func(t *testing.T) {
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+		}
+	})
+}
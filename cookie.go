@@ -0,0 +1,38 @@
+package urlvalues
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// UnmarshalCookies unmarshals the values of cookies into the value pointed to
+// by v. Cookies are keyed by their name. Cookies sharing the same name are
+// decoded like repeated URL values. Fields with the "source:cookie" option are
+// decoded from cookies as well.
+//
+// The cookies of an incoming request are obtained by [http.Request.Cookies].
+//
+// See [Unmarshal] for details on how the cookie values are decoded.
+func UnmarshalCookies(cookies []*http.Cookie, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	defer pOpts.observe(v)(&err)
+
+	data := cookieValues(cookies)
+	values, keys := valuesLookup(data), valuesKeys(data)
+	in := input{
+		values:     values,
+		sources:    map[string]lookup{sourceCookie: values},
+		valueKeys:  keys,
+		sourceKeys: map[string]keyLister{sourceCookie: keys},
+	}
+	return unmarshalInput(in, v, pOpts)
+}
+
+// cookieValues returns the values of cookies keyed by their name.
+func cookieValues(cookies []*http.Cookie) url.Values {
+	data := make(url.Values, len(cookies))
+	for _, c := range cookies {
+		data.Add(c.Name, c.Value)
+	}
+	return data
+}
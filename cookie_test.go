@@ -0,0 +1,51 @@
+package urlvalues_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshalCookies(t *testing.T) {
+	type Target struct {
+		Theme    string `urlvalue:"theme,default:light"`
+		PageSize int    `urlvalue:"page_size,default:20"`
+		Locale   string `urlvalue:"locale,default:en"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	r.AddCookie(&http.Cookie{Name: "page_size", Value: "50"})
+	want := Target{Theme: "dark", PageSize: 50, Locale: "en"}
+
+	var got Target
+	if err := urlvalues.UnmarshalCookies(r.Cookies(), &got); err != nil {
+		t.Fatalf("urlvalues.UnmarshalCookies(%v, %v) = %q, want <nil>", r.Cookies(), &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.UnmarshalCookies(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalCookies_Source(t *testing.T) {
+	type Target struct {
+		Session string `urlvalue:"session,source:cookie"`
+		Theme   string `urlvalue:"theme"`
+	}
+
+	cookies := []*http.Cookie{{Name: "session", Value: "abc"}, {Name: "theme", Value: "dark"}}
+	want := Target{Session: "abc", Theme: "dark"}
+
+	var got Target
+	if err := urlvalues.UnmarshalCookies(cookies, &got); err != nil {
+		t.Fatalf("urlvalues.UnmarshalCookies(%v, %v) = %q, want <nil>", cookies, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.UnmarshalCookies(...) -got +want\n%s", diff)
+	}
+}
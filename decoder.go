@@ -0,0 +1,150 @@
+package urlvalues
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Decoder unmarshals values with a set of options, so that the options don't
+// have to be passed at every call site. A Decoder is typically configured once
+// at startup, and specialized per route with [Decoder.With]:
+//
+//	base := urlvalues.NewDecoder(urlvalues.WithStrictNumbers())
+//	search := base.With(urlvalues.WithPrefix("q."))
+//
+// The options of a Decoder never change once it is created, and validators are
+// registered copy-on-write, so a Decoder is safe for concurrent use by multiple
+// goroutines, and deriving decoders from it never affects it or calls in
+// flight. The state shared by all calls, such as the cached metadata of struct
+// types and the types registered by [Register] and [RegisterEnum], is guarded
+// as well. Functions and values passed as options, such as a
+// [WarningHandlerFunc] or [Metrics], must be safe for concurrent use if the
+// Decoder is used concurrently. The zero Decoder decodes with the default
+// options.
+type Decoder struct {
+	setParseOpts []SetParseOptionFunc
+	// Validators by name. The map is replaced rather than modified, so that
+	// it can be read without locking and shared by copies of the Decoder.
+	validators atomic.Pointer[map[string]ValidatorFunc]
+	// Serializes registrations of validators.
+	mu sync.Mutex
+}
+
+// ValidatorFunc checks a value of a field, returning an error describing why
+// the value is invalid, if it is. See [Decoder.RegisterValidation].
+type ValidatorFunc func(value string) error
+
+// NewDecoder returns a Decoder unmarshaling with the options set by
+// setParseOpts.
+func NewDecoder(setParseOpts ...SetParseOptionFunc) *Decoder {
+	return &Decoder{setParseOpts: slices.Clone(setParseOpts)}
+}
+
+// Clone returns a copy of d, including the validators registered on d.
+func (d *Decoder) Clone() *Decoder {
+	return d.derive(slices.Clone(d.setParseOpts))
+}
+
+// With returns a copy of d with the options set by setParseOpts applied after
+// the options of d, which thereby take precedence. d is left untouched.
+func (d *Decoder) With(setParseOpts ...SetParseOptionFunc) *Decoder {
+	return d.derive(append(slices.Clip(d.setParseOpts), setParseOpts...))
+}
+
+// derive returns a Decoder with the options set by setParseOpts and the
+// validators of d.
+func (d *Decoder) derive(setParseOpts []SetParseOptionFunc) *Decoder {
+	derived := &Decoder{setParseOpts: setParseOpts}
+	derived.validators.Store(d.validators.Load())
+	return derived
+}
+
+// RegisterValidation registers fn as the validator named name, replacing any
+// validator registered under the same name. Fields name validators by the
+// "validate" tag option, which holds one or more names separated by vertical
+// bars (|):
+//
+//	d.RegisterValidation("slug", func(value string) error {
+//		if !slugPattern.MatchString(value) {
+//			return errors.New("must be a slug, such as my-post")
+//		}
+//		return nil
+//	})
+//
+//	// Field is decoded from a value accepted by the slug validator.
+//	Field string `urlvalue:"myName,validate:slug"`
+//
+// Validators are passed each value of a field before it is parsed, after the
+// "trim", "lower" and "upper" options are applied. Values of slice and map
+// fields are split into their elements and key-value pairs first. A value
+// rejected by a validator results in a [ParseError] wrapping a
+// [ValidationError]. Decoding a field that names a validator that isn't
+// registered results in an error that isn't a ParseError, since the struct tag
+// is at fault rather than the value.
+//
+// Registering validators on d doesn't affect decoders derived from d before,
+// nor calls in flight. RegisterValidation panics if name is empty or fn is
+// nil.
+func (d *Decoder) RegisterValidation(name string, fn ValidatorFunc) {
+	if name == "" || fn == nil {
+		panic("urlvalues: RegisterValidation with empty name or nil validator")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	validators := make(map[string]ValidatorFunc)
+	if old := d.validators.Load(); old != nil {
+		maps.Copy(validators, *old)
+	}
+	validators[name] = fn
+	d.validators.Store(&validators)
+}
+
+// Unmarshal unmarshals data into the value pointed to by v like [Unmarshal],
+// with the options of d followed by setParseOpts.
+func (d *Decoder) Unmarshal(data url.Values, v any, setParseOpts ...SetParseOptionFunc) error {
+	return Unmarshal(data, v, d.options(setParseOpts)...)
+}
+
+// UnmarshalContext unmarshals data into the value pointed to by v like
+// [UnmarshalContext], with the options of d followed by setParseOpts.
+func (d *Decoder) UnmarshalContext(ctx context.Context, data url.Values, v any, setParseOpts ...SetParseOptionFunc) error {
+	return UnmarshalContext(ctx, data, v, d.options(setParseOpts)...)
+}
+
+// UnmarshalFrom unmarshals the values of g into the value pointed to by v
+// like [UnmarshalFrom], with the options of d followed by setParseOpts.
+func (d *Decoder) UnmarshalFrom(g ValueGetter, v any, setParseOpts ...SetParseOptionFunc) error {
+	return UnmarshalFrom(g, v, d.options(setParseOpts)...)
+}
+
+// UnmarshalRequest unmarshals r into the value pointed to by v like
+// [UnmarshalRequest], with the options of d followed by setParseOpts.
+func (d *Decoder) UnmarshalRequest(r *http.Request, v any, setParseOpts ...SetParseOptionFunc) error {
+	return UnmarshalRequest(r, v, d.options(setParseOpts)...)
+}
+
+// Bind unmarshals r into the value pointed to by v like [Bind], with the
+// options of d followed by setParseOpts.
+func (d *Decoder) Bind(r *http.Request, v any, setParseOpts ...SetParseOptionFunc) error {
+	return Bind(r, v, d.options(setParseOpts)...)
+}
+
+// options returns the options of d, including its validators, followed by
+// setParseOpts, without modifying the options of d.
+func (d *Decoder) options(setParseOpts []SetParseOptionFunc) []SetParseOptionFunc {
+	opts := make([]SetParseOptionFunc, 0, 1+len(d.setParseOpts)+len(setParseOpts))
+	if validators := d.validators.Load(); validators != nil {
+		opts = append(opts, func(o *ParseOptions) {
+			o.validators = *validators
+		})
+	}
+	opts = append(opts, d.setParseOpts...)
+	return append(opts, setParseOpts...)
+}
@@ -0,0 +1,214 @@
+package urlvalues_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestDecoder(t *testing.T) {
+	type Target struct {
+		Page int `urlvalue:"page"`
+	}
+
+	base := urlvalues.NewDecoder(urlvalues.WithStrictNumbers())
+	search := base.With(urlvalues.WithPrefix("q."))
+	clone := search.Clone()
+
+	tests := []struct {
+		name    string
+		decoder *urlvalues.Decoder
+		in      url.Values
+		want    Target
+	}{
+		{"zero", &urlvalues.Decoder{}, url.Values{"page": {"0x10"}}, Target{Page: 16}},
+		{"base", base, url.Values{"page": {"10"}, "q.page": {"20"}}, Target{Page: 10}},
+		{"derived", search, url.Values{"page": {"10"}, "q.page": {"20"}}, Target{Page: 20}},
+		{"clone", clone, url.Values{"page": {"10"}, "q.page": {"20"}}, Target{Page: 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := tt.decoder.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("Decoder.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("Decoder.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("strictness inherited", func(t *testing.T) {
+		in := url.Values{"q.page": {"0x10"}}
+		var got Target
+		if err := search.Unmarshal(in, &got); err == nil {
+			t.Errorf("Decoder.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+		}
+	})
+
+	t.Run("call options", func(t *testing.T) {
+		in := url.Values{"page": {"10"}, "x.page": {"30"}}
+		var got Target
+		if err := base.Unmarshal(in, &got, urlvalues.WithPrefix("x.")); err != nil {
+			t.Fatalf("Decoder.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got, Target{Page: 30}); diff != "" {
+			t.Errorf("Decoder.Unmarshal(...) -got +want\n%s", diff)
+		}
+
+		// The call options must not leak into the decoder.
+		got = Target{}
+		if err := base.Unmarshal(in, &got); err != nil {
+			t.Fatalf("Decoder.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got, Target{Page: 10}); diff != "" {
+			t.Errorf("Decoder.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+}
+
+// TestDecoder_Concurrent is meant to be run with the race detector.
+func TestDecoder_Concurrent(t *testing.T) {
+	type Target struct {
+		Page   int            `urlvalue:"page,min:1"`
+		Colors []color        `urlvalue:"colors"`
+		Name   tenantName     `urlvalue:"name"`
+		Since  time.Time      `urlvalue:"since,layout:DateOnly"`
+		Tags   []string       `urlvalue:"tags"`
+		Sort   urlvalues.Sort `urlvalue:"sort"`
+	}
+
+	base := urlvalues.NewDecoder(urlvalues.WithStrictNumbers())
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	in := url.Values{
+		"page":   {"2"},
+		"colors": {"red;blue"},
+		"name":   {"alice"},
+		"since":  {"2024-03-01"},
+		"tags":   {"a,b"},
+		"sort":   {"-name"},
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := base.With(urlvalues.WithDelimiters(";", ","))
+			if i%2 == 0 {
+				d = d.Clone()
+			}
+			for range 50 {
+				var got Target
+				if err := d.UnmarshalContext(ctx, in, &got); err != nil {
+					t.Errorf("Decoder.UnmarshalContext(%v, %v, %v) = %q, want <nil>", ctx, in, &got, err)
+					return
+				}
+				if got.Page != 2 || len(got.Colors) != 2 || len(got.Tags) != 2 || got.Name != "acme/alice" {
+					t.Errorf("Decoder.UnmarshalContext(...) = %+v", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDecoder_RegisterValidation(t *testing.T) {
+	type Target struct {
+		Slug  string   `urlvalue:"slug,validate:slug,trim"`
+		Tags  []string `urlvalue:"tags,validate:slug|short"`
+		Other string   `urlvalue:"other"`
+	}
+
+	slug := regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	d := urlvalues.NewDecoder()
+	d.RegisterValidation("slug", func(value string) error {
+		if !slug.MatchString(value) {
+			return errors.New("must be a slug, such as my-post")
+		}
+		return nil
+	})
+	d.RegisterValidation("short", func(value string) error {
+		if len(value) > 5 {
+			return errors.New("must be at most 5 characters")
+		}
+		return nil
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		in := url.Values{"slug": {" my-post "}, "tags": {"go;web"}}
+		var got Target
+		if err := d.Unmarshal(in, &got); err != nil {
+			t.Fatalf("Decoder.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got, Target{Slug: "my-post", Tags: []string{"go", "web"}}); diff != "" {
+			t.Errorf("Decoder.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	tests := []struct {
+		name          string
+		in            url.Values
+		wantKey       string
+		wantValidator string
+		wantMsg       string
+	}{
+		{"rejected", url.Values{"slug": {"My Post"}}, "slug", "slug", "must be a slug, such as my-post"},
+		{"element", url.Values{"tags": {"go;Web"}}, "tags", "slug", "must be a slug, such as my-post"},
+		{"second validator", url.Values{"tags": {"go", "golang"}}, "tags", "short", "must be at most 5 characters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := d.Unmarshal(tt.in, &target)
+
+			var parseErr *urlvalues.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Decoder.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", tt.in, &target, err)
+			}
+			var validErr *urlvalues.ValidationError
+			if !errors.As(err, &validErr) {
+				t.Fatalf("Decoder.Unmarshal(%v, %v) = %v, want *urlvalues.ValidationError", tt.in, &target, err)
+			}
+			if parseErr.Key != tt.wantKey || validErr.Validator != tt.wantValidator || validErr.Error() != tt.wantMsg {
+				t.Errorf("Decoder.Unmarshal(...) = key %q, validator %q, message %q, want %q, %q, %q",
+					parseErr.Key, validErr.Validator, validErr.Error(), tt.wantKey, tt.wantValidator, tt.wantMsg)
+			}
+			if got := parseErr.Code(); got != urlvalues.CodeConstraint {
+				t.Errorf("ParseError.Code() = %q, want %q", got, urlvalues.CodeConstraint)
+			}
+		})
+	}
+
+	t.Run("not registered", func(t *testing.T) {
+		in := url.Values{"slug": {"my-post"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+		var parseErr *urlvalues.ParseError
+		if err == nil || errors.As(err, &parseErr) {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want error other than *urlvalues.ParseError", in, &target, err)
+		}
+	})
+
+	t.Run("copy-on-write", func(t *testing.T) {
+		derived := d.With()
+		derived.RegisterValidation("slug", func(string) error { return nil })
+
+		in := url.Values{"slug": {"My Post"}}
+		var target Target
+		if err := derived.Unmarshal(in, &target); err != nil {
+			t.Errorf("derived Decoder.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+		if err := d.Unmarshal(in, &target); err == nil {
+			t.Errorf("Decoder.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
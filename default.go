@@ -0,0 +1,198 @@
+package urlvalues
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultIf is a default value that applies if the field keyed key holds
+// value, as declared by the "default_if" tag option.
+type defaultIf struct {
+	key, value   string
+	defaultValue string
+}
+
+// parseDefaultIf parses the value of the "default_if" tag option, such as
+// "view=compact:name".
+func parseDefaultIf(s string) (defaultIf, error) {
+	key, rest, ok := strings.Cut(s, "=")
+	if !ok {
+		return defaultIf{}, errors.New("want key=value:default")
+	}
+	value, def, ok := strings.Cut(rest, ":")
+	if !ok {
+		return defaultIf{}, errors.New("want key=value:default")
+	}
+	d := defaultIf{key: strings.TrimSpace(key), value: strings.TrimSpace(value), defaultValue: strings.TrimSpace(def)}
+	if d.key == "" || d.defaultValue == "" {
+		return defaultIf{}, errors.New("want key=value:default")
+	}
+	return d, nil
+}
+
+// conditionalDefault returns the default value of the first "default_if"
+// option of field whose condition holds, or the empty string if none does.
+// Conditions refer to other fields among fields by their keys, and hold if
+// the value of the other field, or its default value if its key is absent,
+// equals the value of the condition.
+func conditionalDefault(in input, field field, fields []field, pOpts *ParseOptions) (string, error) {
+	for _, d := range field.options.defaultIfs {
+		i := -1
+		for j := range fields {
+			if fields[j].key() == d.key {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return "", fmt.Errorf("urlvalues: default_if option of field %s refers to unknown key %q", field.name, d.key)
+		}
+		if conditionValue(in, fields[i], pOpts) == d.value {
+			return d.defaultValue, nil
+		}
+	}
+	return "", nil
+}
+
+// conditionValue returns the first value of the first key of field present in
+// in, normalized like before being parsed, or the default value of field if
+// none is present.
+func conditionValue(in input, field field, pOpts *ParseOptions) string {
+	if lookup := in.lookup(field.options.source); lookup != nil {
+		for _, key := range field.keys() {
+			if values := lookup(field.fullKey(key, pOpts)); len(values) > 0 {
+				return field.options.normalize(values[0])
+			}
+		}
+	}
+	return field.options.defaultValue
+}
+
+// isReference reports whether the default value def refers to another field,
+// such as "@since+30d".
+func isReference(def string) bool {
+	return strings.HasPrefix(def, "@")
+}
+
+// orderReferences returns fields with the fields whose default value refers
+// to another field moved after the fields they refer to, so that referenced
+// fields are set by the time the references are resolved. fields is returned
+// as is if no default value refers to another field.
+func orderReferences(fields []field) ([]field, error) {
+	var pending []int
+	for i := range fields {
+		if isReference(fields[i].options.defaultValue) {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return fields, nil
+	}
+
+	ordered := make([]field, 0, len(fields))
+	for i := range fields {
+		if !isReference(fields[i].options.defaultValue) {
+			ordered = append(ordered, fields[i])
+		}
+	}
+	// Place the references whose referenced field is placed, until all are.
+	for len(pending) > 0 {
+		var rest []int
+		for _, i := range pending {
+			ref, _, err := reference(fields[i], fields)
+			if err != nil {
+				return nil, err
+			}
+			if isReference(fields[ref].options.defaultValue) && slices.Contains(pending, ref) {
+				rest = append(rest, i)
+				continue
+			}
+			ordered = append(ordered, fields[i])
+		}
+		if len(rest) == len(pending) {
+			return nil, fmt.Errorf("urlvalues: default values of field %s and the fields it refers to form a cycle", fields[rest[0]].name)
+		}
+		pending = rest
+	}
+	return ordered, nil
+}
+
+// reference returns the index of the field among fields that the default
+// value of f refers to, and the offsets following its key. Keys may contain
+// signs themselves, so the longest key followed by an offset, or nothing,
+// is the one referred to.
+func reference(f field, fields []field) (int, string, error) {
+	def := f.options.defaultValue[1:]
+	ref := -1
+	for i := range fields {
+		key := fields[i].key()
+		rest, ok := strings.CutPrefix(def, key)
+		if !ok || (rest != "" && rest[0] != '+' && rest[0] != '-') {
+			continue
+		}
+		if ref < 0 || len(key) > len(fields[ref].key()) {
+			ref = i
+		}
+	}
+	if ref < 0 {
+		return 0, "", fmt.Errorf("urlvalues: default value of field %s refers to unknown key %q", f.name, def)
+	}
+	if fields[ref].field == f.field {
+		return 0, "", fmt.Errorf("urlvalues: default value of field %s refers to itself", f.name)
+	}
+	return ref, def[len(fields[ref].key()):], nil
+}
+
+// setReference sets f to the value of the field among fields that its
+// default value refers to, with any offsets added to it, and reports whether
+// it did. f is left untouched if it already holds a non-zero value or if the
+// referenced field holds its zero value. Offsets of years, months and days
+// (y/m/d) are only supported by time.Time fields referring to time.Time
+// fields.
+func setReference(f field, fields []field) (bool, error) {
+	ref, offsets, err := reference(f, fields)
+	if err != nil {
+		return false, err
+	}
+
+	if preset := reflect.Indirect(f.field); preset.IsValid() && !preset.IsZero() {
+		return false, nil
+	}
+	src := reflect.Indirect(fields[ref].field)
+	if !src.IsValid() || src.IsZero() {
+		return false, nil
+	}
+	dst := f.field
+	if dst.Kind() == reflect.Pointer {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	if offsets != "" {
+		if src.Type() != timeType || dst.Type() != timeType {
+			return false, errors.New("offsets are only supported by time.Time fields referring to time.Time fields")
+		}
+		pos := len(f.options.defaultValue) - len(offsets)
+		t, err := addDateOffsets(src.Interface().(time.Time), offsets, pos)
+		if err != nil {
+			return false, err
+		}
+		src = reflect.ValueOf(t)
+	}
+
+	switch {
+	case src.Type().AssignableTo(dst.Type()):
+		dst.Set(src)
+	case src.Type().ConvertibleTo(dst.Type()) && src.Kind() == dst.Kind():
+		dst.Set(src.Convert(dst.Type()))
+	default:
+		return false, fmt.Errorf("cannot assign %s to %s", src.Type(), dst.Type())
+	}
+	return true, nil
+}
@@ -0,0 +1,134 @@
+package urlvalues
+
+import (
+	"net/url"
+	"reflect"
+)
+
+// ParamInfo describes how a struct field is decoded. See [Describe].
+type ParamInfo struct {
+	// Name of the struct field.
+	FieldName string
+	// Key the field is decoded from, composed with the keys of any structs
+	// it is nested in.
+	Key string
+	// Alternative keys the field is decoded from, in order of precedence.
+	Aliases []string
+	// Deprecated keys the field is decoded from, in order of precedence.
+	Deprecated []string
+	// Type of the field.
+	Type reflect.Type
+	// Default value of the field, or empty if it has none.
+	Default string
+	// Sample value of the field, or empty if it has none.
+	Example string
+	// Layout of a time.Time field as given in its tag, or empty if it has
+	// none.
+	Layout string
+	// Source of the field as given in its tag, or empty if it is decoded from
+	// URL values.
+	Source string
+	// Required reports whether decoding fails when the key of the field is
+	// absent, which is the case for fields with a minitems constraint of at
+	// least 1 and no default value.
+	Required bool
+	// Constraints declared in the tag of the field, nil if not declared.
+	MinItems *int
+	MaxItems *int
+	Min      *float64
+	Max      *float64
+	OneOf    []string
+	// Pattern is the regular expression declared in the tag of the field, or
+	// empty if not declared.
+	Pattern string
+}
+
+// Describe returns information about how each field of v is decoded by
+// [Unmarshal], in the order the fields are decoded. v must be a struct or a
+// struct pointer. Nested structs are described by their fields, with keys
+// composed according to the options passed, such as [WithKeyStyle] and
+// [WithPrefix]. Fields with the "encodeonly" option are omitted, since they
+// aren't decoded.
+//
+// Describe is meant for generating documentation of the parameters of an API
+// from the structs they are decoded into.
+func Describe(v any, setParseOpts ...SetParseOptionFunc) ([]ParamInfo, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Struct {
+		v = reflect.New(rv.Type()).Interface()
+	}
+	pOpts := newParseOptionsFor(v, setParseOpts)
+
+	fields, err := extractFields(v, nil, pOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]ParamInfo, 0, len(fields))
+	for _, field := range fields {
+		fOpts := field.options
+		if fOpts.encodeOnly {
+			continue
+		}
+		param := ParamInfo{
+			FieldName:  field.name,
+			Key:        field.fullKey(field.key(), pOpts),
+			Aliases:    fullKeys(field, fOpts.aliases, pOpts),
+			Deprecated: fullKeys(field, fOpts.deprecated, pOpts),
+			Type:       field.field.Type(),
+			Default:    fOpts.defaultValue,
+			Example:    fOpts.example,
+			Layout:     fOpts.layout,
+			Source:     fOpts.source,
+			Required:   fOpts.minItems != nil && *fOpts.minItems > 0 && fOpts.defaultValue == "",
+			MinItems:   fOpts.minItems,
+			MaxItems:   fOpts.maxItems,
+			Min:        fOpts.min,
+			Max:        fOpts.max,
+			OneOf:      fOpts.oneOf,
+		}
+		if fOpts.pattern != nil {
+			param.Pattern = fOpts.pattern.String()
+		}
+		params = append(params, param)
+	}
+
+	return params, nil
+}
+
+// fullKeys returns keys of field composed by field.fullKey.
+func fullKeys(field field, keys []string, pOpts *ParseOptions) []string {
+	if keys == nil {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = field.fullKey(key, pOpts)
+	}
+	return full
+}
+
+// ExampleValues returns sample URL values of v, a struct or struct pointer,
+// for documenting how to build a query string. The value of each field decoded
+// from URL values is its example value, or its default value if it has no
+// example. Fields with neither, and fields with a source, are omitted. Keys
+// are composed like in [Describe].
+func ExampleValues(v any, setParseOpts ...SetParseOptionFunc) (url.Values, error) {
+	params, err := Describe(v, setParseOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+	for _, param := range params {
+		if param.Source != "" {
+			continue
+		}
+		switch {
+		case param.Example != "":
+			values.Set(param.Key, param.Example)
+		case param.Default != "":
+			values.Set(param.Key, param.Default)
+		}
+	}
+	return values, nil
+}
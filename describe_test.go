@@ -0,0 +1,102 @@
+package urlvalues_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestDescribe(t *testing.T) {
+	type Page struct {
+		Size int `urlvalue:"size,default:20,min:1,max:100"`
+	}
+	type Target struct {
+		IDs     []int     `urlvalue:"ids,minitems:1,maxitems:10"`
+		Status  string    `urlvalue:"status,example:open,alias:state,deprecated:st,oneof:open|closed,pattern:^[a-z]+$"`
+		Since   time.Time `urlvalue:"since,layout:2006-01-02"`
+		Token   string    `urlvalue:"X-Token,source:header"`
+		Page    *Page
+		Ignored string `urlvalue:"-"`
+	}
+
+	got, err := urlvalues.Describe(Target{})
+	if err != nil {
+		t.Fatalf("urlvalues.Describe(...) = %q, want <nil>", err)
+	}
+
+	one, ten := 1, 10
+	lo, hi := 1.0, 100.0
+	want := []urlvalues.ParamInfo{
+		{FieldName: "IDs", Key: "ids", Type: reflect.TypeFor[[]int](), Required: true, MinItems: &one, MaxItems: &ten},
+		{FieldName: "Status", Key: "status", Example: "open", Aliases: []string{"state"}, Deprecated: []string{"st"}, Type: reflect.TypeFor[string](), OneOf: []string{"open", "closed"}, Pattern: "^[a-z]+$"},
+		{FieldName: "Since", Key: "since", Type: reflect.TypeFor[time.Time](), Layout: "2006-01-02"},
+		{FieldName: "Token", Key: "X-Token", Type: reflect.TypeFor[string](), Source: "header"},
+		{FieldName: "Size", Key: "size", Type: reflect.TypeFor[int](), Default: "20", Min: &lo, Max: &hi},
+	}
+	typeComparer := cmp.Comparer(func(x, y reflect.Type) bool { return x == y })
+	if diff := cmp.Diff(got, want, typeComparer); diff != "" {
+		t.Errorf("urlvalues.Describe(...) -got +want\n%s", diff)
+	}
+
+	t.Run("pointer", func(t *testing.T) {
+		gotPtr, err := urlvalues.Describe(&Target{})
+		if err != nil {
+			t.Fatalf("urlvalues.Describe(...) = %q, want <nil>", err)
+		}
+		if diff := cmp.Diff(gotPtr, want, typeComparer); diff != "" {
+			t.Errorf("urlvalues.Describe(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		if _, err := urlvalues.Describe(42); err == nil {
+			t.Errorf("urlvalues.Describe(42) = <nil>, want error")
+		}
+	})
+}
+
+func TestExampleValues(t *testing.T) {
+	type Target struct {
+		Query  string   `urlvalue:"q,example:shoes"`
+		Tags   []string `urlvalue:"tags,example:red;blue"`
+		Size   int      `urlvalue:"size,default:20,example:50"`
+		Page   int      `urlvalue:"page,default:1"`
+		Token  string   `urlvalue:"X-Token,source:header,example:secret"`
+		Cursor string   `urlvalue:"cursor"`
+	}
+
+	got, err := urlvalues.ExampleValues(&Target{})
+	if err != nil {
+		t.Fatalf("urlvalues.ExampleValues(...) = %q, want <nil>", err)
+	}
+
+	want := "page=1&q=shoes&size=50&tags=red%3Bblue"
+	if got.Encode() != want {
+		t.Errorf("urlvalues.ExampleValues(...).Encode() = %q, want %q", got.Encode(), want)
+	}
+
+	var target Target
+	if err := urlvalues.Unmarshal(got, &target); err != nil {
+		t.Errorf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", got, &target, err)
+	}
+}
+
+func TestDescribe_NestedKeys(t *testing.T) {
+	type Page struct {
+		Size int `urlvalue:"size,alias:limit"`
+	}
+	type Target struct {
+		Page Page `urlvalue:"page"`
+	}
+
+	got, err := urlvalues.Describe(Target{}, urlvalues.WithKeyStyle(urlvalues.BracketKeys), urlvalues.WithPrefix("q."))
+	if err != nil {
+		t.Fatalf("urlvalues.Describe(...) = %q, want <nil>", err)
+	}
+	if len(got) != 1 || got[0].Key != "q.page[size]" || !cmp.Equal(got[0].Aliases, []string{"q.page[limit]"}) {
+		t.Errorf("urlvalues.Describe(...) = %+v, want key q.page[size] and alias q.page[limit]", got)
+	}
+}
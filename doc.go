@@ -1,2 +1,6 @@
-// Package urlvalues unmarshals [url.Values] into struct values.
+// Package urlvalues unmarshals [url.Values] into struct values, and marshals
+// struct values back into [url.Values].
+//
+// All functions of the package, and [Decoder] values, are safe for concurrent
+// use by multiple goroutines.
 package urlvalues
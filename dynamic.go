@@ -0,0 +1,122 @@
+package urlvalues
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var anyType = reflect.TypeFor[any]()
+
+// unmarshalDynamic decodes data into m, inferring the type of each value. See
+// Unmarshal for details. Values are subject to the same limits as values of
+// struct fields.
+func unmarshalDynamic(in input, keys []string, m *map[string]any, pOpts *ParseOptions) error {
+	if *m == nil {
+		*m = make(map[string]any)
+	}
+
+	var errs Errors
+	for _, key := range keys {
+		if !strings.HasPrefix(key, pOpts.prefix) {
+			continue
+		}
+
+		values := in.values(key)
+		if len(values) == 0 {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, pOpts.prefix)
+		value, err := decodeDynamic(name, key, values, pOpts)
+		if err != nil {
+			if errs, err = pOpts.collect(errs, err); err != nil {
+				return err
+			}
+			continue
+		}
+		(*m)[name] = value
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// decodeDynamic decodes the entry name of a dynamic map from the values of
+// key, inferring the type of each value. Several values, including values
+// split by the delimiter, are decoded into a []any.
+func decodeDynamic(name, key string, values []string, pOpts *ParseOptions) (any, error) {
+	if err := checkSize(values, pOpts); err != nil {
+		return nil, newDynamicParseError(name, key, strings.Join(values, pOpts.Delim()), err, pOpts)
+	}
+	if err := checkChars([]string{key}, pOpts); err != nil {
+		return nil, newDynamicParseError(name, key, key, err, pOpts)
+	}
+	if err := checkChars(values, pOpts); err != nil {
+		return nil, newDynamicParseError(name, key, strings.Join(values, pOpts.Delim()), err, pOpts)
+	}
+
+	if len(values) == 1 {
+		if err := checkSliceLen(pOpts.countItems(values[0]), fieldOptions{}, *pOpts); err != nil {
+			return nil, newDynamicParseError(name, key, values[0], err, pOpts)
+		}
+		values = pOpts.split(values[0])
+	} else if err := checkSliceLen(len(values), fieldOptions{}, *pOpts); err != nil {
+		return nil, newDynamicParseError(name, key, strings.Join(values, pOpts.Delim()), err, pOpts)
+	}
+
+	if len(values) == 1 {
+		return inferValue(values[0]), nil
+	}
+
+	items := make([]any, len(values))
+	for i, value := range values {
+		items[i] = inferValue(value)
+	}
+	return items, nil
+}
+
+// newDynamicParseError returns a ParseError of the entry name of a dynamic
+// map, decoded from key, failing to parse value due to err.
+func newDynamicParseError(name, key, value string, err error, pOpts *ParseOptions) *ParseError {
+	return renderParseError(&ParseError{
+		FieldName: name,
+		Key:       key,
+		fe: &FieldError{
+			fieldName: name,
+			typeName:  anyType.String(),
+			value:     value,
+			err:       err,
+		},
+	}, pOpts)
+}
+
+// inferValue returns value converted to the first of the following types that
+// it can be parsed as: bool, int64, float64 or time.Time. Values that can't be
+// parsed as any of those types are returned as is.
+func inferValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t
+	}
+
+	return value
+}
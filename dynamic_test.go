@@ -0,0 +1,61 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshal_Dynamic(t *testing.T) {
+	in := url.Values{
+		"active": {"true"},
+		"count":  {"42"},
+		"ratio":  {"0.5"},
+		"since":  {"2023-01-02T15:04:05Z"},
+		"name":   {"gopher"},
+		"huge":   {"1e400"},
+		"ids":    {"1", "2"},
+		"tags":   {"a;b"},
+		"empty":  {""},
+	}
+	want := map[string]any{
+		"active": true,
+		"count":  int64(42),
+		"ratio":  0.5,
+		"since":  time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+		"name":   "gopher",
+		"huge":   "1e400",
+		"ids":    []any{int64(1), int64(2)},
+		"tags":   []any{"a", "b"},
+		"empty":  "",
+	}
+
+	var got map[string]any
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshal_DynamicWithPrefix(t *testing.T) {
+	in := url.Values{
+		"filter.status": {"open"},
+		"page":          {"2"},
+	}
+	want := map[string]any{"status": "open"}
+
+	got := map[string]any{}
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithPrefix("filter.")); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+}
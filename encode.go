@@ -0,0 +1,365 @@
+package urlvalues
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal returns the URL values encoding v, a struct or struct pointer, such
+// that [Unmarshal] decodes them into an equivalent struct. Marshal is the
+// inverse of Unmarshal and follows the same struct tags.
+//
+// Slice and map fields encode to a value per element, and the elements of
+// maps are sorted. [time.Time] fields are formatted using the layouts given by
+// their "layout" options, or as seconds since the Unix epoch, with any
+// fraction of a second, if the [WithUnixTime] [SetParseOptionFunc] is passed.
+//
+// Elements containing the delimiter are kept intact by encoding them as
+// repeated keys. Marshal returns an error if such an element would still be
+// split by Unmarshal, which is the case for the only element of a field, or
+// for any element if the [WithSplitRepeated] [SetParseOptionFunc] is passed.
+// Likewise, Marshal returns an error if a key or value of a map contains a
+// colon, which separates them.
+//
+// Fields with zero values are omitted, unless they have a default value.
+// Fields with values equal to their default values are omitted as well if the
+// [WithOmitDefaults] [SetParseOptionFunc] is passed. Fields decoded from a
+// path, header or cookie source, fields with the "decodeonly" option and file
+// uploads are omitted. A prefix set by passing the [WithPrefix]
+// [SetParseOptionFunc] is prepended to all keys.
+func Marshal(v any, setParseOpts ...SetParseOptionFunc) (url.Values, error) {
+	values := make(url.Values)
+	if err := MarshalInto(values, v, setParseOpts...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// MarshalInto encodes v into dst like [Marshal], overwriting the keys of the
+// fields of v while preserving all other keys. The keys of omitted fields,
+// as well as their aliases and deprecated keys, are removed from dst, so that
+// dst decodes into a struct equivalent to v. This is useful for building links
+// to the same query with some parameters changed, such as the next page.
+func MarshalInto(dst url.Values, v any, setParseOpts ...SetParseOptionFunc) error {
+	return encode(dst, v, newParseOptionsFor(v, setParseOpts))
+}
+
+// Canonical returns a deterministic encoding of v, a struct or struct pointer,
+// suitable as a cache key or idempotency key. Structs decoding to the same
+// value have the same canonical encoding.
+//
+// Keys are sorted, and fields with zero values or values equal to their
+// defaults are omitted. The values of slice fields keep their order, unless
+// the fields have the "sort" option, in which case they are sorted. Likewise,
+// the "trim", "lower", "upper" and "dedupe" options are applied to the values
+// of slice fields. Fields decoded from a path, header or cookie source, fields
+// with the "decodeonly" option and file uploads are omitted.
+func Canonical(v any) (string, error) {
+	values := make(url.Values)
+	pOpts := newParseOptionsFor(v, nil)
+	pOpts.omitDefaults = true
+	if err := encode(values, v, pOpts); err != nil {
+		return "", err
+	}
+	return values.Encode(), nil
+}
+
+// encode encodes the fields of v, a struct or struct pointer, into dst,
+// replacing the values of all keys of each field.
+func encode(dst url.Values, v any, pOpts *ParseOptions) error {
+	// Fields must be addressable for pointer receivers of marshalers.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Struct {
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		v = ptr.Interface()
+	}
+
+	buf, fields := getFields()
+	fields, err := extractFields(v, fields, pOpts)
+	defer func() { putFields(buf, fields) }()
+	if err != nil {
+		return err
+	}
+
+	return encodeFields(dst, fields, pOpts)
+}
+
+// encodeFields encodes fields into dst, replacing the values of all keys of
+// each field.
+func encodeFields(dst url.Values, fields []field, pOpts *ParseOptions) error {
+	for _, field := range fields {
+		if !field.reachable() || isFileField(field.field) || field.options.decodeOnly {
+			continue
+		}
+		switch field.options.source {
+		case sourcePath, sourceHeader, sourceCookie:
+			continue
+		}
+
+		if isPolymorphic(field.field) {
+			if err := encodeImplementation(dst, field, pOpts); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.field.Type() == fieldsetsType {
+			encodeFieldsets(dst, field, pOpts)
+			continue
+		}
+		if field.options.passthrough {
+			encodePassthrough(dst, field, pOpts)
+			continue
+		}
+
+		values, err := encodeField(field, pOpts)
+		if err != nil {
+			return fmt.Errorf("urlvalues: encoding field %s: %w", field.name, err)
+		}
+		for _, key := range field.keys() {
+			delete(dst, field.fullKey(key, pOpts))
+		}
+		if len(values) > 0 {
+			dst[field.fullKey(field.key(), pOpts)] = values
+		}
+	}
+
+	return nil
+}
+
+// reachable reports whether f is reachable from the struct it was extracted
+// from, that is, whether all structs that f is nested in are allocated.
+func (f field) reachable() bool {
+	for _, alloc := range f.allocs {
+		if alloc.ptr.IsNil() {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeField returns the values that field encodes to, or nil if it should be
+// omitted. Fields with zero values are omitted, unless they have a default
+// value, since decoding zero values sets the default value. Values equal to
+// the default value are omitted if set in pOpts.
+func encodeField(field field, pOpts *ParseOptions) ([]string, error) {
+	fOpts := field.options
+	if fOpts.defaultValue == "" && field.field.IsZero() {
+		return nil, nil
+	}
+
+	values, err := encodeValue(field.field, fOpts, *pOpts)
+	if err != nil {
+		return nil, err
+	}
+	if holdsMultiple(field.field) {
+		if err := checkDelimited(values, pOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	// Default values referring to other fields are never omitted, since they
+	// depend on the values of the fields they refer to.
+	if pOpts.omitDefaults && fOpts.defaultValue != "" && !isReference(fOpts.defaultValue) {
+		def := reflect.New(field.field.Type()).Elem()
+		if err := processField(true, fOpts.defaultValue, def, fOpts, *pOpts); err != nil {
+			return nil, err
+		}
+		defValues, err := encodeValue(def, fOpts, *pOpts)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Equal(values, defValues) {
+			return nil, nil
+		}
+	}
+
+	return values, nil
+}
+
+// checkDelimited returns an error if any of values, encoding the elements of a
+// slice or map, would be split into several elements when decoded.
+func checkDelimited(values []string, pOpts *ParseOptions) error {
+	if len(values) > 1 && !pOpts.splitRepeated {
+		return nil
+	}
+	for _, value := range values {
+		if pOpts.countItems(value) > 1 {
+			return fmt.Errorf("element %q contains the delimiter %q", value, pOpts.Delim())
+		}
+	}
+	return nil
+}
+
+// encodeValue returns the values that v encodes to. Slices and maps encode to
+// a value per element, while other types encode to a single value.
+func encodeValue(v reflect.Value, fOpts fieldOptions, pOpts ParseOptions) ([]string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+
+	if !holdsMultiple(v) {
+		value, err := formatValue(v, fOpts, pOpts)
+		if err != nil {
+			return nil, err
+		}
+		return []string{value}, nil
+	}
+
+	if v.Kind() == reflect.Slice {
+		// Decode the values of slices with options normalizing them, so that
+		// they are encoded like they would be decoded.
+		if fOpts.normalizes() || fOpts.dedupe || fOpts.sort {
+			formatted, err := formatSlice(v, fOpts, pOpts)
+			if err != nil {
+				return nil, err
+			}
+			normalized := reflect.New(v.Type()).Elem()
+			if err := setSlice(normalized, formatted, fOpts, pOpts); err != nil {
+				return nil, err
+			}
+			v = normalized
+		}
+		return formatSlice(v, fOpts, pOpts)
+	}
+
+	values := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := formatValue(iter.Key(), fOpts, pOpts)
+		if err != nil {
+			return nil, err
+		}
+		val, err := formatValue(iter.Value(), fOpts, pOpts)
+		if err != nil {
+			return nil, err
+		}
+		// Entries are split by colons when decoded.
+		for _, s := range []string{k, val} {
+			if strings.Contains(s, ":") {
+				return nil, fmt.Errorf("map entry %q contains the separator %q", s, ":")
+			}
+		}
+		values = append(values, k+":"+val)
+	}
+	// Map iteration order is random.
+	slices.Sort(values)
+	return values, nil
+}
+
+// formatSlice returns the elements of the slice v formatted as values.
+func formatSlice(v reflect.Value, fOpts fieldOptions, pOpts ParseOptions) ([]string, error) {
+	values := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		value, err := formatValue(v.Index(i), fOpts, pOpts)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// formatOperands returns the operands of f formatted like values of the types
+// of the operands. It is kept out of formatValue, since the closure passed to f
+// moves the options to the heap.
+func formatOperands(f operandEncoder, fOpts fieldOptions, pOpts ParseOptions) (string, error) {
+	return f.encodeOperands(func(v reflect.Value) (string, error) {
+		return formatValue(v, fOpts, pOpts)
+	})
+}
+
+// formatValue returns v formatted as a single value, the inverse of
+// processField.
+func formatValue(v reflect.Value, fOpts fieldOptions, pOpts ParseOptions) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	typ := v.Type()
+
+	if typ == timeType {
+		tim := v.Interface().(time.Time)
+		if pOpts.unixTime {
+			return formatUnixTime(tim), nil
+		}
+		return formatTime(fieldLayout(fOpts, pOpts), tim), nil
+	}
+
+	// Types composed of operands, such as filters.
+	if f := marshalerFrom[operandEncoder](v); f != nil {
+		return formatOperands(f, fOpts, pOpts)
+	}
+
+	// Enum types registered by RegisterEnum.
+	if enumOf(typ) != nil {
+		return v.Interface().(fmt.Stringer).String(), nil
+	}
+
+	// Types implementing encoding.TextMarshaler.
+	if m := marshalerFrom[encoding.TextMarshaler](v); m != nil {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	// Types implementing encoding.BinaryMarshaler.
+	if m := marshalerFrom[encoding.BinaryMarshaler](v); m != nil {
+		b, err := m.MarshalBinary()
+		return string(b), err
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if fOpts.geopoint {
+			return formatGeoPoint(v), nil
+		}
+		return "", fmt.Errorf("unsupported type %s", typ)
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if typ == durationType {
+			return time.Duration(v.Int()).String(), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Bool:
+		if v.Bool() && len(fOpts.trueValues) > 0 {
+			return fOpts.trueValues[0], nil
+		}
+		if !v.Bool() && len(fOpts.falseValues) > 0 {
+			return fOpts.falseValues[0], nil
+		}
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, typ.Bits()), nil
+	default:
+		return "", fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+// marshalerFrom returns v, or a pointer to v, as the marshaler interface T if
+// either implements it.
+func marshalerFrom[T any](v reflect.Value) T {
+	if m, ok := v.Interface().(T); ok {
+		return m
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(T); ok {
+			return m
+		}
+	}
+	var zero T
+	return zero
+}
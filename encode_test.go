@@ -0,0 +1,369 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestCanonical(t *testing.T) {
+	type Filter struct {
+		Status string `urlvalue:"status"`
+	}
+	type Target struct {
+		Query  string            `urlvalue:"q"`
+		Tags   []string          `urlvalue:"tags,sort,lower,dedupe"`
+		IDs    []int             `urlvalue:"ids"`
+		Meta   map[string]string `urlvalue:"meta"`
+		Limit  int               `urlvalue:"limit,default:20"`
+		Exact  bool              `urlvalue:"exact,default:true"`
+		Since  time.Time         `urlvalue:"since,layout:2006-01-02"`
+		Wait   time.Duration     `urlvalue:"wait"`
+		Filter *Filter
+		Token  string `urlvalue:"X-Token,source:header"`
+	}
+
+	tests := []struct {
+		name string
+		in   Target
+		want string
+	}{
+		{"zero", Target{}, "exact=false&limit=0"},
+		{"defaults", Target{Limit: 20, Exact: true}, ""},
+		{
+			"all",
+			Target{
+				Query:  "a b",
+				Tags:   []string{"b", "A", "a"},
+				IDs:    []int{3, 1, 2},
+				Meta:   map[string]string{"y": "2", "x": "1"},
+				Limit:  50,
+				Exact:  true,
+				Since:  time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				Wait:   90 * time.Second,
+				Filter: &Filter{Status: "open"},
+				Token:  "secret",
+			},
+			"ids=3&ids=1&ids=2&limit=50&meta=x%3A1&meta=y%3A2&q=a+b&since=2023-02-01&status=open&tags=a&tags=b&wait=1m30s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.Canonical(tt.in)
+			if err != nil {
+				t.Fatalf("urlvalues.Canonical(%v) = %q, want <nil>", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("urlvalues.Canonical(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+
+			// The canonical encoding decodes to an equivalent struct.
+			values, err := url.ParseQuery(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded Target
+			if err := urlvalues.Unmarshal(values, &decoded); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", values, &decoded, err)
+			}
+			again, err := urlvalues.Canonical(&decoded)
+			if err != nil {
+				t.Fatalf("urlvalues.Canonical(%v) = %q, want <nil>", &decoded, err)
+			}
+			if again != got {
+				t.Errorf("urlvalues.Canonical(%v) = %q, want %q", &decoded, again, got)
+			}
+		})
+	}
+
+	t.Run("equivalent structs", func(t *testing.T) {
+		a, err := urlvalues.Canonical(Target{Tags: []string{"x", "Y"}, Limit: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := urlvalues.Canonical(&Target{Tags: []string{"y", "x", "X"}, Limit: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Errorf("urlvalues.Canonical(...) = %q and %q, want equal", a, b)
+		}
+	})
+}
+
+func TestMarshal(t *testing.T) {
+	type Page struct {
+		Number int `urlvalue:"page,default:1"`
+		Size   int `urlvalue:"size,default:20"`
+	}
+	type Target struct {
+		Query string   `urlvalue:"q"`
+		Tags  []string `urlvalue:"tags"`
+		Page  Page
+	}
+
+	in := Target{Query: "shoes", Tags: []string{"a;b", "c"}, Page: Page{Number: 2, Size: 20}}
+	got, err := urlvalues.Marshal(in, urlvalues.WithPrefix("search."))
+	if err != nil {
+		t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+	}
+
+	want := url.Values{
+		"search.q":    {"shoes"},
+		"search.tags": {"a;b", "c"},
+		"search.page": {"2"},
+		"search.size": {"20"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+	}
+
+	var decoded Target
+	if err := urlvalues.Unmarshal(got, &decoded, urlvalues.WithPrefix("search.")); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", got, &decoded, err)
+	}
+	if diff := cmp.Diff(decoded, in); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("delimiter", func(t *testing.T) {
+		type Target struct {
+			Tags []string          `urlvalue:"tags"`
+			Meta map[string]string `urlvalue:"meta"`
+		}
+		tests := []struct {
+			name    string
+			in      Target
+			opts    []urlvalues.SetParseOptionFunc
+			wantErr bool
+		}{
+			{"repeated keys", Target{Tags: []string{"a;b", "c"}}, nil, false},
+			{"only element", Target{Tags: []string{"a;b"}}, nil, true},
+			{"only map element", Target{Meta: map[string]string{"k": "a;b"}}, nil, true},
+			{"map key separator", Target{Meta: map[string]string{"a:b": "c", "d": "e"}}, nil, true},
+			{"map value separator", Target{Meta: map[string]string{"a": "b:c", "d": "e"}}, nil, true},
+			{"map entries", Target{Meta: map[string]string{"a": "b", "c": "d"}}, nil, false},
+			{"split repeated", Target{Tags: []string{"a;b", "c"}}, []urlvalues.SetParseOptionFunc{urlvalues.WithSplitRepeated()}, true},
+			{"custom delimiter", Target{Tags: []string{"a;b"}}, []urlvalues.SetParseOptionFunc{urlvalues.WithDelimiter(",")}, false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := urlvalues.Marshal(tt.in, tt.opts...)
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("urlvalues.Marshal(%v) = %v, want error", tt.in, got)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", tt.in, err)
+				}
+
+				var decoded Target
+				if err := urlvalues.Unmarshal(got, &decoded, tt.opts...); err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", got, &decoded, err)
+				}
+				if diff := cmp.Diff(decoded, tt.in); diff != "" {
+					t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+				}
+			})
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := struct {
+			Ch chan int
+		}{Ch: make(chan int)}
+		if _, err := urlvalues.Marshal(in); err == nil {
+			t.Errorf("urlvalues.Marshal(%v) = <nil>, want error", in)
+		}
+	})
+}
+
+func TestMarshalInto(t *testing.T) {
+	type Target struct {
+		Query  string `urlvalue:"q"`
+		Page   int    `urlvalue:"page,alias:p"`
+		Cursor string `urlvalue:"cursor"`
+	}
+
+	dst := url.Values{
+		"q":      {"shoes"},
+		"p":      {"1"},
+		"cursor": {"abc"},
+		"utm":    {"mail"},
+	}
+	var params Target
+	if err := urlvalues.Unmarshal(dst, &params); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", dst, &params, err)
+	}
+
+	params.Page++
+	params.Cursor = ""
+	if err := urlvalues.MarshalInto(dst, params); err != nil {
+		t.Fatalf("urlvalues.MarshalInto(%v, %v) = %q, want <nil>", dst, params, err)
+	}
+
+	want := url.Values{
+		"q":    {"shoes"},
+		"page": {"2"},
+		"utm":  {"mail"},
+	}
+	if diff := cmp.Diff(dst, want); diff != "" {
+		t.Errorf("urlvalues.MarshalInto(...) -got +want\n%s", diff)
+	}
+}
+
+func TestMarshal_Time(t *testing.T) {
+	type Target struct {
+		Default time.Time `urlvalue:"default"`
+		Named   time.Time `urlvalue:"named,layout:RFC850"`
+		Custom  time.Time `urlvalue:"custom,layout:2006-01-02"`
+		Precise time.Time `urlvalue:"precise"`
+	}
+
+	tim := time.Date(2023, 2, 1, 15, 4, 5, 0, time.UTC)
+	precise := time.Date(2023, 2, 1, 15, 4, 5, 250e6, time.UTC)
+	in := Target{Default: tim, Named: tim, Custom: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), Precise: precise}
+
+	tests := []struct {
+		name string
+		opts []urlvalues.SetParseOptionFunc
+		want url.Values
+	}{
+		{
+			"layouts",
+			nil,
+			url.Values{
+				"default": {tim.Format(time.Layout)},
+				"named":   {tim.Format(time.RFC850)},
+				"custom":  {"2023-02-01"},
+				"precise": {precise.Format(time.Layout)},
+			},
+		},
+		{
+			"unix",
+			[]urlvalues.SetParseOptionFunc{urlvalues.WithUnixTime()},
+			url.Values{
+				"default": {"1675263845"},
+				"named":   {"1675263845"},
+				"custom":  {"1675209600"},
+				"precise": {"1675263845.25"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.Marshal(in, tt.opts...)
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+			}
+
+			var decoded Target
+			if err := urlvalues.Unmarshal(got, &decoded, tt.opts...); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", got, &decoded, err)
+			}
+			if !decoded.Default.Equal(in.Default) || !decoded.Named.Equal(in.Named) || !decoded.Custom.Equal(in.Custom) {
+				t.Errorf("urlvalues.Unmarshal(%v, ...) = %v, want %v", got, decoded, in)
+			}
+		})
+	}
+}
+
+func TestMarshal_OmitDefaults(t *testing.T) {
+	type Target struct {
+		Page  int      `urlvalue:"page,default:1"`
+		Limit int      `urlvalue:"limit,default:20"`
+		Sort  string   `urlvalue:"sort,default:created_at"`
+		Tags  []string `urlvalue:"tags,default:a;b"`
+	}
+
+	in := Target{Page: 3, Limit: 20, Sort: "created_at", Tags: []string{"a", "b"}}
+
+	tests := []struct {
+		name string
+		opts []urlvalues.SetParseOptionFunc
+		want string
+	}{
+		{"all", nil, "limit=20&page=3&sort=created_at&tags=a&tags=b"},
+		{"omit defaults", []urlvalues.SetParseOptionFunc{urlvalues.WithOmitDefaults()}, "page=3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.Marshal(in, tt.opts...)
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+			}
+			if got.Encode() != tt.want {
+				t.Errorf("urlvalues.Marshal(%v).Encode() = %q, want %q", in, got.Encode(), tt.want)
+			}
+
+			var decoded Target
+			if err := urlvalues.Unmarshal(got, &decoded); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", got, &decoded, err)
+			}
+			if diff := cmp.Diff(decoded, in); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMarshal_Direction(t *testing.T) {
+	type Target struct {
+		Query string `urlvalue:"q"`
+		Token string `urlvalue:"token,decodeonly"`
+		Total int    `urlvalue:"total,encodeonly,default:10"`
+	}
+
+	t.Run("encode", func(t *testing.T) {
+		in := Target{Query: "go", Token: "secret", Total: 42}
+		got, err := urlvalues.Marshal(in)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+		}
+		if want := "q=go&total=42"; got.Encode() != want {
+			t.Errorf("urlvalues.Marshal(%v).Encode() = %q, want %q", in, got.Encode(), want)
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		in := url.Values{"q": {"go"}, "token": {"secret"}, "total": {"42"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got, Target{Query: "go", Token: "secret"}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("describe", func(t *testing.T) {
+		params, err := urlvalues.Describe(Target{})
+		if err != nil {
+			t.Fatalf("urlvalues.Describe(...) = %q, want <nil>", err)
+		}
+		var keys []string
+		for _, p := range params {
+			keys = append(keys, p.Key)
+		}
+		if diff := cmp.Diff(keys, []string{"q", "token"}); diff != "" {
+			t.Errorf("urlvalues.Describe(...) keys -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("both", func(t *testing.T) {
+		var target struct {
+			Token string `urlvalue:"token,decodeonly,encodeonly"`
+		}
+		if err := urlvalues.Unmarshal(url.Values{}, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %T) = <nil>, want error", url.Values{}, &target)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package urlvalues
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// RegisterEnum registers values as the values of the enum type T, so that
+// fields of type T are decoded from the names returned by the String methods
+// of values, matched case-insensitively, and encoded as those names. This
+// saves enum types from implementing [encoding.TextUnmarshaler].
+//
+// Decoding a name that is not registered results in a [ParseError] wrapping a
+// [ConstraintError] of the "enum" constraint.
+//
+// RegisterEnum is meant to be called from init functions, and panics if T is
+// already registered, or if any two values have the same name.
+func RegisterEnum[T fmt.Stringer](values ...T) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() == reflect.Interface {
+		panic(fmt.Sprintf("urlvalues: RegisterEnum of interface type %s", typ))
+	}
+
+	set := &enumSet{values: make(map[string]reflect.Value, len(values))}
+	for _, v := range values {
+		name := v.String()
+		key := strings.ToLower(name)
+		if _, ok := set.values[key]; ok {
+			panic(fmt.Sprintf("urlvalues: RegisterEnum of %s with duplicate name %q", typ, name))
+		}
+		set.values[key] = reflect.ValueOf(v)
+		set.names = append(set.names, name)
+	}
+
+	enums.mu.Lock()
+	defer enums.mu.Unlock()
+
+	if _, ok := enums.sets[typ]; ok {
+		panic(fmt.Sprintf("urlvalues: RegisterEnum of duplicate type %s", typ))
+	}
+	enums.sets[typ] = set
+}
+
+// enums holds the enum types registered by RegisterEnum.
+var enums = struct {
+	mu   sync.RWMutex
+	sets map[reflect.Type]*enumSet
+}{sets: make(map[reflect.Type]*enumSet)}
+
+// enumSet holds the values of an enum type.
+type enumSet struct {
+	// Values keyed by their lower case names.
+	values map[string]reflect.Value
+	// Names in order of registration.
+	names []string
+}
+
+// enumOf returns the values of typ if it is a registered enum type, or nil.
+func enumOf(typ reflect.Type) *enumSet {
+	enums.mu.RLock()
+	defer enums.mu.RUnlock()
+	return enums.sets[typ]
+}
+
+// parse returns the value named name, regardless of case.
+func (s *enumSet) parse(name string) (reflect.Value, error) {
+	v, ok := s.values[strings.ToLower(name)]
+	if !ok {
+		return reflect.Value{}, &ConstraintError{
+			Constraint: "enum",
+			Limit:      strings.Join(s.names, "|"),
+			msg:        fmt.Sprintf("got %q, want one of %s", name, strings.Join(s.names, ", ")),
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,102 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nahojer/urlvalues"
+)
+
+type color int
+
+const (
+	red color = iota + 1
+	green
+	blue
+)
+
+func (c color) String() string {
+	switch c {
+	case red:
+		return "Red"
+	case green:
+		return "Green"
+	case blue:
+		return "Blue"
+	default:
+		return "unknown"
+	}
+}
+
+func init() {
+	urlvalues.RegisterEnum(red, green, blue)
+}
+
+func TestRegisterEnum(t *testing.T) {
+	type Target struct {
+		Color   color   `urlvalue:"color,default:red"`
+		Colors  []color `urlvalue:"colors"`
+		Primary *color  `urlvalue:"primary"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{"default", url.Values{}, Target{Color: red}},
+		{"case-insensitive", url.Values{"color": {"BLUE"}, "primary": {"green"}}, Target{Color: blue, Primary: ptr(green)}},
+		{"slice", url.Values{"colors": {"Red;Blue"}}, Target{Color: red, Colors: []color{red, blue}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("unknown name", func(t *testing.T) {
+		in := url.Values{"color": {"purple"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+
+		var got *urlvalues.ConstraintError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+		}
+		want := &urlvalues.ConstraintError{Constraint: "enum", Limit: "Red|Green|Blue"}
+		if diff := cmp.Diff(got, want, cmpopts.IgnoreUnexported(urlvalues.ConstraintError{})); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		in := Target{Color: green, Colors: []color{blue, red}, Primary: ptr(blue)}
+		got, err := urlvalues.Marshal(in)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+		}
+		want := url.Values{"color": {"Green"}, "colors": {"Blue", "Red"}, "primary": {"Blue"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("urlvalues.RegisterEnum(red) did not panic")
+			}
+		}()
+		urlvalues.RegisterEnum(red)
+	})
+}
@@ -0,0 +1,128 @@
+package urlvalues
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldMask is a tree of the fields selected for a partial response, decoded
+// from comma-separated dotted paths, such as "id,author.name,author.email"
+// for the tree {id, author: {name, email}}. A field without subfields in the
+// tree selects all of its subfields, so "author,author.name" selects all
+// fields of author. Likewise, an empty FieldMask selects all fields.
+type FieldMask map[string]FieldMask
+
+// Contains reports whether the field of the dotted path is selected by m,
+// either by itself or as a subfield of a selected field.
+func (m FieldMask) Contains(path string) bool {
+	sub := m
+	for _, name := range strings.Split(path, ".") {
+		if len(sub) == 0 {
+			return true
+		}
+		next, ok := sub[name]
+		if !ok {
+			return false
+		}
+		sub = next
+	}
+	return true
+}
+
+// Sub returns the mask of the subfields of field name, or nil, which selects
+// all subfields, if name has no subfields in m. Sub is meant for passing masks
+// down when rendering nested fields; check Contains first.
+func (m FieldMask) Sub(name string) FieldMask {
+	return m[name]
+}
+
+// Prune deletes the keys of v that are not selected by m, descending into
+// nested maps, and into maps held by slices, such as the objects of arrays of
+// a decoded JSON document.
+func (m FieldMask) Prune(v map[string]any) {
+	if len(m) == 0 {
+		return
+	}
+	for key, value := range v {
+		sub, ok := m[key]
+		if !ok {
+			delete(v, key)
+			continue
+		}
+		sub.pruneValue(value)
+	}
+}
+
+func (m FieldMask) pruneValue(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		m.Prune(v)
+	case []any:
+		for _, elem := range v {
+			m.pruneValue(elem)
+		}
+	}
+}
+
+// Paths returns the dotted paths of the leaves of m, sorted.
+func (m FieldMask) Paths() []string {
+	var paths []string
+	for name, sub := range m {
+		if len(sub) == 0 {
+			paths = append(paths, name)
+			continue
+		}
+		for _, path := range sub.Paths() {
+			paths = append(paths, name+"."+path)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// String returns the comma-separated paths of m.
+func (m FieldMask) String() string {
+	return strings.Join(m.Paths(), ",")
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (m FieldMask) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *FieldMask) UnmarshalText(text []byte) error {
+	mask := FieldMask{}
+	for _, path := range strings.Split(string(text), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		names := strings.Split(path, ".")
+		if slices.Contains(names, "") {
+			return fmt.Errorf("invalid field path %q", path)
+		}
+		mask.add(names)
+	}
+	*m = mask
+	return nil
+}
+
+// add adds the path of names to m. Paths below a leaf are already selected.
+func (m FieldMask) add(names []string) {
+	sub, ok := m[names[0]]
+	if len(names) == 1 {
+		// All subfields are selected.
+		m[names[0]] = FieldMask{}
+		return
+	}
+	if ok && len(sub) == 0 {
+		return
+	}
+	if !ok {
+		sub = FieldMask{}
+		m[names[0]] = sub
+	}
+	sub.add(names[1:])
+}
@@ -0,0 +1,102 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestFieldMask(t *testing.T) {
+	type Target struct {
+		Fields urlvalues.FieldMask `urlvalue:"fields"`
+	}
+
+	tests := []struct {
+		in   string
+		want urlvalues.FieldMask
+	}{
+		{"", urlvalues.FieldMask{}},
+		{"id, author.name,author.email", urlvalues.FieldMask{"id": {}, "author": {"name": {}, "email": {}}}},
+		{"author.name,author", urlvalues.FieldMask{"author": {}}},
+		{"author,author.name", urlvalues.FieldMask{"author": {}}},
+		{"a.b.c,a.b", urlvalues.FieldMask{"a": {"b": {}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			in := url.Values{"fields": {tt.in}}
+			var target Target
+			if err := urlvalues.Unmarshal(in, &target); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+			}
+			if diff := cmp.Diff(target.Fields, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []string{"a..b", ".a", "a."} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			values := url.Values{"fields": {in}}
+			var target Target
+			if err := urlvalues.Unmarshal(values, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", values, &target)
+			}
+		})
+	}
+
+	mask := urlvalues.FieldMask{"id": {}, "author": {"name": {}}}
+
+	t.Run("contains", func(t *testing.T) {
+		for path, want := range map[string]bool{
+			"id":           true,
+			"id.anything":  true,
+			"author":       true,
+			"author.name":  true,
+			"author.email": false,
+			"title":        false,
+		} {
+			if got := mask.Contains(path); got != want {
+				t.Errorf("Contains(%q) = %t, want %t", path, got, want)
+			}
+		}
+		if !(urlvalues.FieldMask{}).Contains("anything") {
+			t.Errorf("Contains(%q) of empty mask = false, want true", "anything")
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		v := map[string]any{
+			"id":    1,
+			"title": "x",
+			"author": map[string]any{
+				"name":  "a",
+				"email": "b",
+			},
+		}
+		mask.Prune(v)
+		want := map[string]any{"id": 1, "author": map[string]any{"name": "a"}}
+		if diff := cmp.Diff(v, want); diff != "" {
+			t.Errorf("Prune(...) -got +want\n%s", diff)
+		}
+
+		items := map[string]any{"items": []any{map[string]any{"id": 1, "x": 2}}}
+		urlvalues.FieldMask{"items": {"id": {}}}.Prune(items)
+		want = map[string]any{"items": []any{map[string]any{"id": 1}}}
+		if diff := cmp.Diff(items, want); diff != "" {
+			t.Errorf("Prune(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		got, err := urlvalues.Marshal(Target{Fields: mask})
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(...) = %q, want <nil>", err)
+		}
+		want := url.Values{"fields": {"author.name,id"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+		}
+	})
+}
@@ -2,11 +2,20 @@ package urlvalues
 
 import (
 	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"mime/multipart"
 	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // FieldError occurs when an error occurs updating an individual field in the
@@ -22,21 +31,209 @@ func (err *FieldError) Error() string {
 	return fmt.Sprintf("urlvalues: error assigning to field %s: converting '%s' to type %s. details: %s", err.fieldName, err.value, err.typeName, err.err)
 }
 
+// Unwrap returns the underlying error.
+func (err *FieldError) Unwrap() error {
+	return err.err
+}
+
+// MarshalJSON encodes err as a JSON object like [ParseError.MarshalJSON],
+// without a key.
+func (err *FieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errorJSON{
+		Field:        err.fieldName,
+		Value:        err.value,
+		ExpectedType: err.typeName,
+		Message:      err.err.Error(),
+	})
+}
+
+// OverflowError occurs when an integer value is out of range for the type of
+// a field, such as 300 for an int8 field. It wraps the underlying
+// [strconv.NumError], which matches [strconv.ErrRange].
+type OverflowError struct {
+	// Smallest value of the type.
+	Min int64
+	// Largest value of the type.
+	Max uint64
+
+	err error
+}
+
+func (err *OverflowError) Error() string {
+	return fmt.Sprintf("value out of range, must be between %d and %d", err.Min, err.Max)
+}
+
+// Unwrap returns the underlying error.
+func (err *OverflowError) Unwrap() error {
+	return err.err
+}
+
+// overflowError returns an OverflowError reporting the range of the integer
+// type typ if err is a range error, or err otherwise. Negative values of
+// unsigned types are reported as out of range as well.
+func overflowError(typ reflect.Type, value string, err error) error {
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		return err
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if numErr.Err != strconv.ErrRange {
+			return err
+		}
+		bits := typ.Bits()
+		return &OverflowError{Min: -1 << (bits - 1), Max: 1<<(bits-1) - 1, err: err}
+	default:
+		if numErr.Err == strconv.ErrSyntax && strings.HasPrefix(value, "-") {
+			// ParseUint rejects signs as invalid syntax.
+			if _, intErr := strconv.ParseInt(value, 0, 64); intErr == nil || errors.Is(intErr, strconv.ErrRange) {
+				numErr = &strconv.NumError{Func: numErr.Func, Num: numErr.Num, Err: strconv.ErrRange}
+			}
+		}
+		if numErr.Err != strconv.ErrRange {
+			return err
+		}
+		return &OverflowError{Max: 1<<typ.Bits() - 1, err: numErr}
+	}
+}
+
 // field maintains information about a field in the target struct.
 type field struct {
 	name    string
 	field   reflect.Value
 	options fieldOptions
+	// Allocations of the nil struct pointers that field is nested in,
+	// outermost first. See allocate.
+	allocs []allocation
+	// Keys of the structs that field is nested in, outermost first. See
+	// fullKey.
+	path []pathKey
+	// Keys of the struct decoded into field if it is an interface field,
+	// outermost first. See decodeImplementation.
+	implPath []pathKey
+	// Types of the structs that field is nested in if it is an interface
+	// field, outermost first, limiting the depth of implementations nested
+	// in implementations. See extractImplementation.
+	implParents []reflect.Type
+}
+
+// pathKey is the key of a struct that a field is nested in.
+type pathKey struct {
+	key string
+	// Whether the key prefixes the keys of the fields of the struct
+	// regardless of the key style, as set by the "prefix" tag option.
+	prefix bool
+}
+
+// allocation is a struct allocated for a nil struct pointer, which is not
+// assigned to the pointer until any of its fields are set.
+type allocation struct {
+	ptr   reflect.Value
+	value reflect.Value
+	// Whether the pointer is left nil unless a key of the struct is present,
+	// as set by the "optional" tag option.
+	optional bool
+}
+
+// allocate assigns the structs allocated for the nil struct pointers that f is
+// nested in, so that f is reachable from the target. Nested structs are
+// allocated lazily like this so that decoding doesn't leave behind pointers to
+// empty structs. Unless present is true, that is, unless f was decoded from a
+// present key, optional structs and the structs nested in them are left
+// unassigned.
+func (f field) allocate(present bool) {
+	for _, alloc := range f.allocs {
+		if alloc.optional && !present {
+			return
+		}
+		if alloc.ptr.IsNil() {
+			alloc.ptr.Set(alloc.value)
+		}
+	}
+}
+
+// optional reports whether f is nested in an optional struct that is not
+// assigned.
+func (f field) optional() bool {
+	for _, alloc := range f.allocs {
+		if alloc.optional && alloc.ptr.IsNil() {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the access key into URL values for this field. Defaults to the
+// field name if a custom key is not set in tags.
+func (f field) key() string {
+	if f.options.key != "" {
+		return f.options.key
+	}
+	return f.name
+}
+
+// keys returns all keys into URL values for this field, starting with the key
+// returned by key followed by any aliases and deprecated keys.
+func (f field) keys() []string {
+	keys := append([]string{f.key()}, f.options.aliases...)
+	return append(keys, f.options.deprecated...)
 }
 
 // fieldOptions maintain options for a given field.
 type fieldOptions struct {
-	key          string
-	defaultValue string
-	layout       string
+	key           string
+	defaultValue  string
+	defaultIfs    []defaultIf
+	example       string
+	layout        string
+	source        string
+	discriminator string
+	aliases       []string
+	deprecated    []string
+	maxSliceLen   int
+	dedupe        bool
+	sort          bool
+	lower         bool
+	upper         bool
+	trim          bool
+	finite        bool
+	unescape      bool
+	trueValues    []string
+	falseValues   []string
+	prefix        bool
+	squash        bool
+	optional      bool
+	geopoint      bool
+	passthrough   bool
+	ago           bool
+	// Whether the field is only decoded, or only encoded.
+	decodeOnly bool
+	encodeOnly bool
+	minItems   *int
+	maxItems   *int
+	min        *float64
+	max        *float64
+	// Whether min or max is given as a duration.
+	durationBounds bool
+	clampMin       *float64
+	clampMax       *float64
+	before         string
+	after          string
+	oneOf          []string
+	pattern        *regexp.Regexp
+	// Names of validators registered by Decoder.RegisterValidation.
+	validators []string
 }
 
-func extractFields(target any) ([]field, error) {
+// maxDepth is the maximum depth of nested structs that fields are extracted
+// from.
+const maxDepth = 32
+
+// extractFields extracts the fields of the struct pointed to by target,
+// appending them to fields. Names and options of fields are read from struct
+// tags with the key returned by pOpts.TagName.
+func extractFields(target any, fields []field, pOpts *ParseOptions) ([]field, error) {
 	strct := reflect.ValueOf(target)
 	if strct.Kind() != reflect.Ptr {
 		return nil, ErrInvalidStruct
@@ -46,13 +243,49 @@ func extractFields(target any) ([]field, error) {
 		return nil, ErrInvalidStruct
 	}
 
-	var fields []field
+	return extractStructFields(fields, strct, pOpts, nil, nil, nil)
+}
+
+// fieldsPool holds slices of fields reused across calls to Unmarshal, so that
+// decoding complex structs on every request doesn't allocate a new slice of
+// fields each time. See getFields and putFields.
+var fieldsPool = sync.Pool{
+	New: func() any { return new([]field) },
+}
+
+// getFields returns an empty slice of fields from fieldsPool, along with the
+// pointer to return to the pool by putFields.
+func getFields() (*[]field, []field) {
+	buf := fieldsPool.Get().(*[]field)
+	return buf, (*buf)[:0]
+}
+
+// putFields returns fields to fieldsPool through buf. The fields are cleared
+// first, so that the pool doesn't keep the decoded structs alive.
+func putFields(buf *[]field, fields []field) {
+	clear(fields)
+	*buf = fields[:0]
+	fieldsPool.Put(buf)
+}
+
+// extractStructFields extracts the fields of strct, appending them to fields and
+// drilling down into nested structs. parents holds the types of the structs that strct is nested in,
+// used to detect cyclic struct types such as linked list nodes. allocs holds
+// the allocations of the nil struct pointers that strct is nested in, and
+// path the keys of the structs that strct is nested in.
+func extractStructFields(fields []field, strct reflect.Value, pOpts *ParseOptions, parents []reflect.Type, allocs []allocation, path []pathKey) ([]field, error) {
+	if len(parents) >= maxDepth {
+		return nil, fmt.Errorf("urlvalues: structs nested deeper than %d levels", maxDepth)
+	}
+	parents = append(parents, strct.Type())
+
+	fields = slices.Grow(fields, strct.NumField())
 	for i := 0; i < strct.NumField(); i++ {
 		f := strct.Field(i)
 		strctField := strct.Type().Field(i)
 
-		// Get the urlvalue tags associated with this field (if any).
-		fieldTags := strctField.Tag.Get("urlvalue")
+		// Get the tags associated with this field (if any).
+		fieldTags := strctField.Tag.Get(pOpts.TagName())
 
 		// If it's ignored or can't be set, move on.
 		if !f.CanSet() || fieldTags == "-" {
@@ -65,36 +298,116 @@ func extractFields(target any) ([]field, error) {
 		if err != nil {
 			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: %w", fieldName, err)
 		}
+		if fieldOpts.key == "" {
+			fieldOpts.key = pOpts.keyNaming.key(fieldName)
+		}
+
+		if fieldOpts.prefix && fieldOpts.squash {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: prefix and squash options are mutually exclusive", fieldName)
+		}
+
+		if fieldOpts.lower && fieldOpts.upper {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: lower and upper options are mutually exclusive", fieldName)
+		}
+
+		if fieldOpts.optional && (f.Kind() != reflect.Ptr || f.Type().Elem().Kind() != reflect.Struct) {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: optional option not supported by type %s", fieldName, f.Type())
+		}
+
+		if fieldOpts.geopoint && !isGeoPoint(f.Type()) {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: geopoint option not supported by type %s", fieldName, f.Type())
+		}
+
+		if fieldOpts.ago && !isTimeType(f.Type()) {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: ago option not supported by type %s", fieldName, f.Type())
+		}
+
+		if fieldOpts.passthrough && f.Type() != valuesType {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: passthrough option not supported by type %s", fieldName, f.Type())
+		}
+
+		if fieldOpts.sort && !isSortable(f.Type()) {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: sort option not supported by type %s", fieldName, f.Type())
+		}
+
+		if (fieldOpts.minItems != nil || fieldOpts.maxItems != nil) && !holdsMultiple(f) {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: minitems and maxitems options not supported by type %s", fieldName, f.Type())
+		}
+
+		if err := checkConstraintTypes(f.Type(), fieldOpts); err != nil {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: %w", fieldName, err)
+		}
+
+		// File uploads are assigned as is, so don't drill down into them.
+		if isFileField(f) {
+			fields = append(fields, field{
+				name:    fieldName,
+				field:   f,
+				options: fieldOpts,
+				allocs:  allocs,
+				path:    path,
+			})
+			continue
+		}
 
 		// Drill down through pointers until we bottom out at type or nil.
+		fieldAllocs := allocs
 		for f.Kind() == reflect.Ptr {
+			// Drilling down into a struct we are already in would never end.
+			if slices.Contains(parents, f.Type().Elem()) {
+				return nil, fmt.Errorf("urlvalues: field %s: cyclic struct type %s", fieldName, f.Type().Elem())
+			}
+
 			if f.IsNil() {
 				// It's not a struct so leave it alone.
 				if f.Type().Elem().Kind() != reflect.Struct {
 					break
 				}
 
-				// It is a struct so zero it out.
-				f.Set(reflect.New(f.Type().Elem()))
+				// It is a struct so allocate it, but leave the pointer nil
+				// until any of its fields are set.
+				value := reflect.New(f.Type().Elem())
+				fieldAllocs = append(slices.Clip(fieldAllocs), allocation{ptr: f, value: value, optional: fieldOpts.optional})
+				f = value.Elem()
+				continue
 			}
 			f = f.Elem()
 		}
 
+		if fieldOpts.discriminator != "" && f.Kind() != reflect.Interface {
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: discriminator option not supported by type %s", fieldName, f.Type())
+		}
+
 		switch {
 		// If we found a struct that can't deserialize itself, drill down, appending
 		// fields as we go.
-		case f.Kind() == reflect.Struct && textUnmarshaler(f) == nil && binaryUnmarshaler(f) == nil:
-			embeddedPtr := f.Addr().Interface()
-			innerFields, err := extractFields(embeddedPtr)
+		case f.Kind() == reflect.Struct && !fieldOpts.geopoint && textUnmarshaler(f) == nil && textUnmarshalerContext(f) == nil && binaryUnmarshaler(f) == nil && operandDecoderOf(f) == nil:
+			fieldPath := nestedPath(path, strctField, fieldOpts)
+			fields, err = extractStructFields(fields, f, pOpts, parents, fieldAllocs, fieldPath)
 			if err != nil {
-				return nil, fmt.Errorf("urlvalues: %w", err)
+				return nil, err
 			}
-			fields = append(fields, innerFields...)
+		// Implementations of interfaces are decoded like nested structs, but
+		// are only known once decoding.
+		case f.Kind() == reflect.Interface:
+			fields = append(fields, field{
+				name:        fieldName,
+				field:       f,
+				options:     fieldOpts,
+				allocs:      fieldAllocs,
+				path:        path,
+				implPath:    nestedPath(path, strctField, fieldOpts),
+				implParents: slices.Clone(parents),
+			})
+		case fieldOpts.prefix || fieldOpts.squash:
+			return nil, fmt.Errorf("urlvalues: parsing tags for field %s: prefix and squash options not supported by type %s", fieldName, f.Type())
 		default:
 			fields = append(fields, field{
 				name:    fieldName,
 				field:   f,
 				options: fieldOpts,
+				allocs:  fieldAllocs,
+				path:    path,
 			})
 		}
 	}
@@ -102,6 +415,21 @@ func extractFields(target any) ([]field, error) {
 	return fields, nil
 }
 
+// nestedPath returns path extended by the key of strctField, a nested struct
+// or interface field, unless the fields of the struct are keyed as if they
+// were fields of the outer struct. This is the case for embedded structs,
+// unless prefixed, and for squashed structs.
+func nestedPath(path []pathKey, strctField reflect.StructField, fOpts fieldOptions) []pathKey {
+	if fOpts.squash || (strctField.Anonymous && !fOpts.prefix) {
+		return path
+	}
+	key := fOpts.key
+	if key == "" {
+		key = strctField.Name
+	}
+	return append(slices.Clip(path), pathKey{key: key, prefix: fOpts.prefix})
+}
+
 func parseTag(tagStr string) (fieldOptions, error) {
 	if tagStr == "" {
 		return fieldOptions{}, nil
@@ -109,50 +437,309 @@ func parseTag(tagStr string) (fieldOptions, error) {
 
 	var fOpts fieldOptions
 
-	tagParts := strings.Split(tagStr, ",")
-	for i, tagPart := range tagParts {
-		vals := strings.SplitN(tagPart, ":", 2)
-		tagProp := strings.TrimSpace(vals[0])
+	// Cut rather than split the tag, so that parsing it allocates nothing but
+	// the options themselves.
+	for i, rest, more := 0, tagStr, true; more; i++ {
+		var (
+			opt tagOption
+			err error
+		)
+		opt, rest, more, err = cutTagOption(rest)
+		if err != nil {
+			return fOpts, err
+		}
+		tagProp, tagPropVal, hasVal := strings.TrimSpace(opt.name), opt.value, opt.hasValue
 
-		switch len(vals) {
-		case 1:
-			switch tagProp {
-			default:
-				if i == 0 {
-					fOpts.key = tagProp
-				}
+		switch hasVal {
+		case false:
+			switch {
+			case i == 0:
+				fOpts.key = tagProp
+			case tagProp == "dedupe":
+				fOpts.dedupe = true
+			case tagProp == "sort":
+				fOpts.sort = true
+			case tagProp == "lower":
+				fOpts.lower = true
+			case tagProp == "upper":
+				fOpts.upper = true
+			case tagProp == "trim":
+				fOpts.trim = true
+			case tagProp == "finite":
+				fOpts.finite = true
+			case tagProp == "unescape":
+				fOpts.unescape = true
+			case tagProp == "prefix":
+				fOpts.prefix = true
+			case tagProp == "squash":
+				fOpts.squash = true
+			case tagProp == "optional":
+				fOpts.optional = true
+			case tagProp == "geopoint":
+				fOpts.geopoint = true
+			case tagProp == "passthrough":
+				fOpts.passthrough = true
+			case tagProp == "ago":
+				fOpts.ago = true
+			case tagProp == "decodeonly":
+				fOpts.decodeOnly = true
+			case tagProp == "encodeonly":
+				fOpts.encodeOnly = true
+			}
+		case true:
+			if !opt.quoted {
+				tagPropVal = strings.TrimSpace(tagPropVal)
 			}
-		case 2:
-			tagPropVal := strings.TrimSpace(vals[1])
 			if tagPropVal == "" {
 				return fOpts, fmt.Errorf("tag %q missing a value", tagProp)
 			}
 			switch tagProp {
 			case "default":
 				fOpts.defaultValue = tagPropVal
+			case "default_if":
+				d, err := parseDefaultIf(tagPropVal)
+				if err != nil {
+					return fOpts, fmt.Errorf("tag %q has invalid value %q: %w", tagProp, tagPropVal, err)
+				}
+				fOpts.defaultIfs = append(fOpts.defaultIfs, d)
+			case "example":
+				fOpts.example = tagPropVal
 			case "layout":
 				fOpts.layout = tagPropVal
+			case "alias":
+				for _, alias := range strings.Split(tagPropVal, "|") {
+					fOpts.aliases = append(fOpts.aliases, strings.TrimSpace(alias))
+				}
+			case "deprecated":
+				for _, key := range strings.Split(tagPropVal, "|") {
+					fOpts.deprecated = append(fOpts.deprecated, strings.TrimSpace(key))
+				}
+			case "maxslicelen":
+				n, err := strconv.Atoi(tagPropVal)
+				if err != nil || n < 0 {
+					return fOpts, fmt.Errorf("tag %q has invalid value %q", tagProp, tagPropVal)
+				}
+				fOpts.maxSliceLen = n
+			case "minitems", "maxitems":
+				n, err := strconv.Atoi(tagPropVal)
+				if err != nil || n < 0 {
+					return fOpts, fmt.Errorf("tag %q has invalid value %q", tagProp, tagPropVal)
+				}
+				if tagProp == "minitems" {
+					fOpts.minItems = &n
+				} else {
+					fOpts.maxItems = &n
+				}
+			case "min", "max":
+				n, err := strconv.ParseFloat(tagPropVal, 64)
+				if err != nil {
+					// Bounds of time.Duration fields may be durations.
+					d, durErr := time.ParseDuration(tagPropVal)
+					if durErr != nil {
+						return fOpts, fmt.Errorf("tag %q has invalid value %q", tagProp, tagPropVal)
+					}
+					n = float64(d)
+					fOpts.durationBounds = true
+				}
+				if tagProp == "min" {
+					fOpts.min = &n
+				} else {
+					fOpts.max = &n
+				}
+			case "clamp":
+				lo, hi, err := parseClamp(tagPropVal)
+				if err != nil {
+					return fOpts, fmt.Errorf("tag %q has invalid value %q: %w", tagProp, tagPropVal, err)
+				}
+				fOpts.clampMin, fOpts.clampMax = lo, hi
+			case "before":
+				fOpts.before = tagPropVal
+			case "after":
+				fOpts.after = tagPropVal
+			case "oneof":
+				fOpts.oneOf = append(fOpts.oneOf, splitTagList(tagPropVal)...)
+			case "truevals":
+				fOpts.trueValues = splitTagList(tagPropVal)
+			case "falsevals":
+				fOpts.falseValues = splitTagList(tagPropVal)
+			case "validate":
+				fOpts.validators = append(fOpts.validators, splitTagList(tagPropVal)...)
+			case "pattern":
+				re, err := regexp.Compile(tagPropVal)
+				if err != nil {
+					return fOpts, fmt.Errorf("tag %q has invalid value %q: %w", tagProp, tagPropVal, err)
+				}
+				fOpts.pattern = re
+			case "discriminator":
+				fOpts.discriminator = tagPropVal
+			case "source":
+				if !isSource(tagPropVal) {
+					return fOpts, fmt.Errorf("unknown source %q", tagPropVal)
+				}
+				fOpts.source = tagPropVal
 			}
 		}
 	}
 
+	if fOpts.decodeOnly && fOpts.encodeOnly {
+		return fOpts, errors.New("tags \"decodeonly\" and \"encodeonly\" are mutually exclusive")
+	}
+
 	return fOpts, nil
 }
 
+// tagOption is an option of a tag, such as "default:42".
+type tagOption struct {
+	name, value string
+	hasValue    bool
+	// Whether the value is quoted, in which case it is used as is.
+	quoted bool
+}
+
+// cutTagOption cuts the first option off the comma-separated options of tag,
+// returning the option and the remaining options, if any. Commas and colons
+// are part of the value of an option if enclosed in single quotes, or escaped
+// by a backslash, such as in "default:'a,b'" or "default:a\\,b".
+func cutTagOption(tag string) (opt tagOption, rest string, more bool, err error) {
+	part := tag
+	if i := strings.IndexByte(tag, ','); i >= 0 {
+		part, rest, more = tag[:i], tag[i+1:], true
+	}
+
+	// Options without quotes or escapes are cut as is, allocating nothing.
+	if !strings.ContainsAny(part, `'\`) {
+		opt.name, opt.value, opt.hasValue = strings.Cut(part, ":")
+		return opt, rest, more, nil
+	}
+
+	var (
+		b                 strings.Builder
+		quoting, escaping bool
+	)
+	rest, more = "", false
+scan:
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case escaping:
+			b.WriteByte(c)
+			escaping = false
+		case c == '\\':
+			escaping = true
+		case c == '\'':
+			quoting = !quoting
+			opt.quoted = opt.quoted || opt.hasValue
+		case quoting:
+			b.WriteByte(c)
+		case c == ',':
+			rest, more = tag[i+1:], true
+			break scan
+		case c == ':' && !opt.hasValue:
+			opt.name, opt.hasValue = b.String(), true
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	if escaping {
+		return opt, "", false, fmt.Errorf("tag %q ends with an unescaped backslash", tag)
+	}
+	if quoting {
+		return opt, "", false, fmt.Errorf("tag %q has an unterminated quote", tag)
+	}
+
+	if opt.hasValue {
+		opt.value = b.String()
+	} else {
+		opt.name = b.String()
+	}
+	return opt, rest, more, nil
+}
+
+// normalizes reports whether any of the "trim", "lower" and "upper" options
+// are set.
+func (fOpts fieldOptions) normalizes() bool {
+	return fOpts.trim || fOpts.lower || fOpts.upper
+}
+
+// normalize applies the "trim", "lower" and "upper" options to value.
+func (fOpts fieldOptions) normalize(value string) string {
+	if fOpts.trim {
+		value = strings.TrimSpace(value)
+	}
+	switch {
+	case fOpts.lower:
+		value = strings.ToLower(value)
+	case fOpts.upper:
+		value = strings.ToUpper(value)
+	}
+	return value
+}
+
+// processOperands decodes the operands of value into f like fields of the
+// types of the operands. It is kept out of processField, since the closure
+// passed to f moves the options to the heap.
+func processOperands(f operandDecoder, settingDefault bool, value string, fOpts fieldOptions, pOpts ParseOptions) error {
+	return f.decodeOperands(value, func(value string, dst reflect.Value) error {
+		return processField(settingDefault, value, dst, fOpts, pOpts)
+	})
+}
+
 func processField(settingDefault bool, value string, field reflect.Value, fOpts fieldOptions, pOpts ParseOptions) error {
 	typ := field.Type()
+	value = fOpts.normalize(value)
+
+	// Dereference pointer, allocating it if nil, such as the elements of
+	// slices of pointers, so that unmarshalers are never called on nil.
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		if field.IsNil() {
+			field.Set(reflect.New(typ))
+		}
+		field = field.Elem()
+	}
 
 	// Extend time.Time parsing to accept custom layouts and our own "now" based
 	// parsing.
-	if typ.PkgPath() == "time" && typ.Name() == "Time" {
-		tim, err := parseTime(fOpts.layout, value)
+	if typ == timeType {
+		parse := parseTime
+		if pOpts.unixTime {
+			parse = parseUnixTime
+		}
+		layout := fieldLayout(fOpts, pOpts)
+		var (
+			tim time.Time
+			err error
+		)
+		if fOpts.ago {
+			tim, err = parseAgo(parse, layout, value)
+		} else {
+			tim, err = parse(layout, value)
+		}
 		if err != nil {
 			return err
 		}
-		field.Set(reflect.ValueOf(tim))
+		// Assign through a pointer when possible, which unlike reflect.ValueOf
+		// doesn't box the time.
+		if field.CanAddr() {
+			*field.Addr().Interface().(*time.Time) = tim
+		} else {
+			field.Set(reflect.ValueOf(tim))
+		}
 		return nil
 	}
 
+	// Types composed of operands, such as filters, decode their operands like
+	// fields of the types of the operands.
+	if f := operandDecoderOf(field); f != nil {
+		return processOperands(f, settingDefault, value, fOpts, pOpts)
+	}
+
+	// Types implementing TextUnmarshalerContext.
+	if t := textUnmarshalerContext(field); t != nil {
+		return t.UnmarshalTextContext(pOpts.context(), []byte(value))
+	}
+
 	// Types implementing encoding.TextUnmarshaler.
 	if t := textUnmarshaler(field); t != nil {
 		return t.UnmarshalText([]byte(value))
@@ -163,23 +750,27 @@ func processField(settingDefault bool, value string, field reflect.Value, fOpts
 		return b.UnmarshalBinary([]byte(value))
 	}
 
-	// Dereference pointer.
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
-		if field.IsNil() {
-			field.Set(reflect.New(typ))
-		}
-		field = field.Elem()
-	}
-
 	// We don't want a default value to override a proper setting.
 	if settingDefault && !field.IsZero() {
 		return nil
 	}
 
+	// Enum types registered by RegisterEnum.
+	if e := enumOf(typ); e != nil {
+		v, err := e.parse(value)
+		if err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+
 	// Builtin types.
 	switch typ.Kind() {
 	case reflect.String:
+		if pOpts.plusAsSpace && !settingDefault {
+			value = strings.ReplaceAll(value, "+", " ")
+		}
 		field.SetString(value)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -187,27 +778,32 @@ func processField(settingDefault bool, value string, field reflect.Value, fOpts
 			val int64
 			err error
 		)
-		if field.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
+		if typ == durationType {
 			var d time.Duration
 			d, err = time.ParseDuration(value)
 			val = int64(d)
+		} else if r, ok := parseRune(typ, value); ok {
+			val = int64(r)
 		} else {
-			val, err = strconv.ParseInt(value, 0, typ.Bits())
+			val, err = strconv.ParseInt(value, pOpts.intBase(), typ.Bits())
+			if err != nil {
+				return overflowError(typ, value, err)
+			}
 		}
 		if err != nil {
 			return err
 		}
 		field.SetInt(val)
 
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		val, err := strconv.ParseUint(value, 0, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		val, err := strconv.ParseUint(value, pOpts.intBase(), typ.Bits())
 		if err != nil {
-			return err
+			return overflowError(typ, value, err)
 		}
 		field.SetUint(val)
 
 	case reflect.Bool:
-		val, err := strconv.ParseBool(value)
+		val, err := parseBool(value, fOpts)
 		if err != nil {
 			return err
 		}
@@ -218,69 +814,346 @@ func processField(settingDefault bool, value string, field reflect.Value, fOpts
 		if err != nil {
 			return err
 		}
+		if (fOpts.finite || pOpts.finiteFloats) && (math.IsNaN(val) || math.IsInf(val, 0)) {
+			return &ConstraintError{
+				Constraint: "finite",
+				msg:        fmt.Sprintf("got %s, want a finite number", formatNumber(val)),
+			}
+		}
 		field.SetFloat(val)
 
 	case reflect.Slice:
-		vals := strings.Split(value, pOpts.Delim())
-		sl := reflect.MakeSlice(typ, len(vals), len(vals))
-		for i, val := range vals {
-			err := processField(false, val, sl.Index(i), fOpts, pOpts)
-			if err != nil {
-				return err
-			}
+		if err := checkSliceLen(pOpts.countItems(value), fOpts, pOpts); err != nil {
+			return err
 		}
-		field.Set(sl)
+		return setSlice(field, pOpts.split(value), fOpts, pOpts)
 
 	case reflect.Map:
-		mp := reflect.MakeMap(typ)
-		if len(strings.TrimSpace(value)) != 0 {
-			pairs := strings.Split(value, pOpts.Delim())
-			for _, pair := range pairs {
-				kvpair := strings.Split(pair, ":")
-				if len(kvpair) != 2 {
-					return fmt.Errorf("invalid map item: %q", pair)
-				}
-				k := reflect.New(typ.Key()).Elem()
-				err := processField(false, kvpair[0], k, fOpts, pOpts)
-				if err != nil {
-					return err
-				}
-				v := reflect.New(typ.Elem()).Elem()
-				err = processField(false, kvpair[1], v, fOpts, pOpts)
-				if err != nil {
-					return err
-				}
-				mp.SetMapIndex(k, v)
-			}
+		if len(strings.TrimSpace(value)) == 0 {
+			field.Set(reflect.MakeMap(typ))
+			return nil
+		}
+		if err := checkSliceLen(pOpts.countItems(value), fOpts, pOpts); err != nil {
+			return err
+		}
+		return setMap(field, pOpts.split(value), fOpts, pOpts)
+
+	case reflect.Struct:
+		if fOpts.geopoint {
+			return setGeoPoint(field, value)
 		}
-		field.Set(mp)
 	}
 
 	return nil
 }
 
-func textUnmarshaler(field reflect.Value) (t encoding.TextUnmarshaler) {
-	interfaceFrom(field, func(v any, ok *bool) {
-		t, *ok = v.(encoding.TextUnmarshaler)
-	})
-	return t
+// splitTagList splits the value of a tag option holding a list of values
+// separated by vertical bars (|).
+func splitTagList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, "|") {
+		list = append(list, strings.TrimSpace(v))
+	}
+	return list
+}
+
+// parseBool parses value as a bool, matching the values of the "truevals" and
+// "falsevals" options case-insensitively before falling back to
+// strconv.ParseBool.
+func parseBool(value string, fOpts fieldOptions) (bool, error) {
+	for _, v := range fOpts.trueValues {
+		if strings.EqualFold(value, v) {
+			return true, nil
+		}
+	}
+	for _, v := range fOpts.falseValues {
+		if strings.EqualFold(value, v) {
+			return false, nil
+		}
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseRune parses value as the code point of a single character if typ is
+// int32, the underlying type of rune, and value is a single character other
+// than a digit. Digits are left to be parsed as numbers, like any other value
+// of int32 fields.
+func parseRune(typ reflect.Type, value string) (rune, bool) {
+	if typ.Kind() != reflect.Int32 {
+		return 0, false
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if size == 0 || size != len(value) || (r == utf8.RuneError && size == 1) || ('0' <= r && r <= '9') {
+		return 0, false
+	}
+	return r, true
+}
+
+// processValues decodes the values of a key present multiple times into
+// field, which is a slice or map, or a pointer to one. Unlike processField,
+// each value is decoded as a single element as is, without being split by the
+// delimiter, unless pOpts splits repeated values.
+func processValues(values []string, field reflect.Value, fOpts fieldOptions, pOpts ParseOptions) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	if pOpts.splitRepeated {
+		n := 0
+		for _, v := range values {
+			n += pOpts.countItems(v)
+		}
+		if err := checkSliceLen(n, fOpts, pOpts); err != nil {
+			return err
+		}
+		split := make([]string, 0, n)
+		for _, v := range values {
+			split = append(split, pOpts.split(v)...)
+		}
+		values = split
+	}
+
+	if err := checkSliceLen(len(values), fOpts, pOpts); err != nil {
+		return err
+	}
+	if field.Kind() == reflect.Map {
+		return setMap(field, values, fOpts, pOpts)
+	}
+	return setSlice(field, values, fOpts, pOpts)
+}
+
+// setSlice sets the slice field to vals, decoding each value as an element.
+func setSlice(field reflect.Value, vals []string, fOpts fieldOptions, pOpts ParseOptions) error {
+	if fOpts.normalizes() || fOpts.dedupe || pOpts.dedupeSlices {
+		// Don't modify the values of the caller.
+		vals = slices.Clone(vals)
+	}
+	if fOpts.normalizes() {
+		// Normalize before deduplicating, so that values differing only in
+		// case or surrounding whitespace are considered equal.
+		for i, val := range vals {
+			vals[i] = fOpts.normalize(val)
+		}
+	}
+	if fOpts.dedupe || pOpts.dedupeSlices {
+		vals = dedupe(vals)
+	}
+
+	sl := reflect.MakeSlice(field.Type(), len(vals), len(vals))
+	for i, val := range vals {
+		if err := processField(false, val, sl.Index(i), fOpts, pOpts); err != nil {
+			return err
+		}
+	}
+	if fOpts.sort {
+		sortSlice(sl)
+	}
+	field.Set(sl)
+	return nil
+}
+
+// setMap sets the map field to the key-value pairs in pairs, each separated by
+// a colon.
+func setMap(field reflect.Value, pairs []string, fOpts fieldOptions, pOpts ParseOptions) error {
+	typ := field.Type()
+	mp := reflect.MakeMap(typ)
+	for _, pair := range pairs {
+		kvpair := strings.Split(pair, ":")
+		if len(kvpair) != 2 {
+			return fmt.Errorf("invalid map item: %q", pair)
+		}
+		k := reflect.New(typ.Key()).Elem()
+		if err := processField(false, kvpair[0], k, fOpts, pOpts); err != nil {
+			return err
+		}
+		v := reflect.New(typ.Elem()).Elem()
+		if err := processField(false, kvpair[1], v, fOpts, pOpts); err != nil {
+			return err
+		}
+		mp.SetMapIndex(k, v)
+	}
+	field.Set(mp)
+	return nil
+}
+
+// dedupe removes all but the first occurrence of each value in vals, in place.
+func dedupe(vals []string) []string {
+	seen := make(map[string]struct{}, len(vals))
+	deduped := vals[:0]
+	for _, val := range vals {
+		if _, ok := seen[val]; ok {
+			continue
+		}
+		seen[val] = struct{}{}
+		deduped = append(deduped, val)
+	}
+	return deduped
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+)
+
+// isSortable reports whether typ is a slice, or pointer to a slice, with
+// elements that sortSlice knows how to order.
+func isSortable(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Slice {
+		return false
+	}
+
+	elem := typ.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem == timeType {
+		return true
+	}
+	switch elem.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
 }
 
-func binaryUnmarshaler(field reflect.Value) (b encoding.BinaryUnmarshaler) {
-	interfaceFrom(field, func(v any, ok *bool) {
-		b, *ok = v.(encoding.BinaryUnmarshaler)
+// sortSlice sorts the elements of sl in increasing order, comparing the
+// values pointed to by elements that are pointers. The type of sl must be
+// sortable according to isSortable, and its elements must not be nil.
+func sortSlice(sl reflect.Value) {
+	elem := sl.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem == timeType {
+		sort.SliceStable(sl.Interface(), func(i, j int) bool {
+			a, b := reflect.Indirect(sl.Index(i)), reflect.Indirect(sl.Index(j))
+			return a.Interface().(time.Time).Before(b.Interface().(time.Time))
+		})
+		return
+	}
+
+	sort.SliceStable(sl.Interface(), func(i, j int) bool {
+		a, b := reflect.Indirect(sl.Index(i)), reflect.Indirect(sl.Index(j))
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		default:
+			return a.Float() < b.Float()
+		}
 	})
-	return b
 }
 
-func interfaceFrom(field reflect.Value, fn func(any, *bool)) {
-	if !field.CanInterface() {
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.SliceOf(fileHeaderType)
+)
+
+// isFileField reports whether field is of type *multipart.FileHeader or
+// []*multipart.FileHeader.
+func isFileField(field reflect.Value) bool {
+	typ := field.Type()
+	return typ == fileHeaderType || typ == fileHeaderSliceType
+}
+
+// setFiles assigns files to a field for which isFileField reports true. A
+// *multipart.FileHeader field is assigned the first file, if any.
+func setFiles(field reflect.Value, files []*multipart.FileHeader) {
+	if len(files) == 0 {
 		return
 	}
+	if field.Type() == fileHeaderType {
+		field.Set(reflect.ValueOf(files[0]))
+		return
+	}
+	field.Set(reflect.ValueOf(files))
+}
+
+func textUnmarshaler(field reflect.Value) encoding.TextUnmarshaler {
+	impl := implementationsOf(field.Type())
+	return interfaceFrom[encoding.TextUnmarshaler](field, impl.text, impl.textPtr)
+}
+
+func textUnmarshalerContext(field reflect.Value) TextUnmarshalerContext {
+	impl := implementationsOf(field.Type())
+	return interfaceFrom[TextUnmarshalerContext](field, impl.textContext, impl.textContextPtr)
+}
 
-	var ok bool
-	fn(field.Interface(), &ok)
-	if !ok && field.CanAddr() {
-		fn(field.Addr().Interface(), &ok)
+func binaryUnmarshaler(field reflect.Value) encoding.BinaryUnmarshaler {
+	impl := implementationsOf(field.Type())
+	return interfaceFrom[encoding.BinaryUnmarshaler](field, impl.binary, impl.binaryPtr)
+}
+
+// implementations records which unmarshaler interfaces a type, and a pointer
+// to the type, implements.
+type implementations struct {
+	text, textPtr               bool
+	textContext, textContextPtr bool
+	binary, binaryPtr           bool
+	operandsPtr                 bool
+}
+
+var (
+	textUnmarshalerType        = reflect.TypeFor[encoding.TextUnmarshaler]()
+	textUnmarshalerContextType = reflect.TypeFor[TextUnmarshalerContext]()
+	binaryUnmarshalerType      = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+	operandDecoderType         = reflect.TypeFor[operandDecoder]()
+
+	// Cache of implementations by reflect.Type, since checking whether a type
+	// implements an interface is costly.
+	implementationsCache sync.Map
+)
+
+func implementationsOf(typ reflect.Type) implementations {
+	if impl, ok := implementationsCache.Load(typ); ok {
+		return impl.(implementations)
+	}
+
+	ptr := reflect.PointerTo(typ)
+	impl := implementations{
+		text:           typ.Implements(textUnmarshalerType),
+		textPtr:        ptr.Implements(textUnmarshalerType),
+		textContext:    typ.Implements(textUnmarshalerContextType),
+		textContextPtr: ptr.Implements(textUnmarshalerContextType),
+		binary:         typ.Implements(binaryUnmarshalerType),
+		binaryPtr:      ptr.Implements(binaryUnmarshalerType),
+		operandsPtr:    ptr.Implements(operandDecoderType),
+	}
+	implementationsCache.Store(typ, impl)
+	return impl
+}
+
+// interfaceFrom returns field as the interface type T if implemented is true,
+// or a pointer to field if ptrImplemented is true and field is addressable.
+// Checking the type of field before converting it to an interface value means
+// that fields not implementing T are never boxed.
+func interfaceFrom[T any](field reflect.Value, implemented, ptrImplemented bool) T {
+	var zero T
+	if !field.CanInterface() {
+		return zero
+	}
+
+	if field.Kind() == reflect.Interface || implemented {
+		if t, ok := field.Interface().(T); ok {
+			return t
+		}
+	}
+	if ptrImplemented && field.CanAddr() {
+		if t, ok := field.Addr().Interface().(T); ok {
+			return t
+		}
 	}
+	return zero
 }
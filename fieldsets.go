@@ -0,0 +1,95 @@
+package urlvalues
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Fieldsets holds the fields requested per type of resource, as in the sparse
+// fieldsets of JSON:API. A Fieldsets field keyed "fields" is decoded from
+// keys of the form "fields[type]" with comma-separated fields as values, such
+// as in "?fields[articles]=title,body&fields[people]=name".
+//
+// The key of a Fieldsets field is composed with the keys of the structs it is
+// nested in like the key of any other field. Only keys of the values, and of
+// the query and form sources of requests, are decoded into Fieldsets fields.
+type Fieldsets map[string][]string
+
+var fieldsetsType = reflect.TypeFor[Fieldsets]()
+
+// Has reports whether field of resources of type typ is requested. All fields
+// of a type are requested if no fieldset is given for the type.
+func (fs Fieldsets) Has(typ, field string) bool {
+	fields, ok := fs[typ]
+	return !ok || slices.Contains(fields, field)
+}
+
+// Fields returns the fields requested for resources of type typ, and whether
+// a fieldset is given for the type at all.
+func (fs Fieldsets) Fields(typ string) ([]string, bool) {
+	fields, ok := fs[typ]
+	return fields, ok
+}
+
+// fieldsetTypes returns the types of the fieldset keys among keys, keyed by
+// key, such as "articles" for "fields[articles]" given the key "fields".
+func fieldsetTypes(keys []string, key string) map[string]string {
+	types := make(map[string]string)
+	for _, k := range keys {
+		rest, ok := strings.CutPrefix(k, key+"[")
+		if !ok {
+			continue
+		}
+		typ, ok := strings.CutSuffix(rest, "]")
+		if !ok || typ == "" || strings.ContainsAny(typ, "[]") {
+			continue
+		}
+		types[k] = typ
+	}
+	return types
+}
+
+// decodeFieldsets decodes field, a Fieldsets field, from the keys of in
+// composed with the key of field. The field is left untouched if none of the
+// keys are present.
+func decodeFieldsets(in input, field field, pOpts *ParseOptions) error {
+	lookup := in.lookup(field.options.source)
+	if lookup == nil {
+		return nil
+	}
+	types := fieldsetTypes(in.keys(field.options.source), field.fullKey(field.key(), pOpts))
+	if len(types) == 0 {
+		return nil
+	}
+
+	fs := make(Fieldsets, len(types))
+	for key, typ := range types {
+		fields := []string{}
+		for _, value := range lookup(key) {
+			for _, f := range strings.Split(value, ",") {
+				if f = strings.TrimSpace(f); f != "" && !slices.Contains(fields, f) {
+					fields = append(fields, f)
+				}
+			}
+		}
+		fs[typ] = fields
+	}
+
+	field.field.Set(reflect.ValueOf(fs))
+	field.allocate(true)
+	return nil
+}
+
+// encodeFieldsets encodes field, a Fieldsets field, into dst, replacing the
+// values of all fieldset keys composed with the key of field.
+func encodeFieldsets(dst url.Values, field field, pOpts *ParseOptions) {
+	key := field.fullKey(field.key(), pOpts)
+	for k := range fieldsetTypes(keysOf(dst), key) {
+		delete(dst, k)
+	}
+	for typ, fields := range field.field.Interface().(Fieldsets) {
+		dst[key+"["+typ+"]"] = []string{strings.Join(fields, ",")}
+	}
+}
@@ -0,0 +1,83 @@
+package urlvalues_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestFieldsets(t *testing.T) {
+	type Target struct {
+		Fields urlvalues.Fieldsets `urlvalue:"fields"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want urlvalues.Fieldsets
+	}{
+		{"absent", url.Values{"other[articles]": {"title"}}, nil},
+		{
+			name: "fieldsets",
+			in:   url.Values{"fields[articles]": {"title, body,title"}, "fields[people]": {"name"}, "fields": {"x"}},
+			want: urlvalues.Fieldsets{"articles": {"title", "body"}, "people": {"name"}},
+		},
+		{"empty fieldset", url.Values{"fields[articles]": {""}}, urlvalues.Fieldsets{"articles": {}}},
+		{"repeated key", url.Values{"fields[articles]": {"title", "body"}}, urlvalues.Fieldsets{"articles": {"title", "body"}}},
+		{"invalid type", url.Values{"fields[]": {"title"}, "fields[a][b]": {"title"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			if err := urlvalues.Unmarshal(tt.in, &target); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+			}
+			if diff := cmp.Diff(target.Fields, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("has", func(t *testing.T) {
+		fs := urlvalues.Fieldsets{"articles": {"title"}}
+		for _, tt := range []struct {
+			typ, field string
+			want       bool
+		}{
+			{"articles", "title", true},
+			{"articles", "body", false},
+			{"people", "name", true},
+		} {
+			if got := fs.Has(tt.typ, tt.field); got != tt.want {
+				t.Errorf("Has(%q, %q) = %t, want %t", tt.typ, tt.field, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("request", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/?fields[articles]=title,body", nil)
+		var target Target
+		if err := urlvalues.UnmarshalRequest(r, &target); err != nil {
+			t.Fatalf("urlvalues.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &target, err)
+		}
+		want := urlvalues.Fieldsets{"articles": {"title", "body"}}
+		if diff := cmp.Diff(target.Fields, want); diff != "" {
+			t.Errorf("urlvalues.UnmarshalRequest(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		dst := url.Values{"fields[comments]": {"text"}, "q": {"go"}}
+		in := Target{Fields: urlvalues.Fieldsets{"articles": {"title", "body"}}}
+		if err := urlvalues.MarshalInto(dst, in); err != nil {
+			t.Fatalf("urlvalues.MarshalInto(%v, %v) = %q, want <nil>", dst, in, err)
+		}
+		want := url.Values{"fields[articles]": {"title,body"}, "q": {"go"}}
+		if diff := cmp.Diff(dst, want); diff != "" {
+			t.Errorf("urlvalues.MarshalInto(...) -got +want\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package urlvalues
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FilterOp is a comparison operator of a [Filter].
+type FilterOp string
+
+// Comparison operators of filters, given as prefixes of values, such as in
+// "gte:10".
+const (
+	OpEq  FilterOp = "eq"
+	OpNe  FilterOp = "ne"
+	OpGt  FilterOp = "gt"
+	OpGte FilterOp = "gte"
+	OpLt  FilterOp = "lt"
+	OpLte FilterOp = "lte"
+	OpIn  FilterOp = "in"
+)
+
+// isFilterOp reports whether op is a comparison operator of a Filter.
+func isFilterOp(op string) bool {
+	switch FilterOp(op) {
+	case OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn:
+		return true
+	}
+	return false
+}
+
+// Filter is a comparison against values of type T, decoded from a value
+// prefixed by a comparison operator and a colon (:), such as "gte:10" or
+// "lt:2024-01-01". Values without an operator are compared for equality. The
+// values of the "in" operator are separated by a vertical bar (|), such as in
+// "in:a|b|c".
+//
+// The values are decoded like values of fields of type T, following the
+// options of the filter field, such as the "layout" option of time.Time
+// values. Declare a slice of filters to decode several comparisons from
+// repeated keys, such as in "?price=gte:10&price=lt:20".
+type Filter[T any] struct {
+	// Op is the comparison operator, or empty if the filter was not decoded.
+	Op FilterOp
+	// Value compared against, unless Op is OpIn.
+	Value T
+	// Values compared against if Op is OpIn.
+	Values []T
+}
+
+func (f *Filter[T]) decodeOperands(value string, parse func(value string, dst reflect.Value) error) error {
+	op, operand, ok := strings.Cut(value, ":")
+	if !ok || !isFilterOp(op) {
+		op, operand = string(OpEq), value
+	}
+
+	filter := Filter[T]{Op: FilterOp(op)}
+	if filter.Op != OpIn {
+		if err := parse(operand, reflect.ValueOf(&filter.Value).Elem()); err != nil {
+			return err
+		}
+		*f = filter
+		return nil
+	}
+
+	if operand == "" {
+		return fmt.Errorf("operator %q missing values", op)
+	}
+	operands := strings.Split(operand, "|")
+	filter.Values = make([]T, len(operands))
+	for i, operand := range operands {
+		if err := parse(operand, reflect.ValueOf(&filter.Values[i]).Elem()); err != nil {
+			return err
+		}
+	}
+	*f = filter
+	return nil
+}
+
+func (f Filter[T]) encodeOperands(format func(v reflect.Value) (string, error)) (string, error) {
+	if f.Op != OpIn {
+		value, err := format(reflect.ValueOf(&f.Value).Elem())
+		if err != nil {
+			return "", err
+		}
+		if f.Op == "" || f.Op == OpEq {
+			// Values that look like they are prefixed by an operator keep
+			// the operator.
+			if op, _, ok := strings.Cut(value, ":"); !ok || !isFilterOp(op) {
+				return value, nil
+			}
+			return string(OpEq) + ":" + value, nil
+		}
+		return string(f.Op) + ":" + value, nil
+	}
+
+	values := make([]string, len(f.Values))
+	for i := range f.Values {
+		value, err := format(reflect.ValueOf(&f.Values[i]).Elem())
+		if err != nil {
+			return "", err
+		}
+		values[i] = value
+	}
+	return string(OpIn) + ":" + strings.Join(values, "|"), nil
+}
@@ -0,0 +1,86 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestFilter(t *testing.T) {
+	type Target struct {
+		Price   []urlvalues.Filter[int]     `urlvalue:"price"`
+		Created urlvalues.Filter[time.Time] `urlvalue:"created,layout:2006-01-02"`
+		Status  urlvalues.Filter[string]    `urlvalue:"status"`
+	}
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{
+			name: "operators",
+			in:   url.Values{"price": {"gte:10", "lt:20"}, "created": {"lt:2024-01-01"}, "status": {"ne:draft"}},
+			want: Target{
+				Price:   []urlvalues.Filter[int]{{Op: urlvalues.OpGte, Value: 10}, {Op: urlvalues.OpLt, Value: 20}},
+				Created: urlvalues.Filter[time.Time]{Op: urlvalues.OpLt, Value: date(2024, 1, 1)},
+				Status:  urlvalues.Filter[string]{Op: urlvalues.OpNe, Value: "draft"},
+			},
+		},
+		{
+			name: "in",
+			in:   url.Values{"status": {"in:a|b|c"}, "price": {"in:1|2"}},
+			want: Target{
+				Price:  []urlvalues.Filter[int]{{Op: urlvalues.OpIn, Values: []int{1, 2}}},
+				Status: urlvalues.Filter[string]{Op: urlvalues.OpIn, Values: []string{"a", "b", "c"}},
+			},
+		},
+		{
+			name: "no operator",
+			in:   url.Values{"status": {"a:b"}, "price": {"5"}},
+			want: Target{
+				Price:  []urlvalues.Filter[int]{{Op: urlvalues.OpEq, Value: 5}},
+				Status: urlvalues.Filter[string]{Op: urlvalues.OpEq, Value: "a:b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+
+			encoded, err := urlvalues.Marshal(got)
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", got, err)
+			}
+			var decoded Target
+			if err := urlvalues.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", encoded, &decoded, err)
+			}
+			if diff := cmp.Diff(decoded, tt.want); diff != "" {
+				t.Errorf("round trip -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []url.Values{{"price": {"gte:abc"}}, {"status": {"in:"}}, {"created": {"lt:01/02/2024"}}} {
+		t.Run("invalid", func(t *testing.T) {
+			var target Target
+			if err := urlvalues.Unmarshal(in, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package urlvalues
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// LatLng is a geographic point, decoded from its latitude and longitude in
+// degrees separated by a comma, such as "59.33,18.07". Latitudes must be
+// within [-90, 90] and longitudes within [-180, 180].
+//
+// Fields of other struct types with float fields named Lat and Lng are
+// decoded like LatLng fields if they have the "geopoint" tag option.
+type LatLng struct {
+	Lat, Lng float64
+}
+
+// String returns p as its latitude and longitude separated by a comma.
+func (p LatLng) String() string {
+	return formatCoords(p.Lat, p.Lng)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (p LatLng) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (p *LatLng) UnmarshalText(text []byte) error {
+	point, err := parseLatLng(string(text))
+	if err != nil {
+		return err
+	}
+	*p = point
+	return nil
+}
+
+// BBox is a geographic bounding box, decoded from the longitude and latitude
+// of its south-west corner followed by those of its north-east corner, in
+// degrees separated by commas, such as "18.0,59.3,18.1,59.4" for
+// "minLon,minLat,maxLon,maxLat". Latitudes must be within [-90, 90] and
+// longitudes within [-180, 180], and the minimum latitude may not be greater
+// than the maximum latitude.
+//
+// Like in GeoJSON, a minimum longitude greater than the maximum longitude
+// denotes a box crossing the antimeridian, such as "170,-10,-170,10".
+type BBox struct {
+	MinLon, MinLat, MaxLon, MaxLat float64
+}
+
+// CrossesAntimeridian reports whether b crosses the antimeridian, that is,
+// whether its minimum longitude is greater than its maximum longitude.
+func (b BBox) CrossesAntimeridian() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// Contains reports whether p is within b, inclusively.
+func (b BBox) Contains(p LatLng) bool {
+	if p.Lat < b.MinLat || p.Lat > b.MaxLat {
+		return false
+	}
+	if b.CrossesAntimeridian() {
+		return p.Lng >= b.MinLon || p.Lng <= b.MaxLon
+	}
+	return p.Lng >= b.MinLon && p.Lng <= b.MaxLon
+}
+
+// String returns b as its coordinates separated by commas.
+func (b BBox) String() string {
+	return formatCoords(b.MinLon, b.MinLat, b.MaxLon, b.MaxLat)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (b BBox) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (b *BBox) UnmarshalText(text []byte) error {
+	coords, err := parseCoords(string(text), 4)
+	if err != nil {
+		return err
+	}
+	box := BBox{MinLon: coords[0], MinLat: coords[1], MaxLon: coords[2], MaxLat: coords[3]}
+	for _, lng := range []float64{box.MinLon, box.MaxLon} {
+		if err := checkLng(lng); err != nil {
+			return err
+		}
+	}
+	for _, lat := range []float64{box.MinLat, box.MaxLat} {
+		if err := checkLat(lat); err != nil {
+			return err
+		}
+	}
+	if box.MinLat > box.MaxLat {
+		return fmt.Errorf("minimum latitude %s greater than maximum latitude %s", formatNumber(box.MinLat), formatNumber(box.MaxLat))
+	}
+	*b = box
+	return nil
+}
+
+func parseLatLng(s string) (LatLng, error) {
+	coords, err := parseCoords(s, 2)
+	if err != nil {
+		return LatLng{}, err
+	}
+	point := LatLng{Lat: coords[0], Lng: coords[1]}
+	if err := checkLat(point.Lat); err != nil {
+		return LatLng{}, err
+	}
+	if err := checkLng(point.Lng); err != nil {
+		return LatLng{}, err
+	}
+	return point, nil
+}
+
+// parseCoords parses n comma-separated coordinates of s.
+func parseCoords(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("got %d coordinates, want %d", len(parts), n)
+	}
+	coords := make([]float64, n)
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		coords[i] = f
+	}
+	return coords, nil
+}
+
+func formatCoords(coords ...float64) string {
+	parts := make([]string, len(coords))
+	for i, c := range coords {
+		parts[i] = strconv.FormatFloat(c, 'f', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+func checkLat(lat float64) error {
+	if lat < -90 || lat > 90 {
+		return &coordRangeError{msg: fmt.Sprintf("latitude %s not within [-90, 90]", formatNumber(lat))}
+	}
+	return nil
+}
+
+func checkLng(lng float64) error {
+	if lng < -180 || lng > 180 {
+		return &coordRangeError{msg: fmt.Sprintf("longitude %s not within [-180, 180]", formatNumber(lng))}
+	}
+	return nil
+}
+
+// coordRangeError occurs when a coordinate is out of range. It matches
+// strconv.ErrRange, so that it is classified as CodeOutOfRange.
+type coordRangeError struct {
+	msg string
+}
+
+func (err *coordRangeError) Error() string {
+	return err.msg
+}
+
+func (err *coordRangeError) Is(target error) bool {
+	return target == strconv.ErrRange
+}
+
+// isGeoPoint reports whether typ, or the element type of typ if it is a
+// pointer or slice, is a struct with float fields named Lat and Lng.
+func isGeoPoint(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	for _, name := range []string{"Lat", "Lng"} {
+		f, ok := typ.FieldByName(name)
+		if !ok || !f.IsExported() || (f.Type.Kind() != reflect.Float64 && f.Type.Kind() != reflect.Float32) {
+			return false
+		}
+	}
+	return true
+}
+
+// setGeoPoint decodes value into field, a struct with Lat and Lng fields.
+func setGeoPoint(field reflect.Value, value string) error {
+	point, err := parseLatLng(value)
+	if err != nil {
+		return err
+	}
+	field.FieldByName("Lat").SetFloat(point.Lat)
+	field.FieldByName("Lng").SetFloat(point.Lng)
+	return nil
+}
+
+// formatGeoPoint formats v, a struct with Lat and Lng fields, the inverse of
+// setGeoPoint.
+func formatGeoPoint(v reflect.Value) string {
+	return formatCoords(v.FieldByName("Lat").Float(), v.FieldByName("Lng").Float())
+}
@@ -0,0 +1,141 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestLatLng(t *testing.T) {
+	type Point struct {
+		Lat, Lng float64
+	}
+	type Target struct {
+		Near  urlvalues.LatLng   `urlvalue:"near"`
+		Point *Point             `urlvalue:"point,geopoint"`
+		Stops []urlvalues.LatLng `urlvalue:"stops"`
+	}
+
+	in := url.Values{"near": {"59.33,18.07"}, "point": {"-33.9, 151.2"}, "stops": {"1,2", "3,4"}}
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	want := Target{
+		Near:  urlvalues.LatLng{Lat: 59.33, Lng: 18.07},
+		Point: &Point{Lat: -33.9, Lng: 151.2},
+		Stops: []urlvalues.LatLng{{Lat: 1, Lng: 2}, {Lat: 3, Lng: 4}},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("marshal", func(t *testing.T) {
+		got, err := urlvalues.Marshal(want)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", want, err)
+		}
+		want := url.Values{"near": {"59.33,18.07"}, "point": {"-33.9,151.2"}, "stops": {"1,2", "3,4"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		in       url.Values
+		wantCode urlvalues.ErrorCode
+	}{
+		{"latitude out of range", url.Values{"near": {"91,0"}}, urlvalues.CodeOutOfRange},
+		{"longitude out of range", url.Values{"point": {"0,-181"}}, urlvalues.CodeOutOfRange},
+		{"one coordinate", url.Values{"near": {"59.33"}}, urlvalues.CodeInvalid},
+		{"not a number", url.Values{"near": {"a,b"}}, urlvalues.CodeInvalidSyntax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target)
+			var parseErr *urlvalues.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", tt.in, &target, err)
+			}
+			if got := parseErr.Code(); got != tt.wantCode {
+				t.Errorf("Code() = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Point struct{ X, Y float64 } `urlvalue:"point,geopoint"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestBBox(t *testing.T) {
+	type Target struct {
+		BBox urlvalues.BBox `urlvalue:"bbox"`
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want urlvalues.BBox
+	}{
+		{"box", "18.0,59.3,18.1,59.4", urlvalues.BBox{MinLon: 18.0, MinLat: 59.3, MaxLon: 18.1, MaxLat: 59.4}},
+		{"antimeridian", "170,-10,-170,10", urlvalues.BBox{MinLon: 170, MinLat: -10, MaxLon: -170, MaxLat: 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := url.Values{"bbox": {tt.in}}
+			var got Target
+			if err := urlvalues.Unmarshal(in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+			}
+			if diff := cmp.Diff(got.BBox, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []string{"1,2,3", "0,91,1,92", "-181,0,0,1", "0,10,1,5", "a,b,c,d"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			values := url.Values{"bbox": {in}}
+			var target Target
+			if err := urlvalues.Unmarshal(values, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", values, &target)
+			}
+		})
+	}
+
+	t.Run("string", func(t *testing.T) {
+		box := urlvalues.BBox{MinLon: 18, MinLat: 59.3, MaxLon: 18.1, MaxLat: 59.4}
+		if got, want := box.String(), "18,59.3,18.1,59.4"; got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("contains", func(t *testing.T) {
+		box := urlvalues.BBox{MinLon: 170, MinLat: -10, MaxLon: -170, MaxLat: 10}
+		for _, tt := range []struct {
+			p    urlvalues.LatLng
+			want bool
+		}{
+			{urlvalues.LatLng{Lat: 0, Lng: 175}, true},
+			{urlvalues.LatLng{Lat: 0, Lng: -175}, true},
+			{urlvalues.LatLng{Lat: 0, Lng: 0}, false},
+			{urlvalues.LatLng{Lat: 20, Lng: 175}, false},
+		} {
+			if got := box.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %t, want %t", tt.p, got, tt.want)
+			}
+		}
+	})
+}
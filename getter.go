@@ -0,0 +1,85 @@
+package urlvalues
+
+import (
+	"flag"
+	"slices"
+)
+
+// ValueGetter is a source of values keyed by strings, such as a set of command
+// line flags, a map of environment variables or the parameters matched by a
+// router. Implement it with a thin adapter to decode such sources using
+// [UnmarshalFrom].
+type ValueGetter interface {
+	// Get returns the values of key, and whether key is present.
+	Get(key string) ([]string, bool)
+	// Keys returns the keys present, in any order.
+	Keys() []string
+}
+
+// UnmarshalFrom unmarshals the values of g into the value pointed to by v like
+// [Unmarshal] unmarshals URL values. Keys are looked up in g as is. Sources
+// holding single values per key can be adapted by [StringMap]:
+//
+//	env := urlvalues.StringMap{"PORT": "8080", "DEBUG": "true"}
+//	err := urlvalues.UnmarshalFrom(env, &cfg)
+//
+// See [Unmarshal] for details on how the values are decoded.
+func UnmarshalFrom(g ValueGetter, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	defer pOpts.observe(v)(&err)
+
+	return unmarshalInput(input{values: getterLookup(g), valueKeys: getterKeys(g)}, v, pOpts)
+}
+
+// getterLookup returns a lookup of the values in g.
+func getterLookup(g ValueGetter) lookup {
+	return func(key string) []string {
+		if values, ok := g.Get(key); ok {
+			return values
+		}
+		return nil
+	}
+}
+
+// getterKeys returns a keyLister of the keys in g.
+func getterKeys(g ValueGetter) keyLister {
+	return func() []string {
+		keys := slices.Clone(g.Keys())
+		slices.Sort(keys)
+		return keys
+	}
+}
+
+// StringMap is a [ValueGetter] of a single value per key, such as the
+// environment variables of a process, the fields of a Redis hash or the
+// parameters matched by a router.
+type StringMap map[string]string
+
+// Get returns the value of key as the only value, and whether key is present.
+func (m StringMap) Get(key string) ([]string, bool) {
+	value, ok := m[key]
+	if !ok {
+		return nil, false
+	}
+	return []string{value}, true
+}
+
+// Keys returns the keys of m.
+func (m StringMap) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FlagValues returns a [StringMap] of the flags of fs that have been set,
+// keyed by their names, such that flags left unset are absent rather than
+// holding their default values. Call it after fs is parsed.
+func FlagValues(fs *flag.FlagSet) StringMap {
+	m := make(StringMap)
+	fs.Visit(func(f *flag.Flag) {
+		m[f.Name] = f.Value.String()
+	})
+	return m
+}
@@ -0,0 +1,99 @@
+package urlvalues_test
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+// listGetter is a ValueGetter holding multiple values per key.
+type listGetter map[string][]string
+
+func (g listGetter) Get(key string) ([]string, bool) {
+	values, ok := g[key]
+	return values, ok
+}
+
+func (g listGetter) Keys() []string {
+	var keys []string
+	for key := range g {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestUnmarshalFrom(t *testing.T) {
+	type Target struct {
+		Port    int           `urlvalue:"PORT,default:80"`
+		Debug   bool          `urlvalue:"DEBUG"`
+		Timeout time.Duration `urlvalue:"TIMEOUT,default:5s"`
+		Hosts   []string      `urlvalue:"HOSTS"`
+	}
+
+	tests := []struct {
+		name   string
+		getter urlvalues.ValueGetter
+		want   Target
+	}{
+		{
+			"string map",
+			urlvalues.StringMap{"PORT": "8080", "DEBUG": "true", "HOSTS": "a;b"},
+			Target{Port: 8080, Debug: true, Timeout: 5 * time.Second, Hosts: []string{"a", "b"}},
+		},
+		{
+			"multiple values",
+			listGetter{"HOSTS": {"a", "b"}, "TIMEOUT": {"1m"}},
+			Target{Port: 80, Timeout: time.Minute, Hosts: []string{"a", "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.UnmarshalFrom(tt.getter, &got); err != nil {
+				t.Fatalf("urlvalues.UnmarshalFrom(%v, %v) = %q, want <nil>", tt.getter, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.UnmarshalFrom(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("dynamic map", func(t *testing.T) {
+		in := urlvalues.StringMap{"a": "1", "b": "x"}
+		got := map[string]any{}
+		if err := urlvalues.UnmarshalFrom(in, &got); err != nil {
+			t.Fatalf("urlvalues.UnmarshalFrom(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if len(got) != 2 {
+			t.Errorf("urlvalues.UnmarshalFrom(...) = %v, want 2 keys", got)
+		}
+	})
+}
+
+func TestFlagValues(t *testing.T) {
+	type Target struct {
+		Port  int    `urlvalue:"port,default:80"`
+		Name  string `urlvalue:"name,default:app"`
+		Debug bool   `urlvalue:"debug"`
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 1, "")
+	fs.String("name", "flag-default", "")
+	fs.Bool("debug", false, "")
+	if err := fs.Parse([]string{"-port=8080", "-debug"}); err != nil {
+		t.Fatalf("FlagSet.Parse(...) = %q, want <nil>", err)
+	}
+
+	var got Target
+	if err := urlvalues.UnmarshalFrom(urlvalues.FlagValues(fs), &got); err != nil {
+		t.Fatalf("urlvalues.UnmarshalFrom(...) = %q, want <nil>", err)
+	}
+	// Unset flags are absent, so the default of the field applies.
+	if diff := cmp.Diff(got, Target{Port: 8080, Name: "app", Debug: true}); diff != "" {
+		t.Errorf("urlvalues.UnmarshalFrom(...) -got +want\n%s", diff)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/nahojer/urlvalues
 
-go 1.19
+go 1.22
 
 require github.com/google/go-cmp v0.5.9
@@ -0,0 +1,152 @@
+package urlvalues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Handler returns an [http.HandlerFunc] that decodes each request into a
+// value of type T using [UnmarshalRequest] and passes it on to fn. T must be a
+// struct type.
+//
+// If the request fails to be decoded, fn is not called and the error is written
+// using [WriteError], or the [ErrorWriterFunc] set by passing the
+// [WithErrorWriter] [SetParseOptionFunc].
+func Handler[T any](fn func(http.ResponseWriter, *http.Request, T), setParseOpts ...SetParseOptionFunc) http.HandlerFunc {
+	var zero T
+	writeErr := newParseOptionsFor(&zero, setParseOpts).ErrorWriter()
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params T
+		if err := UnmarshalRequest(r, &params, setParseOpts...); err != nil {
+			writeErr(w, err)
+			return
+		}
+		fn(w, r, params)
+	}
+}
+
+// Middleware returns HTTP middleware that decodes each request into a value of
+// type T using [UnmarshalRequest] and stores it in the request context, from
+// where it can be retrieved using [FromContext]. T must be a struct type.
+//
+// Errors are handled like in [Handler], in which case the next handler is not
+// called.
+func Middleware[T any](setParseOpts ...SetParseOptionFunc) func(http.Handler) http.Handler {
+	var zero T
+	writeErr := newParseOptionsFor(&zero, setParseOpts).ErrorWriter()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var params T
+			if err := UnmarshalRequest(r, &params, setParseOpts...); err != nil {
+				writeErr(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), params)))
+		})
+	}
+}
+
+// contextKey is the key of values of type T stored in a context. Being
+// generic, each type T gets a key of its own.
+type contextKey[T any] struct{}
+
+// NewContext returns a copy of ctx carrying v, which can be retrieved using
+// [FromContext].
+func NewContext[T any](ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, contextKey[T]{}, v)
+}
+
+// FromContext returns the value of type T stored in ctx by [Middleware] or
+// [NewContext], and whether such a value was found.
+func FromContext[T any](ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(contextKey[T]{}).(T)
+	return v, ok
+}
+
+// ErrorWriterFunc writes the response to a request that failed to be decoded
+// with err. Custom implementations should use [StatusCode] to keep the status
+// codes consistent.
+type ErrorWriterFunc func(w http.ResponseWriter, err error)
+
+// StatusCode returns the HTTP status code that err maps to. Errors caused by
+// the client, such as a [ParseError] or an [ErrInvalidForm] error, map to 400
+// Bad Request, except for request bodies exceeding the size set by
+// [WithMaxBodySize], which map to 413 Request Entity Too Large. All other
+// errors, such as invalid struct tags, invalid default values or a target that
+// is not a struct pointer, are programming errors and map to 500 Internal
+// Server Error. Errors aggregating several errors, such as [Errors], map to
+// the most severe status of their errors, so that a programming error is never
+// hidden by client errors reported along with it.
+func StatusCode(err error) int {
+	switch x := err.(type) {
+	case *http.MaxBytesError:
+		return http.StatusRequestEntityTooLarge
+	case *ParseError, *TooManyErrorsError:
+		return http.StatusBadRequest
+	case interface{ Unwrap() []error }:
+		errs := x.Unwrap()
+		// An invalid form is wrapped along with its cause.
+		for _, e := range errs {
+			if e == ErrInvalidForm {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return http.StatusRequestEntityTooLarge
+				}
+				return http.StatusBadRequest
+			}
+		}
+		status := 0
+		for _, e := range errs {
+			status = max(status, StatusCode(e))
+		}
+		if status == 0 {
+			return http.StatusInternalServerError
+		}
+		return status
+	}
+	if err == ErrInvalidForm {
+		return http.StatusBadRequest
+	}
+	if next := errors.Unwrap(err); next != nil {
+		return StatusCode(next)
+	}
+	return http.StatusInternalServerError
+}
+
+// errorResponse is the JSON body written by WriteError.
+type errorResponse struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+	Key   string `json:"key,omitempty"`
+}
+
+// WriteError responds with the status code returned by [StatusCode] and a JSON
+// body describing err. The body of client errors contains the error message,
+// and for a [ParseError] also its field name and key. The body of internal
+// errors only contains the status text, so that no internals are revealed.
+//
+// WriteError is the default [ErrorWriterFunc] of [Handler] and [Middleware].
+// Use the [WithErrorWriter] [SetParseOptionFunc] to customize the response,
+// for example to [WriteProblem].
+func WriteError(w http.ResponseWriter, err error) {
+	status := StatusCode(err)
+
+	resp := errorResponse{Error: http.StatusText(status)}
+	if status == http.StatusBadRequest {
+		resp.Error = err.Error()
+	}
+	var parseErr *ParseError
+	if status == http.StatusBadRequest && errors.As(err, &parseErr) {
+		resp = errorResponse{
+			Error: parseErr.Error(),
+			Field: parseErr.FieldName,
+			Key:   parseErr.Key,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
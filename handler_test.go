@@ -0,0 +1,188 @@
+package urlvalues_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestHandler(t *testing.T) {
+	type Params struct {
+		Page int `urlvalue:"page,default:1"`
+	}
+
+	var (
+		called bool
+		got    Params
+	)
+	h := urlvalues.Handler(func(w http.ResponseWriter, r *http.Request, p Params) {
+		called = true
+		got = p
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		called = false
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/?page=3", nil))
+
+		if !called {
+			t.Fatal("handler not called")
+		}
+		if diff := cmp.Diff(got, Params{Page: 3}); diff != "" {
+			t.Errorf("handler params -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		called = false
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(http.MethodGet, "/?page=last", nil))
+
+		if called {
+			t.Error("handler called, want not called")
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+
+		var body struct {
+			Error string `json:"error"`
+			Field string `json:"field"`
+			Key   string `json:"key"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Field != "Page" || body.Key != "page" || body.Error == "" {
+			t.Errorf("body = %+v, want field Page, key page and an error message", body)
+		}
+	})
+}
+
+func TestHandler_InvalidTarget(t *testing.T) {
+	h := urlvalues.Handler(func(w http.ResponseWriter, r *http.Request, p int) {
+		t.Error("handler called, want not called")
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	type Params struct {
+		Page int `urlvalue:"page,default:1"`
+	}
+
+	var (
+		got   Params
+		gotOK bool
+	)
+	h := urlvalues.Middleware[Params]()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, gotOK = urlvalues.FromContext[Params](r.Context())
+	}))
+
+	t.Run("valid", func(t *testing.T) {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/?page=3", nil))
+
+		if !gotOK {
+			t.Fatal("urlvalues.FromContext[Params](...) reported no value")
+		}
+		if diff := cmp.Diff(got, Params{Page: 3}); diff != "" {
+			t.Errorf("urlvalues.FromContext[Params](...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		gotOK = false
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?page=last", nil))
+
+		if gotOK {
+			t.Error("next handler called, want not called")
+		}
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestFromContext_Missing(t *testing.T) {
+	type Params struct{ Page int }
+
+	ctx := urlvalues.NewContext(context.Background(), 42)
+	if got, ok := urlvalues.FromContext[Params](ctx); ok {
+		t.Errorf("urlvalues.FromContext[Params](...) = %v, true, want false", got)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	var target struct {
+		Page int `urlvalue:"page"`
+	}
+	parseErr := urlvalues.Unmarshal(url.Values{"page": {"last"}}, &target)
+
+	formReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("%zz"))
+	formReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	formErr := urlvalues.UnmarshalRequest(formReq, &target)
+
+	largeReq := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("page="+strings.Repeat("1", 64)))
+	largeReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	largeErr := urlvalues.UnmarshalRequest(largeReq, &target, urlvalues.WithMaxBodySize(16))
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"parse error", parseErr, http.StatusBadRequest},
+		{"invalid form", formErr, http.StatusBadRequest},
+		{"body too large", largeErr, http.StatusRequestEntityTooLarge},
+		{"invalid struct", urlvalues.ErrInvalidStruct, http.StatusInternalServerError},
+		{"wrapped parse error", fmt.Errorf("decoding: %w", parseErr), http.StatusBadRequest},
+		{"parse errors", urlvalues.Errors{parseErr, parseErr}, http.StatusBadRequest},
+		{"parse error and internal error", urlvalues.Errors{parseErr, urlvalues.ErrInvalidStruct}, http.StatusInternalServerError},
+		{"joined parse error and internal error", errors.Join(parseErr, errors.New("oops")), http.StatusInternalServerError},
+		{"parse error and body too large", urlvalues.Errors{parseErr, largeErr}, http.StatusRequestEntityTooLarge},
+		{"other", errors.New("oops"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := urlvalues.StatusCode(tt.err); got != tt.want {
+				t.Errorf("urlvalues.StatusCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandler_WithErrorWriter(t *testing.T) {
+	type Params struct {
+		Page int `urlvalue:"page"`
+	}
+
+	h := urlvalues.Handler(func(w http.ResponseWriter, r *http.Request, p Params) {
+		t.Error("handler called, want not called")
+	}, urlvalues.WithErrorWriter(urlvalues.WriteProblem))
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/?page=last", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "application/problem+json"; got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+package urlvalues
+
+import (
+	"net/http"
+	"net/textproto"
+	"net/url"
+)
+
+// UnmarshalHeader unmarshals the HTTP header h into the value pointed to by v.
+//
+// Keys are matched case-insensitively by canonicalizing both the keys of h and
+// the keys of struct fields using [textproto.CanonicalMIMEHeaderKey]. For
+// example, a field tagged `urlvalue:"x-per-page"` is decoded from the
+// X-Per-Page header. Fields with the "source:header" option are decoded from
+// h as well.
+//
+// See [Unmarshal] for details on how the header values are decoded.
+func UnmarshalHeader(h http.Header, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	defer pOpts.observe(v)(&err)
+
+	data := headerValues(h)
+	values, keys := headerLookup(data), valuesKeys(data)
+	in := input{
+		values:       values,
+		sources:      map[string]lookup{sourceHeader: values},
+		valueKeys:    keys,
+		sourceKeys:   map[string]keyLister{sourceHeader: keys},
+		canonicalKey: textproto.CanonicalMIMEHeaderKey,
+	}
+	return unmarshalInput(in, v, pOpts)
+}
+
+// headerValues returns the values of h keyed by their canonical header key.
+func headerValues(h http.Header) url.Values {
+	data := make(url.Values, len(h))
+	for key, values := range h {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		data[key] = append(data[key], values...)
+	}
+	return data
+}
+
+// headerLookup returns a lookup of the values in h, canonicalizing keys before
+// looking them up. The keys of h must already be canonical.
+func headerLookup(h url.Values) lookup {
+	return func(key string) []string {
+		return h[textproto.CanonicalMIMEHeaderKey(key)]
+	}
+}
@@ -0,0 +1,79 @@
+package urlvalues_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshalHeader(t *testing.T) {
+	type Target struct {
+		PerPage int      `urlvalue:"x-per-page,default:20"`
+		Cursor  string   `urlvalue:"X-Cursor"`
+		Accept  []string `urlvalue:"accept"`
+		Token   string   `urlvalue:"x-token,default:anonymous"`
+	}
+
+	in := http.Header{
+		"X-Per-Page": {"50"},
+		"x-cursor":   {"abc"},
+	}
+	in.Add("Accept", "text/html")
+	in.Add("Accept", "application/json")
+	want := Target{
+		PerPage: 50,
+		Cursor:  "abc",
+		Accept:  []string{"text/html", "application/json"},
+		Token:   "anonymous",
+	}
+
+	var got Target
+	if err := urlvalues.UnmarshalHeader(in, &got); err != nil {
+		t.Fatalf("urlvalues.UnmarshalHeader(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.UnmarshalHeader(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalHeader_Sources(t *testing.T) {
+	type Target struct {
+		Token   string     `urlvalue:"x-token,source:header"`
+		Forward url.Values `urlvalue:"x-forward-,passthrough"`
+		Page    int        `urlvalue:"page"`
+	}
+
+	in := http.Header{
+		"X-Token":         {"secret"},
+		"X-Forward-For":   {"10.0.0.1"},
+		"x-forward-trace": {"abc"},
+		"X-Request-Id":    {"42"},
+	}
+	want := Target{
+		Token:   "secret",
+		Forward: url.Values{"X-Forward-For": {"10.0.0.1"}, "X-Forward-Trace": {"abc"}},
+	}
+
+	var events []urlvalues.TraceEvent
+	var got Target
+	err := urlvalues.UnmarshalHeader(in, &got, urlvalues.WithTraceFunc(func(e urlvalues.TraceEvent) {
+		if e.Kind == urlvalues.TraceUnused {
+			events = append(events, e)
+		}
+	}))
+	if err != nil {
+		t.Fatalf("urlvalues.UnmarshalHeader(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.UnmarshalHeader(...) -got +want\n%s", diff)
+	}
+	wantEvents := []urlvalues.TraceEvent{{Kind: urlvalues.TraceUnused, Key: "X-Request-Id"}}
+	if diff := cmp.Diff(events, wantEvents); diff != "" {
+		t.Errorf("unused trace events -got +want\n%s", diff)
+	}
+}
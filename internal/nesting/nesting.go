@@ -0,0 +1,8 @@
+// Package nesting holds the nesting limit shared by the parsers of filter
+// expressions, such that deeply nested input fails to parse instead of
+// exhausting the stack.
+package nesting
+
+// MaxDepth is the maximum depth of nested groups, such as parenthesized
+// expressions, accepted by the parsers.
+const MaxDepth = 100
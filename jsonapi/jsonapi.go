@@ -0,0 +1,115 @@
+// Package jsonapi decodes the query parameters of JSON:API requests using
+// [urlvalues.Unmarshal], so that servers get spec-compliant parsing of
+// inclusion of related resources, sorting, pagination, filtering and sparse
+// fieldsets for free.
+//
+// See https://jsonapi.org/format/#fetching.
+package jsonapi
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/nahojer/urlvalues"
+)
+
+// Query holds the query parameters of a JSON:API request, such as
+// "?include=author&sort=-created&page[number]=2&filter[tag]=go&fields[articles]=title".
+//
+// Query declares the [urlvalues.BracketKeys] key style by implementing
+// [urlvalues.OptionsProvider]. Decode it by [Unmarshal] or [UnmarshalRequest],
+// which decode filters as well.
+type Query struct {
+	// Relationship paths of related resources to include.
+	Include Include `urlvalue:"include"`
+	// Fields to sort by.
+	Sort urlvalues.Sort `urlvalue:"sort"`
+	// Page of results.
+	Page Page `urlvalue:"page"`
+	// Filters keyed by the names inside the brackets of "filter[name]" keys.
+	// The meaning of filters is up to the server.
+	Filter map[string][]string `urlvalue:"-"`
+	// Sparse fieldsets keyed by resource type.
+	Fields urlvalues.Fieldsets `urlvalue:"fields"`
+}
+
+// URLValuesOptions implements [urlvalues.OptionsProvider].
+func (Query) URLValuesOptions() []urlvalues.SetParseOptionFunc {
+	return []urlvalues.SetParseOptionFunc{urlvalues.WithKeyStyle(urlvalues.BracketKeys)}
+}
+
+// Page holds the "page[number]" and "page[size]" parameters of a Query. Page
+// numbers start at 1 and default to 1, while the page size defaults to 20 and
+// is at most 100.
+type Page struct {
+	Number int `urlvalue:"number,default:1,min:1"`
+	Size   int `urlvalue:"size,default:20,min:1,max:100"`
+}
+
+// Offset returns the number of resources before the page.
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Size
+}
+
+// Include holds the comma-separated relationship paths of the "include"
+// parameter, such as "author,comments.author".
+type Include []string
+
+// Has reports whether path is included, either by itself or as part of a
+// longer path, such as "comments" as part of "comments.author".
+func (inc Include) Has(path string) bool {
+	return slices.ContainsFunc(inc, func(p string) bool {
+		return p == path || strings.HasPrefix(p, path+".")
+	})
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (inc Include) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(inc, ",")), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (inc *Include) UnmarshalText(text []byte) error {
+	paths := Include{}
+	for _, path := range strings.Split(string(text), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	*inc = paths
+	return nil
+}
+
+// Unmarshal decodes data into q using [urlvalues.Unmarshal], and the values
+// of "filter[name]" keys into q.Filter.
+func Unmarshal(data url.Values, q *Query, setParseOpts ...urlvalues.SetParseOptionFunc) error {
+	if err := urlvalues.Unmarshal(data, q, setParseOpts...); err != nil {
+		return err
+	}
+
+	for key, values := range data {
+		rest, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+		name, ok := strings.CutSuffix(rest, "]")
+		if !ok || name == "" || strings.ContainsAny(name, "[]") {
+			continue
+		}
+		if q.Filter == nil {
+			q.Filter = make(map[string][]string)
+		}
+		q.Filter[name] = values
+	}
+
+	return nil
+}
+
+// UnmarshalRequest decodes the URL query of r into q like [Unmarshal]. The
+// body of r is not decoded, since the bodies of JSON:API requests are JSON
+// documents rather than forms.
+func UnmarshalRequest(r *http.Request, q *Query, setParseOpts ...urlvalues.SetParseOptionFunc) error {
+	return Unmarshal(r.URL.Query(), q, setParseOpts...)
+}
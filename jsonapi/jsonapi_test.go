@@ -0,0 +1,71 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+	"github.com/nahojer/urlvalues/jsonapi"
+)
+
+func TestUnmarshalRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		want   jsonapi.Query
+	}{
+		{
+			name:   "defaults",
+			target: "/articles",
+			want:   jsonapi.Query{Page: jsonapi.Page{Number: 1, Size: 20}},
+		},
+		{
+			name:   "all parameters",
+			target: "/articles?include=author,comments.author&sort=-created,title&page[number]=2&page[size]=10&filter[tag]=go&filter[tag]=rust&fields[articles]=title,body&fields[people]=name",
+			want: jsonapi.Query{
+				Include: jsonapi.Include{"author", "comments.author"},
+				Sort:    urlvalues.Sort{{Field: "created", Descending: true}, {Field: "title"}},
+				Page:    jsonapi.Page{Number: 2, Size: 10},
+				Filter:  map[string][]string{"tag": {"go", "rust"}},
+				Fields:  urlvalues.Fieldsets{"articles": {"title", "body"}, "people": {"name"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.target, nil)
+			var got jsonapi.Query
+			if err := jsonapi.UnmarshalRequest(r, &got); err != nil {
+				t.Fatalf("jsonapi.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("jsonapi.UnmarshalRequest(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("page out of bounds", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/articles?page[size]=1000", nil)
+		var q jsonapi.Query
+		err := jsonapi.UnmarshalRequest(r, &q)
+
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("jsonapi.UnmarshalRequest(%v, %v) = %v, want *urlvalues.ParseError", r, &q, err)
+		}
+		if parseErr.Key != "page[size]" {
+			t.Errorf("Key = %q, want %q", parseErr.Key, "page[size]")
+		}
+	})
+}
+
+func TestInclude_Has(t *testing.T) {
+	inc := jsonapi.Include{"author", "comments.author"}
+	for path, want := range map[string]bool{"author": true, "comments": true, "comments.author": true, "tags": false, "comm": false} {
+		if got := inc.Has(path); got != want {
+			t.Errorf("Has(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
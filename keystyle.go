@@ -0,0 +1,137 @@
+package urlvalues
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// KeyStyle decides how the keys of fields of nested structs are composed with
+// the keys of the structs they are nested in. The key of a nested struct is the
+// name given in its tag, or its field name. Embedded structs have no key of
+// their own, unless they have the "prefix" tag option. Nested structs with
+// the "squash" tag option have no key of their own either.
+type KeyStyle int
+
+const (
+	// FlatKeys keys fields of nested structs as if they were fields of the
+	// outer struct, such as "size". Keys of nested structs with the "prefix"
+	// tag option are still joined by dots, such as "page.size".
+	FlatKeys KeyStyle = iota
+	// DotKeys joins the keys of nested structs and their fields by dots, such
+	// as "page.size".
+	DotKeys
+	// BracketKeys encloses the keys of fields of nested structs in brackets,
+	// such as "page[size]", as understood by many JavaScript libraries.
+	BracketKeys
+)
+
+// fullKey returns key, the key of f or any of its aliases or deprecated keys,
+// composed with the keys of the structs that f is nested in according to the
+// key style set in pOpts, and prefixed by any prefix set in pOpts.
+func (f field) fullKey(key string, pOpts *ParseOptions) string {
+	flat := pOpts.keyStyle == FlatKeys && !slices.ContainsFunc(f.path, func(p pathKey) bool { return p.prefix })
+	if len(f.path) == 0 || flat {
+		return pOpts.prefix + key
+	}
+
+	var b strings.Builder
+	b.WriteString(pOpts.prefix)
+	switch pOpts.keyStyle {
+	case FlatKeys:
+		for _, p := range f.path {
+			if p.prefix {
+				b.WriteString(p.key)
+				b.WriteByte('.')
+			}
+		}
+		b.WriteString(key)
+	case DotKeys:
+		for _, p := range f.path {
+			b.WriteString(p.key)
+			b.WriteByte('.')
+		}
+		b.WriteString(key)
+	case BracketKeys:
+		b.WriteString(f.path[0].key)
+		for _, p := range f.path[1:] {
+			b.WriteString("[" + p.key + "]")
+		}
+		b.WriteString("[" + key + "]")
+	}
+	return b.String()
+}
+
+// KeyNaming decides how the keys of fields are derived from their field names
+// when their tags give no key. It applies to the keys of nested structs as
+// well. Keys given in tags are used as is.
+type KeyNaming int
+
+const (
+	// FieldNames keys fields by their field names as is, such as "PageSize".
+	FieldNames KeyNaming = iota
+	// SnakeCase keys fields by their field names in snake case, such as
+	// "page_size".
+	SnakeCase
+	// CamelCase keys fields by their field names in camel case, such as
+	// "pageSize".
+	CamelCase
+	// KebabCase keys fields by their field names in kebab case, such as
+	// "page-size".
+	KebabCase
+)
+
+// key returns the key of the field named name according to n.
+func (n KeyNaming) key(name string) string {
+	switch n {
+	case SnakeCase:
+		return strings.ToLower(strings.Join(words(name), "_"))
+	case CamelCase:
+		var b strings.Builder
+		for i, w := range words(name) {
+			if i == 0 {
+				b.WriteString(strings.ToLower(w))
+				continue
+			}
+			b.WriteString(strings.ToUpper(w[:1]))
+			b.WriteString(strings.ToLower(w[1:]))
+		}
+		return b.String()
+	case KebabCase:
+		return strings.ToLower(strings.Join(words(name), "-"))
+	}
+	return name
+}
+
+// words splits the field name name into its words. A word starts at an upper
+// case letter following a lower case letter or digit, or at the last upper
+// case letter of an acronym followed by a lower case letter, such that
+// "HTTPServerID" splits into "HTTP", "Server" and "ID". Underscores separate
+// words as well.
+func words(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,189 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestKeyStyle(t *testing.T) {
+	type Size struct {
+		Width int `urlvalue:"w"`
+	}
+	type Page struct {
+		Number int `urlvalue:"number"`
+		Size   Size
+	}
+	type Base struct {
+		ID string `urlvalue:"id"`
+	}
+	type Target struct {
+		Base
+		Query string `urlvalue:"q"`
+		Page  *Page  `urlvalue:"page"`
+	}
+
+	in := Target{Base: Base{ID: "1"}, Query: "shoes", Page: &Page{Number: 2, Size: Size{Width: 3}}}
+
+	tests := []struct {
+		name  string
+		style urlvalues.KeyStyle
+		want  url.Values
+	}{
+		{"flat", urlvalues.FlatKeys, url.Values{"id": {"1"}, "q": {"shoes"}, "number": {"2"}, "w": {"3"}}},
+		{"dot", urlvalues.DotKeys, url.Values{"id": {"1"}, "q": {"shoes"}, "page.number": {"2"}, "page.Size.w": {"3"}}},
+		{"bracket", urlvalues.BracketKeys, url.Values{"id": {"1"}, "q": {"shoes"}, "page[number]": {"2"}, "page[Size][w]": {"3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.Marshal(in, urlvalues.WithKeyStyle(tt.style))
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+			}
+
+			var decoded Target
+			if err := urlvalues.Unmarshal(tt.want, &decoded, urlvalues.WithKeyStyle(tt.style)); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.want, &decoded, err)
+			}
+			if diff := cmp.Diff(decoded, in); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestKeyStyle_PrefixAndSquash(t *testing.T) {
+	type Pagination struct {
+		Page int `urlvalue:"page"`
+	}
+	type Sorting struct {
+		Page int `urlvalue:"page"`
+	}
+	type Filter struct {
+		Status string `urlvalue:"status"`
+	}
+	type Target struct {
+		Pagination `urlvalue:"pagination,prefix"`
+		Sorting    `urlvalue:"sorting,prefix"`
+		Filter     Filter `urlvalue:"filter,squash"`
+	}
+
+	in := Target{Pagination: Pagination{Page: 2}, Sorting: Sorting{Page: 3}, Filter: Filter{Status: "open"}}
+
+	tests := []struct {
+		name  string
+		style urlvalues.KeyStyle
+		want  url.Values
+	}{
+		{"flat", urlvalues.FlatKeys, url.Values{"pagination.page": {"2"}, "sorting.page": {"3"}, "status": {"open"}}},
+		{"dot", urlvalues.DotKeys, url.Values{"pagination.page": {"2"}, "sorting.page": {"3"}, "status": {"open"}}},
+		{"bracket", urlvalues.BracketKeys, url.Values{"pagination[page]": {"2"}, "sorting[page]": {"3"}, "status": {"open"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.Marshal(in, urlvalues.WithKeyStyle(tt.style))
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+			}
+
+			var decoded Target
+			if err := urlvalues.Unmarshal(tt.want, &decoded, urlvalues.WithKeyStyle(tt.style)); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.want, &decoded, err)
+			}
+			if diff := cmp.Diff(decoded, in); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			target any
+		}{
+			{"both", &struct {
+				Filter Filter `urlvalue:"filter,prefix,squash"`
+			}{}},
+			{"not a struct", &struct {
+				Page int `urlvalue:"page,prefix"`
+			}{}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if err := urlvalues.Unmarshal(url.Values{}, tt.target); err == nil {
+					t.Errorf("urlvalues.Unmarshal(..., %v) = <nil>, want error", tt.target)
+				}
+			})
+		}
+	})
+}
+
+func TestKeyNaming(t *testing.T) {
+	type PageInfo struct {
+		PageSize int
+	}
+	type Target struct {
+		UserID         string
+		HTTPStatusCode int
+		Query          string `urlvalue:"q"`
+		PageInfo       PageInfo
+		Created_At     string
+	}
+
+	in := Target{UserID: "u1", HTTPStatusCode: 404, Query: "shoes", PageInfo: PageInfo{PageSize: 20}, Created_At: "today"}
+
+	tests := []struct {
+		name   string
+		naming urlvalues.KeyNaming
+		want   url.Values
+	}{
+		{
+			"field names",
+			urlvalues.FieldNames,
+			url.Values{"UserID": {"u1"}, "HTTPStatusCode": {"404"}, "q": {"shoes"}, "PageInfo.PageSize": {"20"}, "Created_At": {"today"}},
+		},
+		{
+			"snake case",
+			urlvalues.SnakeCase,
+			url.Values{"user_id": {"u1"}, "http_status_code": {"404"}, "q": {"shoes"}, "page_info.page_size": {"20"}, "created_at": {"today"}},
+		},
+		{
+			"camel case",
+			urlvalues.CamelCase,
+			url.Values{"userId": {"u1"}, "httpStatusCode": {"404"}, "q": {"shoes"}, "pageInfo.pageSize": {"20"}, "createdAt": {"today"}},
+		},
+		{
+			"kebab case",
+			urlvalues.KebabCase,
+			url.Values{"user-id": {"u1"}, "http-status-code": {"404"}, "q": {"shoes"}, "page-info.page-size": {"20"}, "created-at": {"today"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []urlvalues.SetParseOptionFunc{urlvalues.WithKeyNaming(tt.naming), urlvalues.WithKeyStyle(urlvalues.DotKeys)}
+			got, err := urlvalues.Marshal(in, opts...)
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+			}
+
+			var decoded Target
+			if err := urlvalues.Unmarshal(tt.want, &decoded, opts...); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.want, &decoded, err)
+			}
+			if diff := cmp.Diff(decoded, in); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package urlvalues
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TooManyValuesError occurs when a slice or map field receives more values
+// than allowed. See [WithMaxSliceLen].
+type TooManyValuesError struct {
+	// Maximum number of values allowed.
+	Max int
+	// Number of values received.
+	Count int
+}
+
+func (err *TooManyValuesError) Error() string {
+	return fmt.Sprintf("too many values: got %d, want at most %d", err.Count, err.Max)
+}
+
+// ValueTooLongError occurs when a URL value is longer than allowed. See
+// [WithMaxValueLen].
+type ValueTooLongError struct {
+	// Maximum number of bytes allowed.
+	Max int
+	// Number of bytes received.
+	Len int
+}
+
+func (err *ValueTooLongError) Error() string {
+	return fmt.Sprintf("value too long: got %d bytes, want at most %d", err.Len, err.Max)
+}
+
+// TooLargeError occurs when the URL values decoded by a single call are larger
+// in total than allowed. See [WithMaxTotalSize].
+type TooLargeError struct {
+	// Maximum number of bytes allowed.
+	Max int
+}
+
+func (err *TooLargeError) Error() string {
+	return fmt.Sprintf("values too large: want at most %d bytes in total", err.Max)
+}
+
+// InvalidCharError occurs when a URL value contains a control character or
+// invalid UTF-8. See [WithRejectControlChars].
+type InvalidCharError struct {
+	// Offset in bytes of the character within the value.
+	Offset int
+	// The offending character, or utf8.RuneError if the value is not valid
+	// UTF-8 at Offset.
+	Char rune
+}
+
+func (err *InvalidCharError) Error() string {
+	if err.Char == utf8.RuneError {
+		return fmt.Sprintf("invalid UTF-8 at offset %d", err.Offset)
+	}
+	return fmt.Sprintf("invalid control character %U at offset %d", err.Char, err.Offset)
+}
+
+// checkChars returns an InvalidCharError if any of values contains a control
+// character or invalid UTF-8, and pOpts rejects them.
+func checkChars(values []string, pOpts *ParseOptions) error {
+	if !pOpts.rejectControlChars {
+		return nil
+	}
+	for _, value := range values {
+		for i := 0; i < len(value); {
+			r, size := utf8.DecodeRuneInString(value[i:])
+			// A RuneError of size 1 is invalid UTF-8 rather than an encoded
+			// replacement character.
+			if (r == utf8.RuneError && size == 1) || unicode.IsControl(r) {
+				return &InvalidCharError{Offset: i, Char: r}
+			}
+			i += size
+		}
+	}
+	return nil
+}
+
+// checkSliceLen returns a TooManyValuesError if n values are more than allowed
+// by fOpts or pOpts. Callers count delimited values without splitting them, so
+// that nothing is allocated for values that are too long.
+func checkSliceLen(n int, fOpts fieldOptions, pOpts ParseOptions) error {
+	limit := pOpts.maxSliceLen
+	if fOpts.maxSliceLen > 0 {
+		limit = fOpts.maxSliceLen
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	if n > limit {
+		return &TooManyValuesError{Max: limit, Count: n}
+	}
+	return nil
+}
+
+// checkSize returns a ValueTooLongError if any of values is longer than
+// allowed by pOpts, or a TooLargeError if values exceed the total size left
+// for the current call. The size of values is deducted from the total size
+// left.
+func checkSize(values []string, pOpts *ParseOptions) error {
+	for _, value := range values {
+		if pOpts.maxValueLen > 0 && len(value) > pOpts.maxValueLen {
+			return &ValueTooLongError{Max: pOpts.maxValueLen, Len: len(value)}
+		}
+		if pOpts.processed != nil {
+			*pOpts.processed += len(value)
+			if pOpts.maxTotalSize > 0 && *pOpts.processed > pOpts.maxTotalSize {
+				return &TooLargeError{Max: pOpts.maxTotalSize}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshal_WithMaxSliceLen(t *testing.T) {
+	type Target struct {
+		IDs  []int             `urlvalue:"ids"`
+		Tags []string          `urlvalue:"tags,maxslicelen:1"`
+		Meta map[string]string `urlvalue:"meta"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.TooManyValuesError
+	}{
+		{"within limits", url.Values{"ids": {"1;2"}, "tags": {"a"}, "meta": {"a:1", "b:2"}}, nil},
+		{"delimited values", url.Values{"ids": {"1;2;3"}}, &urlvalues.TooManyValuesError{Max: 2, Count: 3}},
+		{"repeated keys", url.Values{"ids": {"1", "2", "3"}}, &urlvalues.TooManyValuesError{Max: 2, Count: 3}},
+		{"map", url.Values{"meta": {"a:1;b:2;c:3"}}, &urlvalues.TooManyValuesError{Max: 2, Count: 3}},
+		{"tag override", url.Values{"tags": {"a;b"}}, &urlvalues.TooManyValuesError{Max: 1, Count: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target, urlvalues.WithMaxSliceLen(2))
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var got *urlvalues.TooManyValuesError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_WithMaxValueLen(t *testing.T) {
+	type Target struct {
+		Name string   `urlvalue:"name"`
+		Tags []string `urlvalue:"tags"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.ValueTooLongError
+	}{
+		{"within limit", url.Values{"name": {"abcd"}, "tags": {"abcd", "efgh"}}, nil},
+		{"too long", url.Values{"name": {"abcde"}}, &urlvalues.ValueTooLongError{Max: 4, Len: 5}},
+		{"repeated key too long", url.Values{"tags": {"a", "abcde"}}, &urlvalues.ValueTooLongError{Max: 4, Len: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target, urlvalues.WithMaxValueLen(4))
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var got *urlvalues.ValueTooLongError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_WithMaxValueLenRaw(t *testing.T) {
+	type Target struct {
+		Name string `urlvalue:"name"`
+	}
+
+	tests := []struct {
+		name string
+		opts []urlvalues.SetParseOptionFunc
+	}{
+		{"unescape", []urlvalues.SetParseOptionFunc{urlvalues.WithUnescape()}},
+		{"value transformer", []urlvalues.SetParseOptionFunc{urlvalues.WithValueTransformer(func(key, value string) string {
+			return value[:1]
+		})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := url.Values{"name": {"%41%42%43"}}
+			var target Target
+			err := urlvalues.Unmarshal(in, &target, append(tt.opts, urlvalues.WithMaxValueLen(4))...)
+
+			var got *urlvalues.ValueTooLongError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, &urlvalues.ValueTooLongError{Max: 4, Len: 9}); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_WithMaxTotalSize(t *testing.T) {
+	type Target struct {
+		A string `urlvalue:"a"`
+		B string `urlvalue:"b"`
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		in := url.Values{"a": {"123"}, "b": {"456"}, "unused": {"ignored"}}
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target, urlvalues.WithMaxTotalSize(6)); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		in := url.Values{"a": {"123"}, "b": {"4567"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithMaxTotalSize(6))
+
+		var got *urlvalues.TooLargeError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+		}
+		if got.Max != 6 {
+			t.Errorf("Max = %d, want 6", got.Max)
+		}
+	})
+
+	t.Run("shared between namespaces", func(t *testing.T) {
+		in := url.Values{"x.a": {"123"}, "y.a": {"4567"}}
+		var x, y Target
+		err := urlvalues.UnmarshalNamespaces(in, map[string]any{"x": &x, "y": &y}, urlvalues.WithMaxTotalSize(6))
+
+		var got *urlvalues.TooLargeError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.UnmarshalNamespaces(%v, ...) = %v, want %q", in, err, reflect.TypeOf(got).String())
+		}
+	})
+}
+
+func TestUnmarshal_WithRejectControlChars(t *testing.T) {
+	type Target struct {
+		Name string   `urlvalue:"name"`
+		Tags []string `urlvalue:"tags"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.InvalidCharError
+	}{
+		{"printable", url.Values{"name": {"héllo wörld \uFFFD"}, "tags": {"a", "b"}}, nil},
+		{"line break", url.Values{"name": {"foo\r\nSet-Cookie: x"}}, &urlvalues.InvalidCharError{Offset: 3, Char: '\r'}},
+		{"tab", url.Values{"tags": {"a", "b\tc"}}, &urlvalues.InvalidCharError{Offset: 1, Char: '\t'}},
+		{"null byte", url.Values{"name": {"a\x00"}}, &urlvalues.InvalidCharError{Offset: 1, Char: 0}},
+		{"invalid utf-8", url.Values{"name": {"ab\xff"}}, &urlvalues.InvalidCharError{Offset: 2, Char: utf8.RuneError}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target, urlvalues.WithRejectControlChars())
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var got *urlvalues.InvalidCharError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+			var parseErr *urlvalues.ParseError
+			if errors.As(err, &parseErr) && parseErr.Code() != urlvalues.CodeInvalidSyntax {
+				t.Errorf("ParseError.Code() = %q, want %q", parseErr.Code(), urlvalues.CodeInvalidSyntax)
+			}
+		})
+	}
+
+	t.Run("unescaped", func(t *testing.T) {
+		in := url.Values{"name": {"foo%0D%0ASet-Cookie"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithRejectControlChars(), urlvalues.WithUnescape())
+
+		var got *urlvalues.InvalidCharError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		in := url.Values{"name": {"a\tb"}}
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+	})
+}
+
+func TestUnmarshal_DynamicLimits(t *testing.T) {
+	t.Run("value too long", func(t *testing.T) {
+		in := url.Values{"name": {"abcde"}}
+		var target map[string]any
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithMaxValueLen(4))
+
+		var got *urlvalues.ValueTooLongError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+		}
+		if diff := cmp.Diff(got, &urlvalues.ValueTooLongError{Max: 4, Len: 5}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+		}
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) || parseErr.Key != "name" {
+			t.Errorf("urlvalues.Unmarshal(...) = %v, want *urlvalues.ParseError with key %q", err, "name")
+		}
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		in := url.Values{"a": {"123"}, "b": {"4567"}}
+		var target map[string]any
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithMaxValueLen(4), urlvalues.WithMaxTotalSize(6))
+
+		var got *urlvalues.TooLargeError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+		}
+		if got.Max != 6 {
+			t.Errorf("Max = %d, want 6", got.Max)
+		}
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		in := url.Values{"a": {"123"}, "b": {"456"}}
+		var target map[string]any
+		if err := urlvalues.Unmarshal(in, &target, urlvalues.WithMaxValueLen(4), urlvalues.WithMaxTotalSize(6)); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+		if diff := cmp.Diff(target, map[string]any{"a": int64(123), "b": int64(456)}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+}
+
+func TestUnmarshal_DynamicMaxSliceLen(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.TooManyValuesError
+	}{
+		{"within limits", url.Values{"ids": {"1;2"}}, nil},
+		{"delimited values", url.Values{"ids": {"1;2;3"}}, &urlvalues.TooManyValuesError{Max: 2, Count: 3}},
+		{"repeated keys", url.Values{"ids": {"1", "2", "3"}}, &urlvalues.TooManyValuesError{Max: 2, Count: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target map[string]any
+			err := urlvalues.Unmarshal(tt.in, &target, urlvalues.WithMaxSliceLen(2))
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var got *urlvalues.TooManyValuesError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+			var parseErr *urlvalues.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Errorf("urlvalues.Unmarshal(...) = %v, want %q", err, "*urlvalues.ParseError")
+			}
+		})
+	}
+}
+
+func TestUnmarshal_DynamicRejectControlChars(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.InvalidCharError
+	}{
+		{"printable", url.Values{"name": {"héllo wörld"}}, nil},
+		{"control char in value", url.Values{"name": {"foo\r\nSet-Cookie: x"}}, &urlvalues.InvalidCharError{Offset: 3, Char: '\r'}},
+		{"control char in key", url.Values{"na\nme": {"foo"}}, &urlvalues.InvalidCharError{Offset: 2, Char: '\n'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target map[string]any
+			err := urlvalues.Unmarshal(tt.in, &target, urlvalues.WithRejectControlChars())
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var got *urlvalues.InvalidCharError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+			if len(target) != 0 {
+				t.Errorf("urlvalues.Unmarshal(...) decoded %v, want no entries", target)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package urlvalues
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorCode classifies why a URL value failed to be parsed.
+type ErrorCode string
+
+const (
+	// CodeInvalid is the code of values that are invalid for any reason not
+	// covered by the other codes.
+	CodeInvalid ErrorCode = "invalid"
+	// CodeInvalidSyntax is the code of values that are not in the format
+	// expected by the field's type, such as "abc" for an int field, or that
+	// contain characters rejected by [WithRejectControlChars].
+	CodeInvalidSyntax ErrorCode = "invalid_syntax"
+	// CodeOutOfRange is the code of values that are out of range for the
+	// field's type, such as "300" for an int8 field.
+	CodeOutOfRange ErrorCode = "out_of_range"
+	// CodeTooManyValues is the code of values holding more items than allowed
+	// for a slice or map field. See [WithMaxSliceLen].
+	CodeTooManyValues ErrorCode = "too_many_values"
+	// CodeTooLong is the code of values that are too long, or that exceed the
+	// total size allowed. See [WithMaxValueLen] and [WithMaxTotalSize].
+	CodeTooLong ErrorCode = "too_long"
+	// CodeMultipleValues is the code of keys with multiple values decoded into
+	// a field holding a single value. See [WithMultiplePolicy].
+	CodeMultipleValues ErrorCode = "multiple_values"
+	// CodeConstraint is the code of values violating a constraint declared by
+	// a tag option, such as the "maxitems" option.
+	CodeConstraint ErrorCode = "constraint"
+)
+
+// errorCode returns the ErrorCode classifying err.
+func errorCode(err error) ErrorCode {
+	var (
+		timeErr     *time.ParseError
+		tooManyErr  *TooManyValuesError
+		tooLongErr  *ValueTooLongError
+		tooLargeErr *TooLargeError
+		multipleErr *MultipleValuesError
+		constrErr   *ConstraintError
+		validErr    *ValidationError
+		charErr     *InvalidCharError
+		escapeErr   url.EscapeError
+	)
+	switch {
+	case errors.As(err, &constrErr), errors.As(err, &validErr):
+		return CodeConstraint
+	case errors.As(err, &multipleErr):
+		return CodeMultipleValues
+	case errors.As(err, &tooManyErr):
+		return CodeTooManyValues
+	case errors.As(err, &tooLongErr), errors.As(err, &tooLargeErr):
+		return CodeTooLong
+	case errors.Is(err, strconv.ErrSyntax), errors.As(err, &timeErr), errors.As(err, &charErr), errors.As(err, &escapeErr):
+		return CodeInvalidSyntax
+	case errors.Is(err, strconv.ErrRange):
+		return CodeOutOfRange
+	default:
+		return CodeInvalid
+	}
+}
+
+// Messages maps error codes to message templates used to render the messages
+// of [ParseError] values. Templates may contain the following placeholders:
+//
+//	{field}	name of the struct field
+//	{key}	key into URL values
+//	{value}	the offending value
+//	{type}	Go type of the struct field
+//	{expected}	what a valid value looks like, see [ParseError.Expected]
+//
+// Templates are plain strings, so a Messages value per supported language
+// allows for rendering messages in the language of the client.
+type Messages map[ErrorCode]string
+
+// message renders the message of e, or returns false if there is no template
+// for the code of e nor for [CodeInvalid].
+func (m Messages) message(e *ParseError) (string, bool) {
+	tmpl, ok := m[e.Code()]
+	if !ok {
+		tmpl, ok = m[CodeInvalid]
+	}
+	if !ok {
+		return "", false
+	}
+
+	return strings.NewReplacer(
+		"{field}", e.FieldName,
+		"{key}", e.Key,
+		"{value}", e.fe.value,
+		"{type}", e.fe.typeName,
+		"{expected}", e.Expected,
+	).Replace(tmpl), true
+}
+
+// FieldErrorInfo describes why the value of a field failed to be parsed, for
+// rendering the message of a [ParseError] by an [ErrorFormatterFunc].
+type FieldErrorInfo struct {
+	// Name of struct field.
+	Field string
+	// Key into URL values.
+	Key string
+	// The offending value.
+	Value string
+	// Go type of the struct field.
+	Type string
+	// Code classifying why the value failed to be parsed.
+	Code ErrorCode
+	// What a valid value looks like, see [ParseError.Expected].
+	Expected string
+	// Default message, without mentioning the key.
+	Message string
+	// Underlying error.
+	Err error
+}
+
+// ErrorFormatterFunc renders the message of a [ParseError] from info. An
+// empty message leaves the default message in place.
+type ErrorFormatterFunc func(info FieldErrorInfo) string
+
+// info returns the FieldErrorInfo of e.
+func (e *ParseError) info() FieldErrorInfo {
+	return FieldErrorInfo{
+		Field:    e.FieldName,
+		Key:      e.Key,
+		Value:    e.fe.value,
+		Type:     e.fe.typeName,
+		Code:     e.Code(),
+		Expected: e.Expected,
+		Message:  e.reason(),
+		Err:      e.fe.err,
+	}
+}
+
+// expected describes what a valid value of field looks like, if known from
+// the type and tag options of the field: the layout of time fields, the names
+// of enum types and the pattern of the "pattern" option.
+func expected(field field, pOpts *ParseOptions) string {
+	typ := field.field.Type()
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType && pOpts.unixTime:
+		return "seconds since the Unix epoch"
+	case typ == timeType && fieldLayout(field.options, *pOpts) == autoLayout:
+		return "format " + strings.Join(autoLayouts, " or ")
+	case typ == timeType && field.options.ago:
+		return "a duration such as 24h"
+	case typ == timeType && field.options.layout == quarterLayout:
+		return "a quarter such as 2024-Q2"
+	case typ == timeType && field.options.layout == "":
+		return "format " + time.Layout + " or " + time.RFC3339
+	case typ == timeType:
+		return "format " + timeLayout(field.options.layout)
+	}
+	if set := enumOf(typ); set != nil {
+		return "one of " + strings.Join(set.names, ", ")
+	}
+	if field.options.pattern != nil {
+		return "a match of " + field.options.pattern.String()
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshal_WithMessages(t *testing.T) {
+	type Target struct {
+		Page  int8 `urlvalue:"page"`
+		Debug bool `urlvalue:"debug"`
+	}
+
+	swedish := urlvalues.Messages{
+		urlvalues.CodeInvalidSyntax: "{key}: {value} är inte ett giltigt värde",
+		urlvalues.CodeOutOfRange:    "{key}: {value} är utanför intervallet för {type}",
+	}
+
+	tests := []struct {
+		name     string
+		in       url.Values
+		messages urlvalues.Messages
+		wantCode urlvalues.ErrorCode
+		wantMsg  string
+	}{
+		{
+			"invalid syntax",
+			url.Values{"page": {"abc"}},
+			swedish,
+			urlvalues.CodeInvalidSyntax,
+			"page: abc är inte ett giltigt värde",
+		},
+		{
+			"out of range",
+			url.Values{"page": {"300"}},
+			swedish,
+			urlvalues.CodeOutOfRange,
+			"page: 300 är utanför intervallet för int8",
+		},
+		{
+			"fallback to invalid template",
+			url.Values{"page": {"300"}},
+			urlvalues.Messages{urlvalues.CodeInvalid: "bad {field}"},
+			urlvalues.CodeOutOfRange,
+			"bad Page",
+		},
+		{
+			"fallback to default message",
+			url.Values{"debug": {"maybe"}},
+			urlvalues.Messages{},
+			urlvalues.CodeInvalidSyntax,
+			`error parsing value of debug: strconv.ParseBool: parsing "maybe": invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target, urlvalues.WithMessages(tt.messages))
+
+			parseErr, ok := err.(*urlvalues.ParseError)
+			if !ok {
+				t.Fatalf("urlvalues.Unmarshal(%v, ...) = %v, want *urlvalues.ParseError", tt.in, err)
+			}
+			if got := parseErr.Code(); got != tt.wantCode {
+				t.Errorf("Code() = %q, want %q", got, tt.wantCode)
+			}
+			if got := parseErr.Error(); got != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestParseError_Expected(t *testing.T) {
+	type Target struct {
+		Date   time.Time   `urlvalue:"date,layout:DateOnly"`
+		Dates  []time.Time `urlvalue:"dates,layout:2006-01"`
+		Color  color       `urlvalue:"color"`
+		Code   string      `urlvalue:"code,pattern:^[A-Z]{3}$"`
+		Amount int         `urlvalue:"amount"`
+	}
+
+	tests := []struct {
+		name         string
+		in           url.Values
+		setParseOpts []urlvalues.SetParseOptionFunc
+		wantExpected string
+		wantReason   string
+	}{
+		{
+			"layout",
+			url.Values{"date": {"01/02/2024"}},
+			nil,
+			"format 2006-01-02",
+			`parsing time "01/02/2024" as "2006-01-02": cannot parse "01/02/2024" as "2006" (expected format 2006-01-02)`,
+		},
+		{
+			"slice layout",
+			url.Values{"dates": {"2024-01;jan"}},
+			nil,
+			"format 2006-01",
+			`parsing time "jan" as "2006-01": cannot parse "jan" as "2006" (expected format 2006-01)`,
+		},
+		{
+			"unix time",
+			url.Values{"date": {"yesterday"}},
+			[]urlvalues.SetParseOptionFunc{urlvalues.WithUnixTime()},
+			"seconds since the Unix epoch",
+			"",
+		},
+		{
+			"enum already stated",
+			url.Values{"color": {"purple"}},
+			nil,
+			"one of Red, Green, Blue",
+			`got "purple", want one of Red, Green, Blue`,
+		},
+		{
+			"pattern already stated",
+			url.Values{"code": {"abc"}},
+			nil,
+			"a match of ^[A-Z]{3}$",
+			`got "abc", want a match of ^[A-Z]{3}$`,
+		},
+		{
+			"unknown",
+			url.Values{"amount": {"much"}},
+			nil,
+			"",
+			`strconv.ParseInt: parsing "much": invalid syntax`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target, tt.setParseOpts...)
+
+			parseErr, ok := err.(*urlvalues.ParseError)
+			if !ok {
+				t.Fatalf("urlvalues.Unmarshal(%v, ...) = %v, want *urlvalues.ParseError", tt.in, err)
+			}
+			if parseErr.Expected != tt.wantExpected {
+				t.Errorf("Expected = %q, want %q", parseErr.Expected, tt.wantExpected)
+			}
+			if got := urlvalues.NewProblem(err).InvalidParams[0].Reason; tt.wantReason != "" && got != tt.wantReason {
+				t.Errorf("reason = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+
+	t.Run("template", func(t *testing.T) {
+		in := url.Values{"date": {"today"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithMessages(urlvalues.Messages{
+			urlvalues.CodeInvalid: "{key} must have the {expected}",
+		}))
+		if got, want := err.Error(), "date must have the format 2006-01-02"; got != want {
+			t.Errorf("Error() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestUnmarshal_WithErrorFormatter(t *testing.T) {
+	type Target struct {
+		Page  int       `urlvalue:"page,max:10"`
+		Since time.Time `urlvalue:"since,layout:DateOnly"`
+	}
+
+	formatter := func(info urlvalues.FieldErrorInfo) string {
+		switch info.Code {
+		case urlvalues.CodeConstraint:
+			return "Please pick a smaller " + info.Field + "."
+		case urlvalues.CodeInvalidSyntax:
+			return "Please enter " + info.Key + " in the " + info.Expected + "."
+		default:
+			return ""
+		}
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantMsg string
+	}{
+		{"constraint", url.Values{"page": {"11"}}, "Please pick a smaller Page."},
+		{"invalid syntax", url.Values{"since": {"yesterday"}}, "Please enter since in the format 2006-01-02."},
+		{"default message", url.Values{"page": {"99999999999999999999"}}, "error parsing value of page: value out of range, must be between -9223372036854775808 and 9223372036854775807"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target,
+				urlvalues.WithMessages(urlvalues.Messages{urlvalues.CodeInvalid: "ignored"}),
+				urlvalues.WithErrorFormatter(formatter),
+			)
+
+			parseErr, ok := err.(*urlvalues.ParseError)
+			if !ok {
+				t.Fatalf("urlvalues.Unmarshal(%v, ...) = %v, want *urlvalues.ParseError", tt.in, err)
+			}
+			if got := parseErr.Error(); got != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMsg)
+			}
+		})
+	}
+}
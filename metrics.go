@@ -0,0 +1,102 @@
+package urlvalues
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+// Metrics is implemented by instrumentation of decoding, such as collectors of
+// Prometheus or OpenTelemetry metrics. See [WithMetrics].
+//
+// To monitor decoding by endpoint, pass a Metrics value labelled with the
+// endpoint to [Handler], [Middleware] or [UnmarshalRequest] of each endpoint.
+type Metrics interface {
+	// OnDecodeStart is called before a value is decoded.
+	OnDecodeStart(DecodeStart)
+	// OnDecodeEnd is called after a value is decoded, whether it succeeded
+	// or not.
+	OnDecodeEnd(DecodeEnd)
+}
+
+// DecodeStart describes a value about to be decoded.
+type DecodeStart struct {
+	// Type of the value passed to the decoding function, such as
+	// *Params for a call to Unmarshal(data, &params).
+	Target reflect.Type
+}
+
+// DecodeEnd describes a value that was decoded.
+type DecodeEnd struct {
+	// Type of the value passed to the decoding function.
+	Target reflect.Type
+	// Time it took to decode the value.
+	Duration time.Duration
+	// Number of struct fields decoded, including the fields of nested
+	// structs and of the items of slices decoded by Unmarshal.
+	Fields int
+	// Error returned by the decoding function, if any.
+	Err error
+	// Class of Err.
+	Class ErrorClass
+	// Code of the first ParseError among Err, if Class is ClassParse.
+	Code ErrorCode
+}
+
+// ErrorClass classifies errors returned by decoding functions, such as
+// [Unmarshal], for the purpose of counting them.
+type ErrorClass string
+
+const (
+	// ClassNone is the class of no error.
+	ClassNone ErrorClass = ""
+	// ClassParse is the class of errors of values failing to be parsed, as
+	// reported by [ParseError].
+	ClassParse ErrorClass = "parse"
+	// ClassForm is the class of [ErrInvalidForm] errors.
+	ClassForm ErrorClass = "form"
+	// ClassInternal is the class of all other errors, such as invalid struct
+	// tags, which are programming errors.
+	ClassInternal ErrorClass = "internal"
+)
+
+// errorClass returns the ErrorClass classifying err, along with the code of
+// the first ParseError of err if it is of class ClassParse.
+func errorClass(err error) (ErrorClass, ErrorCode) {
+	var parseErr *ParseError
+	switch {
+	case err == nil:
+		return ClassNone, ""
+	case errors.As(err, &parseErr):
+		return ClassParse, parseErr.Code()
+	case errors.Is(err, ErrInvalidForm):
+		return ClassForm, ""
+	default:
+		return ClassInternal, ""
+	}
+}
+
+// observe reports the start of decoding v to the metrics, if any, and returns
+// a function reporting the end of decoding with the error pointed to by errp.
+func (o *ParseOptions) observe(v any) func(errp *error) {
+	if o.metrics == nil {
+		return func(*error) {}
+	}
+
+	typ := reflect.TypeOf(v)
+	o.metrics.OnDecodeStart(DecodeStart{Target: typ})
+	start := time.Now()
+
+	return func(errp *error) {
+		end := DecodeEnd{
+			Target:   typ,
+			Duration: time.Since(start),
+			Err:      *errp,
+		}
+		if o.decoded != nil {
+			end.Fields = *o.decoded
+		}
+		end.Class, end.Code = errorClass(end.Err)
+		o.metrics.OnDecodeEnd(end)
+	}
+}
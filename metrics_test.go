@@ -0,0 +1,103 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+type recordingMetrics struct {
+	starts []urlvalues.DecodeStart
+	ends   []urlvalues.DecodeEnd
+}
+
+func (m *recordingMetrics) OnDecodeStart(s urlvalues.DecodeStart) {
+	m.starts = append(m.starts, s)
+}
+
+func (m *recordingMetrics) OnDecodeEnd(e urlvalues.DecodeEnd) {
+	m.ends = append(m.ends, e)
+}
+
+var typeComparer = cmp.Comparer(func(a, b reflect.Type) bool { return a == b })
+
+func TestWithMetrics(t *testing.T) {
+	type Inner struct {
+		B string `urlvalue:"b"`
+	}
+	type Target struct {
+		A     int `urlvalue:"a"`
+		Inner Inner
+	}
+	targetType := reflect.TypeFor[*Target]()
+
+	tests := []struct {
+		name   string
+		decode func(*Target, ...urlvalues.SetParseOptionFunc) error
+		want   urlvalues.DecodeEnd
+	}{
+		{
+			name: "success",
+			decode: func(v *Target, opts ...urlvalues.SetParseOptionFunc) error {
+				return urlvalues.Unmarshal(url.Values{"a": {"1"}}, v, opts...)
+			},
+			want: urlvalues.DecodeEnd{Target: targetType, Fields: 2},
+		},
+		{
+			name: "parse error",
+			decode: func(v *Target, opts ...urlvalues.SetParseOptionFunc) error {
+				return urlvalues.Unmarshal(url.Values{"a": {"x"}}, v, opts...)
+			},
+			want: urlvalues.DecodeEnd{Target: targetType, Fields: 2, Class: urlvalues.ClassParse, Code: urlvalues.CodeInvalidSyntax},
+		},
+		{
+			name: "invalid form",
+			decode: func(v *Target, opts ...urlvalues.SetParseOptionFunc) error {
+				r := httptest.NewRequest("POST", "/", strings.NewReader("a=%zz"))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return urlvalues.UnmarshalRequest(r, v, opts...)
+			},
+			want: urlvalues.DecodeEnd{Target: targetType, Class: urlvalues.ClassForm},
+		},
+		{
+			name: "query",
+			decode: func(v *Target, opts ...urlvalues.SetParseOptionFunc) error {
+				return urlvalues.UnmarshalQuery("a=1&b=2", v, opts...)
+			},
+			want: urlvalues.DecodeEnd{Target: targetType, Fields: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				m      recordingMetrics
+				target Target
+			)
+			err := tt.decode(&target, urlvalues.WithMetrics(&m))
+
+			if diff := cmp.Diff(m.starts, []urlvalues.DecodeStart{{Target: targetType}}, typeComparer); diff != "" {
+				t.Errorf("OnDecodeStart calls -got +want\n%s", diff)
+			}
+			if len(m.ends) != 1 {
+				t.Fatalf("got %d OnDecodeEnd calls, want 1", len(m.ends))
+			}
+			got := m.ends[0]
+			if !errors.Is(got.Err, err) {
+				t.Errorf("DecodeEnd.Err = %v, want %v", got.Err, err)
+			}
+			if got.Duration < 0 {
+				t.Errorf("DecodeEnd.Duration = %v, want >= 0", got.Duration)
+			}
+			got.Err, got.Duration = nil, 0
+			if diff := cmp.Diff(got, tt.want, typeComparer); diff != "" {
+				t.Errorf("DecodeEnd -got +want\n%s", diff)
+			}
+		})
+	}
+}
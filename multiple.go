@@ -0,0 +1,76 @@
+package urlvalues
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MultiplePolicy decides how fields that hold a single value, such as int or
+// string fields, are decoded from keys with multiple values.
+type MultiplePolicy int
+
+const (
+	// JoinMultiple joins the values using the delimiter, as if they were a
+	// single delimited value.
+	JoinMultiple MultiplePolicy = iota
+	// TakeFirst decodes the first value and ignores the rest.
+	TakeFirst
+	// TakeLast decodes the last value and ignores the rest.
+	TakeLast
+	// RejectMultiple fails with a [MultipleValuesError].
+	RejectMultiple
+)
+
+// MultipleValuesError occurs when a field that holds a single value receives
+// multiple values and the [RejectMultiple] policy is in effect.
+type MultipleValuesError struct {
+	// Number of values received.
+	Count int
+}
+
+func (err *MultipleValuesError) Error() string {
+	return fmt.Sprintf("got %d values, want a single value", err.Count)
+}
+
+// applyMultiplePolicy returns the values of field to decode according to the
+// policy set in pOpts. Fields holding multiple values, such as slices and
+// maps, are not subject to the policy.
+func applyMultiplePolicy(field field, key string, values []string, pOpts *ParseOptions) ([]string, error) {
+	if len(values) < 2 || holdsMultiple(field.field) {
+		return values, nil
+	}
+
+	switch pOpts.multiplePolicy {
+	case TakeFirst:
+		pOpts.warn(Warning{
+			FieldName: field.name,
+			Key:       key,
+			Message:   fmt.Sprintf("%d values ignored in favour of the first value", len(values)-1),
+		})
+		return values[:1], nil
+	case TakeLast:
+		pOpts.warn(Warning{
+			FieldName: field.name,
+			Key:       key,
+			Message:   fmt.Sprintf("%d values ignored in favour of the last value", len(values)-1),
+		})
+		return values[len(values)-1:], nil
+	case RejectMultiple:
+		return nil, &MultipleValuesError{Count: len(values)}
+	default:
+		return values, nil
+	}
+}
+
+// holdsMultiple reports whether field is a slice or map, or a pointer to one,
+// that does not decode itself.
+func holdsMultiple(field reflect.Value) bool {
+	if textUnmarshaler(field) != nil || textUnmarshalerContext(field) != nil || binaryUnmarshaler(field) != nil {
+		return false
+	}
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map
+}
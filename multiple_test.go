@@ -0,0 +1,81 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshal_WithMultiplePolicy(t *testing.T) {
+	type Target struct {
+		ID   int      `urlvalue:"id"`
+		Name string   `urlvalue:"name"`
+		Tags []string `urlvalue:"tags"`
+	}
+
+	in := url.Values{
+		"name": {"a", "b"},
+		"tags": {"x", "y"},
+	}
+
+	tests := []struct {
+		name         string
+		policy       urlvalues.MultiplePolicy
+		want         Target
+		wantWarnings []urlvalues.Warning
+	}{
+		{"join", urlvalues.JoinMultiple, Target{Name: "a;b", Tags: []string{"x", "y"}}, nil},
+		{
+			"take first",
+			urlvalues.TakeFirst,
+			Target{Name: "a", Tags: []string{"x", "y"}},
+			[]urlvalues.Warning{{FieldName: "Name", Key: "name", Message: "1 values ignored in favour of the first value"}},
+		},
+		{
+			"take last",
+			urlvalues.TakeLast,
+			Target{Name: "b", Tags: []string{"x", "y"}},
+			[]urlvalues.Warning{{FieldName: "Name", Key: "name", Message: "1 values ignored in favour of the last value"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got         Target
+				gotWarnings []urlvalues.Warning
+			)
+			err := urlvalues.Unmarshal(in, &got,
+				urlvalues.WithMultiplePolicy(tt.policy),
+				urlvalues.WithWarningHandler(func(w urlvalues.Warning) { gotWarnings = append(gotWarnings, w) }),
+			)
+			if err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+			if diff := cmp.Diff(gotWarnings, tt.wantWarnings); diff != "" {
+				t.Errorf("warnings -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("reject", func(t *testing.T) {
+		in := url.Values{"id": {"1", "2"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithMultiplePolicy(urlvalues.RejectMultiple))
+
+		var got *urlvalues.MultipleValuesError
+		if !errors.As(err, &got) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(got).String())
+		}
+		if got.Count != 2 {
+			t.Errorf("Count = %d, want 2", got.Count)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package urlvalues
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Errors is a list of errors that occurred while decoding. It is returned
+// when decoding continues past the first error, for example by
+// [UnmarshalNamespaces].
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors in the list, for use by [errors.Is] and
+// [errors.As].
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// FieldErrors returns the messages of the [ParseError] values in the tree of
+// e keyed by the keys that failed to be decoded, which is the shape form
+// libraries expect for highlighting invalid inputs. The messages don't
+// mention the keys. Only the first message of each key is kept.
+func (e Errors) FieldErrors() map[string]string {
+	parseErrs := parseErrors(e)
+	m := make(map[string]string, len(parseErrs))
+	for _, parseErr := range parseErrs {
+		if _, ok := m[parseErr.Key]; !ok {
+			m[parseErr.Key] = parseErr.reason()
+		}
+	}
+	return m
+}
+
+// UnmarshalNamespaces unmarshals data into several values, each decoding the
+// keys of its own namespace. targets maps namespace names to pointers to the
+// struct values of the namespaces. A field with key "size" of the target of
+// the "page" namespace is decoded from the key "page.size". Keys outside of
+// all namespaces are ignored.
+//
+// Namespaces are decoded in lexical order of their names. Rather than
+// stopping at the first namespace that fails to be decoded, all namespaces are
+// decoded and any errors are returned together as [Errors].
+//
+// See [Unmarshal] for details on how the values of each namespace are
+// decoded. Like with Unmarshal, the targets may be dynamic maps or slices of
+// structs, and options provided by targets implementing [OptionsProvider]
+// apply to their namespaces.
+func UnmarshalNamespaces(data url.Values, targets map[string]any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptions(setParseOpts)
+	defer pOpts.observe(targets)(&err)
+	in, traceUnused := traceKeys(input{values: valuesLookup(data), valueKeys: valuesKeys(data)}, pOpts)
+	defer traceUnused()
+
+	names := make([]string, 0, len(targets))
+	for name := range targets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs Errors
+	for _, name := range names {
+		nsOpts := newParseOptionsFor(targets[name], setParseOpts)
+		nsOpts.prefix += name + "."
+		// Namespaces share the state of the call, such as the total size of
+		// the values decoded.
+		nsOpts.processed, nsOpts.decoded = pOpts.processed, pOpts.decoded
+		if pOpts.errorCount != nil {
+			nsOpts.errorCount = pOpts.errorCount
+		}
+		if err := unmarshalTarget(in, targets[name], nsOpts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshalNamespaces(t *testing.T) {
+	type Page struct {
+		Number int `urlvalue:"number,default:1"`
+		Size   int `urlvalue:"size,default:20"`
+	}
+	type Filter struct {
+		Status string `urlvalue:"status"`
+	}
+
+	in := url.Values{
+		"page.size":     {"50"},
+		"filter.status": {"open"},
+		"status":        {"ignored"},
+	}
+
+	var (
+		page   Page
+		filter Filter
+	)
+	err := urlvalues.UnmarshalNamespaces(in, map[string]any{"page": &page, "filter": &filter})
+	if err != nil {
+		t.Fatalf("urlvalues.UnmarshalNamespaces(%v, ...) = %q, want <nil>", in, err)
+	}
+
+	if diff := cmp.Diff(page, Page{Number: 1, Size: 50}); diff != "" {
+		t.Errorf("page -got +want\n%s", diff)
+	}
+	if diff := cmp.Diff(filter, Filter{Status: "open"}); diff != "" {
+		t.Errorf("filter -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalNamespaces_Errors(t *testing.T) {
+	type Page struct {
+		Size int `urlvalue:"size"`
+	}
+	type Filter struct {
+		Since int `urlvalue:"since"`
+	}
+
+	in := url.Values{
+		"page.size":    {"big"},
+		"filter.since": {"yesterday"},
+	}
+
+	var (
+		page   Page
+		filter Filter
+	)
+	err := urlvalues.UnmarshalNamespaces(in, map[string]any{"page": &page, "filter": &filter})
+
+	var errs urlvalues.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("urlvalues.UnmarshalNamespaces(%v, ...) = %v, want urlvalues.Errors", in, err)
+	}
+
+	var gotKeys []string
+	for _, err := range errs {
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("error %v is not a *urlvalues.ParseError", err)
+		}
+		gotKeys = append(gotKeys, parseErr.Key)
+	}
+	if diff := cmp.Diff(gotKeys, []string{"filter.since", "page.size"}); diff != "" {
+		t.Errorf("error keys -got +want\n%s", diff)
+	}
+}
+
+func TestErrors_FieldErrors(t *testing.T) {
+	type Target struct {
+		Page  int    `urlvalue:"page,min:1"`
+		Debug bool   `urlvalue:"debug"`
+		Name  string `urlvalue:"name"`
+	}
+
+	in := url.Values{"page": {"0"}, "debug": {"maybe"}, "name": {"alice"}}
+	var target Target
+	err := urlvalues.Unmarshal(in, &target, urlvalues.WithAllErrors())
+
+	var errs urlvalues.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want urlvalues.Errors", in, &target, err)
+	}
+	want := map[string]string{
+		"page":  "got 0, want at least 1",
+		"debug": `strconv.ParseBool: parsing "maybe": invalid syntax`,
+	}
+	if diff := cmp.Diff(errs.FieldErrors(), want); diff != "" {
+		t.Errorf("Errors.FieldErrors() -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalNamespaces_Targets(t *testing.T) {
+	type Item struct {
+		Name string `urlvalue:"name"`
+	}
+
+	in := url.Values{
+		"search.tags":          {"a|b"},
+		"search.filter.status": {"open"},
+		"meta.debug":           {"true"},
+		"items.[0].name":       {"x"},
+		"items.[1].name":       {"y"},
+	}
+
+	var (
+		search SearchParams
+		meta   map[string]any
+		items  []Item
+	)
+	err := urlvalues.UnmarshalNamespaces(in, map[string]any{"search": &search, "meta": &meta, "items": &items})
+	if err != nil {
+		t.Fatalf("urlvalues.UnmarshalNamespaces(%v, ...) = %q, want <nil>", in, err)
+	}
+
+	if diff := cmp.Diff(search, SearchParams{Tags: []string{"a", "b"}, Filter: Filter{Status: "open"}}); diff != "" {
+		t.Errorf("search -got +want\n%s", diff)
+	}
+	if diff := cmp.Diff(meta, map[string]any{"debug": true}); diff != "" {
+		t.Errorf("meta -got +want\n%s", diff)
+	}
+	if diff := cmp.Diff(items, []Item{{"x"}, {"y"}}); diff != "" {
+		t.Errorf("items -got +want\n%s", diff)
+	}
+}
@@ -0,0 +1,428 @@
+package odata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nahojer/urlvalues/internal/nesting"
+)
+
+// Filter is the $filter option, decoded into an expression tree. The
+// supported subset of the OData grammar consists of:
+//
+//   - the logical operators "and", "or" and "not", and parentheses;
+//   - the comparison operators "eq", "ne", "gt", "ge", "lt" and "le";
+//   - the functions "contains", "startswith" and "endswith";
+//   - property paths, such as "address/city";
+//   - string literals in single quotes, with single quotes escaped by
+//     doubling them, such as 'O”Neil';
+//   - number literals, and the literals true, false and null.
+//
+// For example, "price lt 10 and (contains(name,'milk') or not discontinued)".
+type Filter struct {
+	// Expr is the root of the expression tree, or nil if the filter was not
+	// decoded.
+	Expr Expr
+}
+
+// String returns the filter in the syntax of the $filter option.
+func (f Filter) String() string {
+	if f.Expr == nil {
+		return ""
+	}
+	return f.Expr.String()
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (f Filter) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (f *Filter) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "" {
+		f.Expr = nil
+		return nil
+	}
+	expr, err := ParseFilter(string(text))
+	if err != nil {
+		return err
+	}
+	f.Expr = expr
+	return nil
+}
+
+// Expr is an expression of a [Filter]: a [*BinaryExpr], [*NotExpr],
+// [*CallExpr], [Property] or [Literal].
+type Expr interface {
+	// String returns the expression in the syntax of the $filter option.
+	String() string
+	expr()
+}
+
+// BinaryExpr is a logical or comparison expression, such as "price lt 10".
+type BinaryExpr struct {
+	// Op is "and", "or", "eq", "ne", "gt", "ge", "lt" or "le".
+	Op          string
+	Left, Right Expr
+}
+
+// NotExpr is the negation of an expression, such as "not discontinued".
+type NotExpr struct {
+	Expr Expr
+}
+
+// CallExpr is a call of a function, such as "contains(name,'milk')".
+type CallExpr struct {
+	// Func is "contains", "startswith" or "endswith".
+	Func string
+	Args []Expr
+}
+
+// Property is a property path, such as "address/city".
+type Property string
+
+// Literal is a string, number, boolean or null literal. Value is a string,
+// int64, float64, bool, or nil.
+type Literal struct {
+	Value any
+}
+
+func (*BinaryExpr) expr() {}
+func (*NotExpr) expr()    {}
+func (*CallExpr) expr()   {}
+func (Property) expr()    {}
+func (Literal) expr()     {}
+
+func (e *BinaryExpr) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.Left, e.Op, e.Right)
+}
+
+func (e *NotExpr) String() string {
+	return "not " + e.Expr.String()
+}
+
+func (e *CallExpr) String() string {
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = arg.String()
+	}
+	return e.Func + "(" + strings.Join(args, ",") + ")"
+}
+
+func (p Property) String() string {
+	return string(p)
+}
+
+func (l Literal) String() string {
+	switch v := l.Value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ParseFilter parses s, the value of a $filter option, into an expression
+// tree. See [Filter] for the supported syntax.
+func ParseFilter(s string) (Expr, error) {
+	p := &parser{lex: lexer{s: s}}
+	p.next()
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return expr, nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+	// Depth of nested expressions being parsed.
+	depth int
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid $filter at offset %d: %s", p.tok.pos, fmt.Sprintf(format, args...))
+}
+
+// enter enters a nested expression, returning an error if it is nested
+// deeper than nesting.MaxDepth. Each call must be paired with a call to leave.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > nesting.MaxDepth {
+		return p.errorf("expression nested deeper than %d levels", nesting.MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+// isKeyword reports whether the current token is the keyword kw.
+func (p *parser) isKeyword(kw string) bool {
+	return p.tok.kind == tokIdent && p.tok.text == kw
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.isKeyword("not") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		p.next()
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Expr: expr}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokIdent && isComparison(p.tok.text) {
+		op := p.tok.text
+		p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &BinaryExpr{Op: op, Left: left, Right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("missing )")
+		}
+		p.next()
+		return expr, nil
+	case tokString:
+		p.next()
+		return Literal{Value: tok.text}, nil
+	case tokNumber:
+		p.next()
+		if n, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return Literal{Value: n}, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $filter number %q", tok.text)
+		}
+		return Literal{Value: f}, nil
+	case tokIdent:
+		p.next()
+		switch tok.text {
+		case "true", "false":
+			return Literal{Value: tok.text == "true"}, nil
+		case "null":
+			return Literal{}, nil
+		case "contains", "startswith", "endswith":
+			return p.parseCall(tok.text)
+		case "and", "or", "not", "eq", "ne", "gt", "ge", "lt", "le":
+			return nil, fmt.Errorf("invalid $filter at offset %d: unexpected %s", tok.pos, tok)
+		}
+		return Property(tok.text), nil
+	default:
+		return nil, p.errorf("unexpected %s", tok)
+	}
+}
+
+func (p *parser) parseCall(fn string) (Expr, error) {
+	if p.tok.kind != tokLParen {
+		return nil, p.errorf("missing ( after %s", fn)
+	}
+	p.next()
+
+	call := &CallExpr{Func: fn}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		if p.tok.kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if p.tok.kind != tokRParen {
+		return nil, p.errorf("missing ) after arguments of %s", fn)
+	}
+	p.next()
+
+	if len(call.Args) != 2 {
+		return nil, fmt.Errorf("invalid $filter: %s takes 2 arguments, got %d", fn, len(call.Args))
+	}
+	return call, nil
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "eq", "ne", "gt", "ge", "lt", "le":
+		return true
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokLParen
+	tokRParen
+	tokComma
+	tokInvalid
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return fmt.Sprintf("string '%s'", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
+
+type lexer struct {
+	s   string
+	pos int
+}
+
+func (l *lexer) next() token {
+	for l.pos < len(l.s) && l.s[l.pos] == ' ' {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.s) {
+		return token{kind: tokEOF, pos: start}
+	}
+
+	c := l.s[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}
+	case c == ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}
+	case c == ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", pos: start}
+	case c == '\'':
+		var b strings.Builder
+		for l.pos++; l.pos < len(l.s); l.pos++ {
+			if l.s[l.pos] != '\'' {
+				b.WriteByte(l.s[l.pos])
+				continue
+			}
+			// Single quotes are escaped by doubling them.
+			if l.pos+1 < len(l.s) && l.s[l.pos+1] == '\'' {
+				b.WriteByte('\'')
+				l.pos++
+				continue
+			}
+			l.pos++
+			return token{kind: tokString, text: b.String(), pos: start}
+		}
+		return token{kind: tokInvalid, text: l.s[start:], pos: start}
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.s) && (isDigit(l.s[l.pos]) || strings.IndexByte(".eE+-", l.s[l.pos]) >= 0) {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: l.s[start:l.pos], pos: start}
+	case isIdentByte(c):
+		for l.pos < len(l.s) && (isIdentByte(l.s[l.pos]) || isDigit(l.s[l.pos]) || l.s[l.pos] == '/') {
+			l.pos++
+		}
+		return token{kind: tokIdent, text: l.s[start:l.pos], pos: start}
+	default:
+		l.pos++
+		return token{kind: tokInvalid, text: string(c), pos: start}
+	}
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
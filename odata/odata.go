@@ -0,0 +1,116 @@
+// Package odata provides field types decoding the OData system query options
+// $orderby, $select and a practical subset of $filter, for use in structs
+// decoded by [urlvalues.Unmarshal]. [Query] bundles them with the $top, $skip
+// and $count options.
+//
+// See https://docs.oasis-open.org/odata/odata/v4.01/odata-v4.01-part2-url-conventions.html.
+package odata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Query holds the OData system query options, such as in
+// "?$top=10&$skip=20&$orderby=name desc&$select=id,name&$count=true&$filter=price lt 10".
+type Query struct {
+	// Maximum number of items to return, or nil if unlimited.
+	Top *int `urlvalue:"$top,min:0"`
+	// Number of items to skip.
+	Skip int `urlvalue:"$skip,min:0"`
+	// Order of the items.
+	OrderBy OrderBy `urlvalue:"$orderby"`
+	// Properties to return, or all properties if empty.
+	Select Select `urlvalue:"$select"`
+	// Whether to return the total number of items.
+	Count bool `urlvalue:"$count"`
+	// Condition the items must satisfy.
+	Filter Filter `urlvalue:"$filter"`
+}
+
+// OrderByItem is a property to order by, as given in an [OrderBy] option.
+type OrderByItem struct {
+	// Path of the property, such as "address/city".
+	Property string
+	// Whether to order in descending rather than ascending order.
+	Desc bool
+}
+
+// OrderBy is the $orderby option, decoded from comma-separated property
+// paths optionally followed by "asc" or "desc", such as "name desc,id".
+type OrderBy []OrderByItem
+
+// MarshalText implements [encoding.TextMarshaler].
+func (o OrderBy) MarshalText() ([]byte, error) {
+	items := make([]string, len(o))
+	for i, item := range o {
+		items[i] = item.Property
+		if item.Desc {
+			items[i] += " desc"
+		}
+	}
+	return []byte(strings.Join(items, ",")), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (o *OrderBy) UnmarshalText(text []byte) error {
+	orderBy := OrderBy{}
+	for _, item := range strings.Split(string(text), ",") {
+		fields := strings.Fields(item)
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) > 2:
+			return fmt.Errorf("invalid $orderby item %q", item)
+		}
+
+		obi := OrderByItem{Property: fields[0]}
+		if len(fields) == 2 {
+			switch strings.ToLower(fields[1]) {
+			case "asc":
+			case "desc":
+				obi.Desc = true
+			default:
+				return fmt.Errorf("invalid $orderby direction %q", fields[1])
+			}
+		}
+		orderBy = append(orderBy, obi)
+	}
+	*o = orderBy
+	return nil
+}
+
+// Select is the $select option, decoded from comma-separated property paths,
+// such as "id,name,address/city".
+type Select []string
+
+// Has reports whether property is selected. All properties are selected if s
+// is empty, or if s holds the "*" wildcard.
+func (s Select) Has(property string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for _, p := range s {
+		if p == property || p == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s Select) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(s, ",")), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (s *Select) UnmarshalText(text []byte) error {
+	sel := Select{}
+	for _, p := range strings.Split(string(text), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			sel = append(sel, p)
+		}
+	}
+	*s = sel
+	return nil
+}
@@ -0,0 +1,153 @@
+package odata_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+	"github.com/nahojer/urlvalues/odata"
+)
+
+func TestQuery(t *testing.T) {
+	top := 10
+	tests := []struct {
+		name string
+		in   url.Values
+		want odata.Query
+	}{
+		{"empty", url.Values{}, odata.Query{}},
+		{
+			name: "all options",
+			in: url.Values{
+				"$top":     {"10"},
+				"$skip":    {"20"},
+				"$orderby": {"name desc, address/city,id asc"},
+				"$select":  {"id, name"},
+				"$count":   {"true"},
+				"$filter":  {"price lt 10"},
+			},
+			want: odata.Query{
+				Top:     &top,
+				Skip:    20,
+				OrderBy: odata.OrderBy{{Property: "name", Desc: true}, {Property: "address/city"}, {Property: "id"}},
+				Select:  odata.Select{"id", "name"},
+				Count:   true,
+				Filter: odata.Filter{Expr: &odata.BinaryExpr{
+					Op:    "lt",
+					Left:  odata.Property("price"),
+					Right: odata.Literal{Value: int64(10)},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got odata.Query
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []url.Values{{"$top": {"-1"}}, {"$orderby": {"name sideways"}}, {"$filter": {"price lt"}}} {
+		t.Run("invalid", func(t *testing.T) {
+			var q odata.Query
+			if err := urlvalues.Unmarshal(in, &q); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &q)
+			}
+		})
+	}
+
+	t.Run("marshal", func(t *testing.T) {
+		in := url.Values{"$orderby": {"name desc,id"}, "$select": {"id,name"}, "$filter": {"((price lt 10) and not contains(name,'O''Neil'))"}}
+		var q odata.Query
+		if err := urlvalues.Unmarshal(in, &q); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &q, err)
+		}
+		got, err := urlvalues.Marshal(q)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", q, err)
+		}
+		if diff := cmp.Diff(got, in); diff != "" {
+			t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+		}
+	})
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want odata.Expr
+	}{
+		{
+			in: "a eq 1 or b eq 2 and c eq 3",
+			want: &odata.BinaryExpr{
+				Op:   "or",
+				Left: &odata.BinaryExpr{Op: "eq", Left: odata.Property("a"), Right: odata.Literal{Value: int64(1)}},
+				Right: &odata.BinaryExpr{
+					Op:    "and",
+					Left:  &odata.BinaryExpr{Op: "eq", Left: odata.Property("b"), Right: odata.Literal{Value: int64(2)}},
+					Right: &odata.BinaryExpr{Op: "eq", Left: odata.Property("c"), Right: odata.Literal{Value: int64(3)}},
+				},
+			},
+		},
+		{
+			in: "(a eq 1 or b eq 2) and not active",
+			want: &odata.BinaryExpr{
+				Op: "and",
+				Left: &odata.BinaryExpr{
+					Op:    "or",
+					Left:  &odata.BinaryExpr{Op: "eq", Left: odata.Property("a"), Right: odata.Literal{Value: int64(1)}},
+					Right: &odata.BinaryExpr{Op: "eq", Left: odata.Property("b"), Right: odata.Literal{Value: int64(2)}},
+				},
+				Right: &odata.NotExpr{Expr: odata.Property("active")},
+			},
+		},
+		{
+			in:   "startswith(address/city,'New')",
+			want: &odata.CallExpr{Func: "startswith", Args: []odata.Expr{odata.Property("address/city"), odata.Literal{Value: "New"}}},
+		},
+		{
+			in:   "price ge -1.5 and deleted eq null and active ne false",
+			want: &odata.BinaryExpr{Op: "and", Left: &odata.BinaryExpr{Op: "and", Left: &odata.BinaryExpr{Op: "ge", Left: odata.Property("price"), Right: odata.Literal{Value: -1.5}}, Right: &odata.BinaryExpr{Op: "eq", Left: odata.Property("deleted"), Right: odata.Literal{}}}, Right: &odata.BinaryExpr{Op: "ne", Left: odata.Property("active"), Right: odata.Literal{Value: false}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := odata.ParseFilter(tt.in)
+			if err != nil {
+				t.Fatalf("odata.ParseFilter(%q) = %q, want <nil>", tt.in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("odata.ParseFilter(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []string{"a eq", "(a eq 1", "name eq 'x", "contains(name)", "a eq 1 b", "and", "a # 1"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if _, err := odata.ParseFilter(in); err == nil {
+				t.Errorf("odata.ParseFilter(%q) = <nil>, want error", in)
+			}
+		})
+	}
+
+	t.Run("nesting", func(t *testing.T) {
+		nested := func(n int, open, close string) string {
+			return strings.Repeat(open, n) + "a eq 1" + strings.Repeat(close, n)
+		}
+		if _, err := odata.ParseFilter(nested(50, "(", ")")); err != nil {
+			t.Errorf("odata.ParseFilter(<50 nested groups>) = %q, want <nil>", err)
+		}
+		for _, in := range []string{nested(1_000_000, "(", ")"), nested(1_000_000, "not ", ""), nested(1_000_000, "contains(", ",'x')")} {
+			if _, err := odata.ParseFilter(in); err == nil {
+				t.Errorf("odata.ParseFilter(%.20q...) = <nil>, want error", in)
+			}
+		}
+	})
+}
@@ -0,0 +1,264 @@
+// Package openapi generates OpenAPI 3 parameter objects from structs tagged
+// for decoding by [urlvalues.Unmarshal], so that API documentation can't
+// drift from how parameters are actually decoded.
+package openapi
+
+import (
+	"encoding"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nahojer/urlvalues"
+)
+
+// Parameter is an OpenAPI 3 parameter object.
+//
+// See https://spec.openapis.org/oas/v3.0.3#parameter-object.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+	Style       string  `json:"style,omitempty"`
+	Explode     *bool   `json:"explode,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+	Example     any     `json:"example,omitempty"`
+}
+
+// Schema is the subset of an OpenAPI 3 schema object describing parameters.
+//
+// See https://spec.openapis.org/oas/v3.0.3#schema-object.
+type Schema struct {
+	Type                 string   `json:"type,omitempty"`
+	Format               string   `json:"format,omitempty"`
+	Items                *Schema  `json:"items,omitempty"`
+	AdditionalProperties *Schema  `json:"additionalProperties,omitempty"`
+	Default              any      `json:"default,omitempty"`
+	Enum                 []any    `json:"enum,omitempty"`
+	Minimum              *float64 `json:"minimum,omitempty"`
+	Maximum              *float64 `json:"maximum,omitempty"`
+	MinItems             *int     `json:"minItems,omitempty"`
+	MaxItems             *int     `json:"maxItems,omitempty"`
+	Pattern              string   `json:"pattern,omitempty"`
+}
+
+// Parameters returns the OpenAPI 3 parameters of the struct v, or struct
+// pointer v, as described by [urlvalues.Describe]. Fields decoded from URL
+// values are query parameters, while fields with a path, header or cookie
+// source are parameters in the path, headers and cookies respectively. Fields
+// with a form source and file uploads are part of the request body rather
+// than parameters, and are omitted.
+//
+// Aliases and deprecated keys of a field are returned as parameters of their
+// own, following the parameter of the field's key, with deprecated keys marked
+// as such.
+//
+// Options are passed on to [urlvalues.Describe], and the delimiter set by
+// passing the [urlvalues.WithDelimiter] [urlvalues.SetParseOptionFunc] is used
+// to split default values of slices and maps.
+func Parameters(v any, setParseOpts ...urlvalues.SetParseOptionFunc) ([]Parameter, error) {
+	infos, err := urlvalues.Describe(v, setParseOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var pOpts urlvalues.ParseOptions
+	for _, f := range setParseOpts {
+		f(&pOpts)
+	}
+
+	var params []Parameter
+	for _, info := range infos {
+		in, ok := location(info)
+		if !ok {
+			continue
+		}
+
+		param := Parameter{
+			Name:     info.Key,
+			In:       in,
+			Required: info.Required || in == "path",
+			Schema:   schemaOf(info, pOpts.Delim()),
+		}
+		if info.Example != "" {
+			param.Example = valueOf(param.Schema, info.Example, pOpts.Delim())
+		}
+		if param.Schema.Type == "array" && in == "query" {
+			// Repeated keys decode into separate elements.
+			explode := true
+			param.Style, param.Explode = "form", &explode
+		}
+		params = append(params, param)
+
+		for _, alias := range info.Aliases {
+			aliasParam := param
+			aliasParam.Name = alias
+			aliasParam.Description = "Alias of " + info.Key + "."
+			aliasParam.Required = false
+			params = append(params, aliasParam)
+		}
+		for _, key := range info.Deprecated {
+			deprecatedParam := param
+			deprecatedParam.Name = key
+			deprecatedParam.Description = "Deprecated, use " + info.Key + " instead."
+			deprecatedParam.Required = false
+			deprecatedParam.Deprecated = true
+			params = append(params, deprecatedParam)
+		}
+	}
+
+	return params, nil
+}
+
+// location returns the location of the parameter described by info, and
+// whether it is a parameter at all.
+func location(info urlvalues.ParamInfo) (string, bool) {
+	switch info.Source {
+	case "":
+		if isFile(info.Type) {
+			return "", false
+		}
+		return "query", true
+	case "path", "header", "cookie":
+		return info.Source, true
+	default:
+		return "", false
+	}
+}
+
+var (
+	timeType              = reflect.TypeFor[time.Time]()
+	durationType          = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType   = reflect.TypeFor[encoding.TextUnmarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// isFile reports whether typ is the type of a file upload field.
+func isFile(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ.PkgPath() == "mime/multipart" && typ.Name() == "FileHeader"
+}
+
+// schemaOf returns the schema of the field described by info. Values of slices
+// and maps in its default value are separated by delim.
+func schemaOf(info urlvalues.ParamInfo, delim string) *Schema {
+	schema := typeSchema(info.Type, info.Layout)
+
+	// Constraints on values apply to the elements of slices.
+	valueSchema := schema
+	if schema.Type == "array" {
+		valueSchema = schema.Items
+	}
+	if info.Min != nil {
+		valueSchema.Minimum = info.Min
+	}
+	valueSchema.Maximum = info.Max
+	valueSchema.Pattern = info.Pattern
+	for _, option := range info.OneOf {
+		valueSchema.Enum = append(valueSchema.Enum, valueOf(valueSchema, option, delim))
+	}
+
+	schema.MinItems = info.MinItems
+	schema.MaxItems = info.MaxItems
+	if info.Default != "" {
+		schema.Default = valueOf(schema, info.Default, delim)
+	}
+
+	return schema
+}
+
+// typeSchema returns the schema of values of type typ, with time.Time values
+// parsed using layout.
+func typeSchema(typ reflect.Type, layout string) *Schema {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == timeType:
+		return &Schema{Type: "string", Format: timeFormat(layout)}
+	case typ == durationType:
+		return &Schema{Type: "string"}
+	case typ.Implements(textUnmarshalerType), reflect.PointerTo(typ).Implements(textUnmarshalerType),
+		typ.Implements(binaryUnmarshalerType), reflect.PointerTo(typ).Implements(binaryUnmarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32", Minimum: new(float64)}
+	case reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64", Minimum: new(float64)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: typeSchema(typ.Elem(), layout)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(typ.Elem(), layout)}
+	default:
+		return &Schema{Type: "string"}
+	}
+}
+
+// timeFormat returns the format of time values parsed using layout, or the
+// empty string if there is no matching format.
+func timeFormat(layout string) string {
+	switch layout {
+	case "RFC3339", "RFC3339Nano", time.RFC3339, time.RFC3339Nano:
+		return "date-time"
+	case time.DateOnly:
+		return "date"
+	default:
+		return ""
+	}
+}
+
+// valueOf returns value converted to the type of schema, or value itself if it
+// can't be converted, such as for "now" based time values. Values of arrays
+// and objects are separated by delim.
+func valueOf(schema *Schema, value, delim string) any {
+	switch schema.Type {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "integer":
+		if n, err := strconv.ParseInt(value, 0, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "array":
+		var items []any
+		for _, item := range strings.Split(value, delim) {
+			items = append(items, valueOf(schema.Items, item, delim))
+		}
+		return items
+	case "object":
+		items := make(map[string]any)
+		for _, pair := range strings.Split(value, delim) {
+			k, v, ok := strings.Cut(pair, ":")
+			if !ok {
+				return value
+			}
+			items[k] = valueOf(schema.AdditionalProperties, v, delim)
+		}
+		return items
+	}
+	return value
+}
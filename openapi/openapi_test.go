@@ -0,0 +1,77 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+	"github.com/nahojer/urlvalues/openapi"
+)
+
+func TestParameters(t *testing.T) {
+	type Target struct {
+		IDs    []uint                `urlvalue:"ids,minitems:1,default:1|2"`
+		Size   int                   `urlvalue:"size,default:20,min:1,max:100,example:50"`
+		Status string                `urlvalue:"status,alias:state,deprecated:st,oneof:open|closed"`
+		Since  time.Time             `urlvalue:"since,layout:2006-01-02"`
+		Labels map[string]bool       `urlvalue:"labels,default:a:true"`
+		ID     string                `urlvalue:"id,source:path"`
+		Token  string                `urlvalue:"X-Token,source:header"`
+		Name   string                `urlvalue:"name,source:form"`
+		File   *multipart.FileHeader `urlvalue:"file"`
+	}
+
+	got, err := openapi.Parameters(Target{}, urlvalues.WithDelimiter("|"))
+	if err != nil {
+		t.Fatalf("openapi.Parameters(...) = %q, want <nil>", err)
+	}
+
+	want := `[
+		{"name": "ids", "in": "query", "style": "form", "explode": true,
+		 "schema": {"type": "array", "items": {"type": "integer", "format": "int64", "minimum": 0}, "default": [1, 2], "minItems": 1}},
+		{"name": "size", "in": "query", "example": 50,
+		 "schema": {"type": "integer", "format": "int64", "default": 20, "minimum": 1, "maximum": 100}},
+		{"name": "status", "in": "query",
+		 "schema": {"type": "string", "enum": ["open", "closed"]}},
+		{"name": "state", "in": "query", "description": "Alias of status.",
+		 "schema": {"type": "string", "enum": ["open", "closed"]}},
+		{"name": "st", "in": "query", "description": "Deprecated, use status instead.", "deprecated": true,
+		 "schema": {"type": "string", "enum": ["open", "closed"]}},
+		{"name": "since", "in": "query",
+		 "schema": {"type": "string", "format": "date"}},
+		{"name": "labels", "in": "query",
+		 "schema": {"type": "object", "additionalProperties": {"type": "boolean"}, "default": {"a": true}}},
+		{"name": "id", "in": "path", "required": true,
+		 "schema": {"type": "string"}},
+		{"name": "X-Token", "in": "header",
+		 "schema": {"type": "string"}}
+	]`
+
+	if diff := cmp.Diff(jsonValue(t, got), jsonValue(t, want)); diff != "" {
+		t.Errorf("openapi.Parameters(...) -got +want\n%s", diff)
+	}
+}
+
+// jsonValue returns v, or the JSON document v if it is a string, decoded into
+// generic JSON values.
+func jsonValue(t *testing.T, v any) any {
+	t.Helper()
+
+	data, ok := v.(string)
+	if !ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = string(b)
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
@@ -0,0 +1,23 @@
+package urlvalues
+
+import "reflect"
+
+// operandDecoder is implemented by pointers to types composed of operands,
+// such as Filter and Range. Rather than implementing
+// encoding.TextUnmarshaler, they decode their operands by parse, like fields
+// of the types of the operands, so that the options of their fields apply to
+// the operands.
+type operandDecoder interface {
+	decodeOperands(value string, parse func(value string, dst reflect.Value) error) error
+}
+
+// operandEncoder is implemented by types composed of operands, the inverse of
+// operandDecoder.
+type operandEncoder interface {
+	encodeOperands(format func(v reflect.Value) (string, error)) (string, error)
+}
+
+func operandDecoderOf(field reflect.Value) operandDecoder {
+	impl := implementationsOf(field.Type())
+	return interfaceFrom[operandDecoder](field, false, impl.operandsPtr)
+}
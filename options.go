@@ -1,5 +1,11 @@
 package urlvalues
 
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
 // SetParseOptionFunc allows for overriding the parsing behaviour of URL values.
 type SetParseOptionFunc func(*ParseOptions)
 
@@ -8,6 +14,367 @@ type SetParseOptionFunc func(*ParseOptions)
 func WithDelimiter(s string) SetParseOptionFunc {
 	return func(o *ParseOptions) {
 		o.delim = &s
+		o.extraDelims = nil
+	}
+}
+
+// WithDelimiters returns a SetParseOptionFunc that sets multiple delimiters
+// that slices and maps are split by when decoding, such as both ";" and ","
+// while migrating from one to the other. The first delimiter is used to
+// convert slices and maps into their string representation, like the
+// delimiter set by [WithDelimiter]. Empty delimiters are ignored.
+func WithDelimiters(delims ...string) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		var nonEmpty []string
+		for _, d := range delims {
+			if d != "" {
+				nonEmpty = append(nonEmpty, d)
+			}
+		}
+		if len(nonEmpty) == 0 {
+			o.delim, o.extraDelims = nil, nil
+			return
+		}
+		o.delim = &nonEmpty[0]
+		o.extraDelims = nonEmpty[1:]
+	}
+}
+
+// Precedence decides which values are used by [UnmarshalRequest] when a key is
+// present in both the URL query and the form body of a request, or whether
+// only one of them is used at all.
+type Precedence int
+
+const (
+	// FormPrecedence lets values in the form body take precedence over values
+	// in the URL query. This mirrors the behaviour of [http.Request.FormValue].
+	FormPrecedence Precedence = iota
+	// QueryPrecedence lets values in the URL query take precedence over values
+	// in the form body.
+	QueryPrecedence
+	// QueryOnly uses values in the URL query only, ignoring the form body.
+	QueryOnly
+	// FormOnly uses values in the form body only, ignoring the URL query. This
+	// mirrors the behaviour of [http.Request.PostFormValue].
+	FormOnly
+)
+
+// WithPrecedence returns a SetParseOptionFunc that sets the precedence used by
+// [UnmarshalRequest] when merging the URL query and form body of a request, or
+// restricts decoding to one of them. Fields with the "source" tag option are
+// decoded from their source regardless.
+func WithPrecedence(p Precedence) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.precedence = p
+	}
+}
+
+// WithMaxMemory returns a SetParseOptionFunc that sets the maximum number of
+// bytes of a multipart form that [UnmarshalRequest] stores in memory. The
+// remainder of the form is stored on disk in temporary files.
+func WithMaxMemory(n int64) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.maxMemory = n
+	}
+}
+
+// WithMaxBodySize returns a SetParseOptionFunc that sets the maximum number of
+// bytes of a request body that [UnmarshalRequest] and [Bind] read when parsing
+// the form. Larger bodies result in an [ErrInvalidForm] error wrapping an
+// [http.MaxBytesError]. A negative n lifts the limit, which should only be done
+// if the body is limited otherwise, such as by [http.MaxBytesReader].
+func WithMaxBodySize(n int64) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.maxBodySize = n
+	}
+}
+
+// WithErrorWriter returns a SetParseOptionFunc that sets the function used by
+// [Handler] and [Middleware] to respond to requests that failed to be decoded.
+func WithErrorWriter(fn ErrorWriterFunc) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.errorWriter = fn
+	}
+}
+
+// WithMessages returns a SetParseOptionFunc that sets the templates used to
+// render the messages of [ParseError] values. Errors with codes missing a
+// template use the template of [CodeInvalid], or the default message if that
+// is missing as well.
+func WithMessages(m Messages) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.messages = m
+	}
+}
+
+// WithErrorFormatter returns a SetParseOptionFunc that sets the function
+// rendering the messages of [ParseError] values, for full control over their
+// wording, such as whether to echo the offending value. It takes precedence
+// over the templates set by [WithMessages].
+func WithErrorFormatter(fn ErrorFormatterFunc) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.errorFormatter = fn
+	}
+}
+
+// WithWarningHandler returns a SetParseOptionFunc that sets the function that
+// handles warnings emitted while decoding. Warnings are discarded if not set.
+func WithWarningHandler(fn WarningHandlerFunc) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.warningHandler = fn
+	}
+}
+
+// WithTraceFunc returns a SetParseOptionFunc that sets the function that
+// handles trace events emitted while decoding, such as a field being decoded
+// from a key or a key being unused. Tracing is meant for diagnosing why a
+// value didn't end up in the expected field, and is disabled if not set.
+func WithTraceFunc(fn TraceFunc) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.traceFunc = fn
+	}
+}
+
+// WithMetrics returns a SetParseOptionFunc that sets the [Metrics] notified
+// when values start and end being decoded, for monitoring decoding latency and
+// failures.
+func WithMetrics(m Metrics) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.metrics = m
+	}
+}
+
+// ValueTransformerFunc returns value, found at key, transformed before it is
+// parsed.
+type ValueTransformerFunc func(key, value string) string
+
+// WithValueTransformer returns a SetParseOptionFunc that sets the function that
+// transforms each value before it is parsed, such as by trimming whitespace or
+// normalizing case. The function is called with the key the value was found
+// at, and is not applied to default values.
+func WithValueTransformer(fn ValueTransformerFunc) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.valueTransformer = fn
+	}
+}
+
+// WithRejectControlChars returns a SetParseOptionFunc that rejects URL values
+// containing control characters, including tabs and line breaks, or invalid
+// UTF-8 with an [InvalidCharError]. This hardens values that end up in logs
+// and headers.
+func WithRejectControlChars() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.rejectControlChars = true
+	}
+}
+
+// WithStrictNumbers returns a SetParseOptionFunc that parses integer fields as
+// base 10 numbers only. By default, the base is implied by the prefix of the
+// value as in Go syntax, so that "0x1F", "0o17", "0755" and "1_000" are
+// accepted as well.
+func WithStrictNumbers() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.strictNumbers = true
+	}
+}
+
+// WithFiniteFloats returns a SetParseOptionFunc that rejects the values "NaN",
+// "Inf" and their variants for all float fields, as if the fields had the
+// "finite" tag option.
+func WithFiniteFloats() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.finiteFloats = true
+	}
+}
+
+// WithAutoTimeLayout returns a SetParseOptionFunc that makes time.Time fields
+// without a "layout" option use the "Auto" layout instead of [time.Layout].
+// The "Auto" layout decodes values in the first matching layout of
+// [time.RFC3339], [time.RFC3339Nano], [time.DateOnly] and [time.DateTime], and
+// encodes values in the [time.RFC3339Nano] layout.
+func WithAutoTimeLayout() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.autoTimeLayout = true
+	}
+}
+
+// WithUnescape returns a SetParseOptionFunc that unescapes all URL values using
+// [url.QueryUnescape] before they are parsed, as if all fields had the
+// "unescape" tag option. This decodes values that were encoded twice, such as
+// by upstream proxies.
+func WithUnescape() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.unescape = true
+	}
+}
+
+// WithPlusAsSpace returns a SetParseOptionFunc that replaces plus signs (+)
+// with spaces in values decoded into string fields, including the elements of
+// slices and maps of strings. This suits URL values built by other means than
+// parsing a query string, such as by hand, in which plus signs may still mean
+// spaces. Default values are left as is.
+func WithPlusAsSpace() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.plusAsSpace = true
+	}
+}
+
+// WithSplitRepeated returns a SetParseOptionFunc that splits each value of a
+// key present multiple times by the delimiter, so that "?tag=a;b&tag=c" is
+// decoded into a slice field as "a", "b" and "c". By default, each value of a
+// repeated key is decoded as a single element as is. This allows for clients
+// sending either shape.
+func WithSplitRepeated() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.splitRepeated = true
+	}
+}
+
+// WithAllErrors returns a SetParseOptionFunc that makes decoding continue past
+// fields that fail to be decoded, returning all of their [ParseError] values
+// together as [Errors] instead of only the first. This allows clients to fix
+// all of their parameters at once.
+func WithAllErrors() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.allErrors = true
+	}
+}
+
+// WithMaxErrors returns a SetParseOptionFunc that limits the number of
+// [ParseError] values returned when errors are aggregated by [WithAllErrors]
+// to n. Any further errors are counted by a single [TooManyErrorsError]
+// following the first n errors, so that huge inputs can't result in huge
+// error responses. Not set or set to a non-positive value means no limit.
+func WithMaxErrors(n int) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.maxErrors = n
+	}
+}
+
+// WithPrefix returns a SetParseOptionFunc that restricts decoding to keys
+// starting with prefix. The prefix is prepended to the key of each field
+// before looking up its values, so that a field with key "status" is decoded
+// from "filter.status" given the prefix "filter.". This allows multiple struct
+// values to decode different sections of the same URL values.
+func WithPrefix(prefix string) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.prefix = prefix
+	}
+}
+
+// WithMaxSliceLen returns a SetParseOptionFunc that limits the number of values
+// that slice and map fields may receive, and the number of items of slices of
+// structs. Exceeding the limit results in a [TooManyValuesError]. The limit of individual fields can be overridden using
+// the "maxslicelen" tag option. There is no limit if not set or set to zero.
+func WithMaxSliceLen(n int) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.maxSliceLen = n
+	}
+}
+
+// WithMaxValueLen returns a SetParseOptionFunc that limits the length in bytes
+// of individual URL values, as received before any unescaping or transforming.
+// Exceeding the limit results in a [ValueTooLongError]. There is no limit if
+// not set or set to zero.
+func WithMaxValueLen(n int) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.maxValueLen = n
+	}
+}
+
+// WithMaxTotalSize returns a SetParseOptionFunc that limits the total length in
+// bytes of all URL values decoded by a single call, such as a call to
+// [Unmarshal]. Exceeding the limit results in a [TooLargeError]. There is no
+// limit if not set or set to zero.
+func WithMaxTotalSize(n int) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.maxTotalSize = n
+	}
+}
+
+// WithMultiplePolicy returns a SetParseOptionFunc that sets how fields holding a
+// single value are decoded from keys with multiple values. Defaults to
+// [JoinMultiple] if not set.
+func WithMultiplePolicy(p MultiplePolicy) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.multiplePolicy = p
+	}
+}
+
+// WithDedupeSlices returns a SetParseOptionFunc that removes repeated values
+// from all slice fields, keeping the first occurrence of each value. Values
+// are compared before being parsed. Individual fields can be deduplicated
+// using the "dedupe" tag option instead.
+func WithDedupeSlices() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.dedupeSlices = true
+	}
+}
+
+// WithKeyStyle returns a SetParseOptionFunc that sets how the keys of fields of
+// nested structs are composed, both when decoding and encoding. Defaults to
+// [FlatKeys] if not set.
+func WithKeyStyle(s KeyStyle) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.keyStyle = s
+	}
+}
+
+// WithKeyNaming returns a SetParseOptionFunc that sets how the keys of fields
+// are derived from their field names when their tags give no key, both when
+// decoding and encoding, such that PageSize is keyed "page_size" by
+// [SnakeCase]. Defaults to [FieldNames] if not set. The naming applies before
+// keys are composed according to [WithKeyStyle].
+func WithKeyNaming(n KeyNaming) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.keyNaming = n
+	}
+}
+
+// WithUnixTime returns a SetParseOptionFunc that encodes time.Time values as
+// the number of seconds since the Unix epoch, and decodes them from it, instead
+// of using the layouts of the fields. The seconds may have a fractional part,
+// such as "1700000000.123". "now" based values are still decoded.
+func WithUnixTime() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.unixTime = true
+	}
+}
+
+// WithOmitDefaults returns a SetParseOptionFunc that makes [Marshal] and
+// [MarshalInto] omit fields with values equal to their default values, which
+// results in minimal URL values that decode into the same struct.
+func WithOmitDefaults() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.omitDefaults = true
+	}
+}
+
+// WithSeparators returns a SetParseOptionFunc that sets the characters that
+// separate key-value pairs of query strings parsed by [ParseQuery] and
+// [UnmarshalQuery]. For example, "&;" separates pairs by both ampersands and
+// semicolons. Defaults to "&" if not set or set to the empty string.
+func WithSeparators(seps string) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.separators = seps
+	}
+}
+
+// WithLenientQuery returns a SetParseOptionFunc that makes [ParseQuery] and
+// [UnmarshalQuery] keep the raw keys and values of pairs that fail to be
+// unescaped, instead of failing.
+func WithLenientQuery() SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.lenientQuery = true
+	}
+}
+
+// WithTagName returns a SetParseOptionFunc that sets the key of struct tags
+// holding the names and options of fields. Defaults to "urlvalue" if not set
+// or set to the empty string.
+func WithTagName(name string) SetParseOptionFunc {
+	return func(o *ParseOptions) {
+		o.tagName = name
 	}
 }
 
@@ -17,6 +384,87 @@ type ParseOptions struct {
 	// Delimiter used to convert slices and maps from and into their string
 	// representaton.
 	delim *string
+	// Delimiters that slices and maps are split by in addition to delim.
+	extraDelims []string
+	// Precedence used when merging URL query and form body values.
+	precedence Precedence
+	// Maximum number of bytes of multipart forms stored in memory.
+	maxMemory int64
+	// Maximum number of bytes of request bodies read when parsing forms.
+	maxBodySize int64
+	// Writes responses to requests that failed to be decoded.
+	errorWriter ErrorWriterFunc
+	// Templates of ParseError messages.
+	messages Messages
+	// Renders ParseError messages.
+	errorFormatter ErrorFormatterFunc
+	// Handles non-fatal events.
+	warningHandler WarningHandlerFunc
+	// Handles trace events.
+	traceFunc TraceFunc
+	// Instrumentation of decoding.
+	metrics Metrics
+	// Transforms values before they are parsed.
+	valueTransformer ValueTransformerFunc
+	// Whether values containing control characters or invalid UTF-8 are
+	// rejected.
+	rejectControlChars bool
+	// Whether integers are parsed as base 10 numbers only.
+	strictNumbers bool
+	// Whether float fields reject NaN and infinite values.
+	finiteFloats bool
+	// Whether time.Time fields without a layout auto-detect layouts.
+	autoTimeLayout bool
+	// Whether values are unescaped before they are parsed.
+	unescape bool
+	// Whether plus signs in values of string fields mean spaces.
+	plusAsSpace bool
+	// Whether each value of repeated keys is split by the delimiter.
+	splitRepeated bool
+	// Prefix of all keys into URL values.
+	prefix string
+	// Maximum number of values of slices and maps.
+	maxSliceLen int
+	// Maximum length of individual values.
+	maxValueLen int
+	// Maximum total length of all values decoded by a single call.
+	maxTotalSize int
+	// Total length of all values decoded so far by the current call. Shared
+	// by copies of the options made during the call.
+	processed *int
+	// Number of struct fields decoded so far by the current call. Shared by
+	// copies of the options made during the call.
+	decoded *int
+	// Whether decoding continues past fields that fail to be decoded.
+	allErrors bool
+	// Maximum number of ParseErrors returned when allErrors is set.
+	maxErrors int
+	// ParseErrors of the current call, if allErrors is set. Shared by copies
+	// of the options made during the call.
+	errorCount *errorCount
+	// How fields holding a single value treat multiple values.
+	multiplePolicy MultiplePolicy
+	// Whether to remove repeated values from slices.
+	dedupeSlices bool
+	// How keys of fields of nested structs are composed.
+	keyStyle KeyStyle
+	// How keys of fields are derived from their field names.
+	keyNaming KeyNaming
+	// Whether time.Time values are seconds since the Unix epoch.
+	unixTime bool
+	// Whether fields with values equal to their defaults are omitted when
+	// encoding.
+	omitDefaults bool
+	// Characters separating key-value pairs of query strings.
+	separators string
+	// Whether pairs of query strings failing to be unescaped are kept raw.
+	lenientQuery bool
+	// Key of struct tags.
+	tagName string
+	// Context of the current call, see UnmarshalContext.
+	ctx context.Context
+	// Validators by name, registered by Decoder.RegisterValidation.
+	validators map[string]ValidatorFunc
 }
 
 // Delim returns the delimiter used to convert slices and maps from and into
@@ -28,3 +476,111 @@ func (o *ParseOptions) Delim() string {
 	}
 	return ";"
 }
+
+// split splits s by the delimiter and any additional delimiters.
+func (o *ParseOptions) split(s string) []string {
+	delim := o.Delim()
+	if len(o.extraDelims) > 0 {
+		oldnew := make([]string, 0, 2*len(o.extraDelims))
+		for _, d := range o.extraDelims {
+			oldnew = append(oldnew, d, delim)
+		}
+		s = strings.NewReplacer(oldnew...).Replace(s)
+	}
+	return strings.Split(s, delim)
+}
+
+// countItems returns the number of items that s is split into by split,
+// without splitting it.
+func (o *ParseOptions) countItems(s string) int {
+	n := strings.Count(s, o.Delim()) + 1
+	for _, d := range o.extraDelims {
+		n += strings.Count(s, d)
+	}
+	return n
+}
+
+// MaxMemory returns the maximum number of bytes of a multipart form that are
+// stored in memory. Defaults to 32 MB if not set or set to a non-positive
+// value.
+func (o *ParseOptions) MaxMemory() int64 {
+	if o.maxMemory > 0 {
+		return o.maxMemory
+	}
+	return 32 << 20
+}
+
+// MaxBodySize returns the maximum number of bytes of a request body read when
+// parsing the form, or a negative number if there is no limit. Defaults to 64
+// MB if not set or set to zero.
+func (o *ParseOptions) MaxBodySize() int64 {
+	if o.maxBodySize != 0 {
+		return o.maxBodySize
+	}
+	return 64 << 20
+}
+
+// TagName returns the key of struct tags holding the names and options of
+// fields. Defaults to "urlvalue" if not set or set to the empty string.
+func (o *ParseOptions) TagName() string {
+	if o.tagName != "" {
+		return o.tagName
+	}
+	return "urlvalue"
+}
+
+// intBase returns the base that integers are parsed in, where 0 implies the
+// base from the prefix of the value.
+func (o *ParseOptions) intBase() int {
+	if o.strictNumbers {
+		return 10
+	}
+	return 0
+}
+
+// context returns the context of the current call. Defaults to
+// [context.Background] if not set.
+func (o *ParseOptions) context() context.Context {
+	if o.ctx != nil {
+		return o.ctx
+	}
+	return context.Background()
+}
+
+// ErrorWriter returns the function used to respond to requests that failed to
+// be decoded. Defaults to [WriteError] if not set.
+func (o *ParseOptions) ErrorWriter() ErrorWriterFunc {
+	if o.errorWriter != nil {
+		return o.errorWriter
+	}
+	return WriteError
+}
+
+// OptionsProvider is implemented by struct types that declare options of their
+// own, so that the options don't have to be passed at every call site. When
+// the value passed to a function such as [Unmarshal] or [Marshal] implements
+// OptionsProvider, its options are applied before the options passed to the
+// function, which thereby take precedence.
+type OptionsProvider interface {
+	URLValuesOptions() []SetParseOptionFunc
+}
+
+// newParseOptionsFor returns the options of v, if it implements
+// OptionsProvider, followed by setParseOpts.
+func newParseOptionsFor(v any, setParseOpts []SetParseOptionFunc) *ParseOptions {
+	if p, ok := v.(OptionsProvider); ok {
+		setParseOpts = append(slices.Clip(p.URLValuesOptions()), setParseOpts...)
+	}
+	return newParseOptions(setParseOpts)
+}
+
+func newParseOptions(setParseOpts []SetParseOptionFunc) *ParseOptions {
+	pOpts := &ParseOptions{processed: new(int), decoded: new(int)}
+	for _, f := range setParseOpts {
+		f(pOpts)
+	}
+	if pOpts.allErrors {
+		pOpts.errorCount = new(errorCount)
+	}
+	return pOpts
+}
@@ -0,0 +1,59 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+type Filter struct {
+	Status string `query:"status"`
+}
+
+type SearchParams struct {
+	Tags   []string `query:"tags"`
+	Filter Filter   `query:"filter"`
+}
+
+func (SearchParams) URLValuesOptions() []urlvalues.SetParseOptionFunc {
+	return []urlvalues.SetParseOptionFunc{
+		urlvalues.WithTagName("query"),
+		urlvalues.WithDelimiter("|"),
+		urlvalues.WithKeyStyle(urlvalues.DotKeys),
+	}
+}
+
+func TestOptionsProvider(t *testing.T) {
+	in := url.Values{"tags": {"a|b"}, "filter.status": {"open"}}
+	want := SearchParams{Tags: []string{"a", "b"}, Filter: Filter{Status: "open"}}
+
+	var got SearchParams
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	encoded, err := urlvalues.Marshal(got)
+	if err != nil {
+		t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", got, err)
+	}
+	if diff := cmp.Diff(encoded, url.Values{"tags": {"a", "b"}, "filter.status": {"open"}}); diff != "" {
+		t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", got, diff)
+	}
+
+	t.Run("call site takes precedence", func(t *testing.T) {
+		in := url.Values{"tags": {"a;b"}, "filter[status]": {"open"}}
+		var got SearchParams
+		err := urlvalues.Unmarshal(in, &got, urlvalues.WithDelimiter(";"), urlvalues.WithKeyStyle(urlvalues.BracketKeys))
+		if err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package urlvalues
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"net/url"
+)
+
+// Page holds page-based pagination parameters. Embed it in a struct to decode
+// the "page" and "per_page" keys, such as in "?page=3&per_page=50". Page
+// numbers start at 1 and default to 1, while the number of items per page
+// defaults to 20 and is at most 100.
+type Page struct {
+	// Number of the page, starting at 1.
+	Number int `urlvalue:"page,default:1,min:1"`
+	// Number of items per page.
+	PerPage int `urlvalue:"per_page,default:20,min:1,max:100"`
+}
+
+// Offset returns the number of items before the page. The offset saturates at
+// [math.MaxInt] rather than overflowing for huge page numbers.
+func (p Page) Offset() int {
+	if p.Number > 1 && p.PerPage > 0 && p.Number-1 > math.MaxInt/p.PerPage {
+		return math.MaxInt
+	}
+	return (p.Number - 1) * p.PerPage
+}
+
+// Limit returns the number of items per page.
+func (p Page) Limit() int {
+	return p.PerPage
+}
+
+// Next returns the page after p.
+func (p Page) Next() Page {
+	p.Number++
+	return p
+}
+
+// Prev returns the page before p, or false if p is the first page.
+func (p Page) Prev() (Page, bool) {
+	if p.Number <= 1 {
+		return p, false
+	}
+	p.Number--
+	return p, true
+}
+
+// Cursor holds cursor-based pagination parameters. Embed it in a struct to
+// decode the "cursor" and "limit" keys, such as in "?cursor=...&limit=50".
+// The token is opaque to clients, and is empty for the first page. The limit
+// defaults to 20 and is at most 100.
+//
+// Use [NewCursor] to build the cursor of the next page from the position of
+// the last item of a page, and [Cursor.Decode] to read the position back.
+type Cursor struct {
+	// Opaque token of the position to continue from.
+	Token string `urlvalue:"cursor"`
+	// Maximum number of items per page.
+	Limit int `urlvalue:"limit,default:20,min:1,max:100"`
+}
+
+// NewCursor returns a cursor with limit items per page, continuing from the
+// position held by v, a struct or struct pointer. The position is encoded in
+// the token by [Canonical], so that any struct that can be encoded by
+// [Marshal] can act as a position.
+func NewCursor(v any, limit int) (Cursor, error) {
+	position, err := Canonical(v)
+	if err != nil {
+		return Cursor{}, err
+	}
+	return Cursor{
+		Token: base64.RawURLEncoding.EncodeToString([]byte(position)),
+		Limit: limit,
+	}, nil
+}
+
+// Decode decodes the position held by the token of c into v, the inverse of
+// [NewCursor]. v is left untouched if the token is empty.
+func (c Cursor) Decode(v any) error {
+	if c.Token == "" {
+		return nil
+	}
+	position, err := base64.RawURLEncoding.DecodeString(c.Token)
+	if err != nil {
+		return fmt.Errorf("urlvalues: invalid cursor: %w", err)
+	}
+	return UnmarshalQuery(string(position), v)
+}
+
+// PageLink returns a link to the page of results described by v, a struct or
+// struct pointer, for use in a Link header with the relation type rel, such as
+// `<https://example.com/items?page=3>; rel="next"`. The URL of the link is
+// u with its query replaced by the values of v, as encoded by [MarshalInto].
+// Keys of the query of u that are not keys of the fields of v are kept.
+//
+// Typically, v is the struct the request was decoded into, with the pagination
+// parameters advanced by [Page.Next] or replaced by [NewCursor].
+func PageLink(u *url.URL, rel string, v any, setParseOpts ...SetParseOptionFunc) (string, error) {
+	query := u.Query()
+	if err := MarshalInto(query, v, setParseOpts...); err != nil {
+		return "", err
+	}
+	link := *u
+	link.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=%q", link.String(), rel), nil
+}
@@ -0,0 +1,135 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestPage(t *testing.T) {
+	type Target struct {
+		urlvalues.Page
+		Query string `urlvalue:"q"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want urlvalues.Page
+	}{
+		{"defaults", url.Values{}, urlvalues.Page{Number: 1, PerPage: 20}},
+		{"given", url.Values{"page": {"3"}, "per_page": {"50"}}, urlvalues.Page{Number: 3, PerPage: 50}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			if err := urlvalues.Unmarshal(tt.in, &target); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+			}
+			if diff := cmp.Diff(target.Page, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []url.Values{{"page": {"0"}}, {"per_page": {"101"}}} {
+		t.Run("out of bounds", func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(in, &target)
+			var constrErr *urlvalues.ConstraintError
+			if !errors.As(err, &constrErr) {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ConstraintError", in, &target, err)
+			}
+		})
+	}
+
+	t.Run("navigation", func(t *testing.T) {
+		page := urlvalues.Page{Number: 3, PerPage: 10}
+		if got := page.Offset(); got != 20 {
+			t.Errorf("Offset() = %d, want 20", got)
+		}
+		if got := (urlvalues.Page{Number: math.MaxInt, PerPage: 20}).Offset(); got != math.MaxInt {
+			t.Errorf("Offset() of last possible page = %d, want %d", got, math.MaxInt)
+		}
+		if got := page.Next().Number; got != 4 {
+			t.Errorf("Next().Number = %d, want 4", got)
+		}
+		if _, ok := (urlvalues.Page{Number: 1}).Prev(); ok {
+			t.Errorf("Prev() of first page = true, want false")
+		}
+	})
+
+	t.Run("link", func(t *testing.T) {
+		u, _ := url.Parse("https://example.com/items?q=go&page=3&per_page=50&other=1")
+		next := Target{Page: urlvalues.Page{Number: 4, PerPage: 50}, Query: "go"}
+		got, err := urlvalues.PageLink(u, "next", next)
+		if err != nil {
+			t.Fatalf("urlvalues.PageLink(%v, %q, %v) = %q, want <nil>", u, "next", next, err)
+		}
+		want := `<https://example.com/items?other=1&page=4&per_page=50&q=go>; rel="next"`
+		if got != want {
+			t.Errorf("urlvalues.PageLink(...) = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestCursor(t *testing.T) {
+	type Position struct {
+		CreatedAt time.Time `urlvalue:"created_at"`
+		ID        int       `urlvalue:"id"`
+	}
+	type Target struct {
+		urlvalues.Cursor
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		var target Target
+		if err := urlvalues.Unmarshal(url.Values{}, &target); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(...) = %q, want <nil>", err)
+		}
+		if diff := cmp.Diff(target.Cursor, urlvalues.Cursor{Limit: 20}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+		var pos Position
+		if err := target.Decode(&pos); err != nil {
+			t.Fatalf("Decode(%v) = %q, want <nil>", &pos, err)
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		want := Position{CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), ID: 42}
+		cursor, err := urlvalues.NewCursor(want, 50)
+		if err != nil {
+			t.Fatalf("urlvalues.NewCursor(%v, 50) = %q, want <nil>", want, err)
+		}
+
+		in, err := urlvalues.Marshal(Target{Cursor: cursor})
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(...) = %q, want <nil>", err)
+		}
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+
+		var got Position
+		if err := target.Decode(&got); err != nil {
+			t.Fatalf("Decode(%v) = %q, want <nil>", &got, err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("Decode(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		var pos Position
+		if err := (urlvalues.Cursor{Token: "!"}).Decode(&pos); err == nil {
+			t.Errorf("Decode(%v) = <nil>, want error", &pos)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package urlvalues
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var valuesType = reflect.TypeFor[url.Values]()
+
+// passthroughKeys returns the keys among keys that start with prefix.
+func passthroughKeys(keys []string, prefix string) []string {
+	var matched []string
+	for _, k := range keys {
+		if strings.HasPrefix(k, prefix) {
+			matched = append(matched, k)
+		}
+	}
+	return matched
+}
+
+// decodePassthrough decodes field, a url.Values field with the "passthrough"
+// option, from the keys of in starting with the key of field. The keys and
+// their values are collected as is, without being parsed, such that they can
+// be forwarded. The field is left untouched if none of the keys are present.
+func decodePassthrough(in input, field field, pOpts *ParseOptions) {
+	lookup := in.lookup(field.options.source)
+	if lookup == nil {
+		return
+	}
+	keys := passthroughKeys(in.keys(field.options.source), in.canonical(field.fullKey(field.key(), pOpts)))
+	if len(keys) == 0 {
+		return
+	}
+
+	values := make(url.Values, len(keys))
+	for _, key := range keys {
+		values[key] = slices.Clone(lookup(key))
+	}
+
+	field.field.Set(reflect.ValueOf(values))
+	field.allocate(true)
+}
+
+// encodePassthrough encodes field, a url.Values field with the "passthrough"
+// option, into dst, replacing the values of all keys starting with the key of
+// field. Keys of field not starting with its key are omitted, since they
+// wouldn't be decoded into field.
+func encodePassthrough(dst url.Values, field field, pOpts *ParseOptions) {
+	prefix := field.fullKey(field.key(), pOpts)
+	for _, k := range passthroughKeys(keysOf(dst), prefix) {
+		delete(dst, k)
+	}
+	for key, values := range field.field.Interface().(url.Values) {
+		if strings.HasPrefix(key, prefix) && len(values) > 0 {
+			dst[key] = slices.Clone(values)
+		}
+	}
+}
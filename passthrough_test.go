@@ -0,0 +1,61 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestPassthrough(t *testing.T) {
+	type Target struct {
+		Query string     `urlvalue:"q"`
+		Ext   url.Values `urlvalue:"ext.,passthrough"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{"absent", url.Values{"q": {"shoes"}, "extra": {"1"}}, Target{Query: "shoes"}},
+		{
+			"collected as is",
+			url.Values{"q": {"shoes"}, "ext.tracking": {" ID-1 "}, "ext.flags": {"a", "b"}, "ext": {"x"}},
+			Target{Query: "shoes", Ext: url.Values{"ext.tracking": {" ID-1 "}, "ext.flags": {"a", "b"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("marshal", func(t *testing.T) {
+		in := Target{Query: "shoes", Ext: url.Values{"ext.tracking": {"1"}, "other": {"dropped"}}}
+		dst := url.Values{"ext.stale": {"x"}, "keep": {"y"}}
+		if err := urlvalues.MarshalInto(dst, in); err != nil {
+			t.Fatalf("urlvalues.MarshalInto(%v, %v) = %q, want <nil>", dst, in, err)
+		}
+		want := url.Values{"q": {"shoes"}, "ext.tracking": {"1"}, "keep": {"y"}}
+		if diff := cmp.Diff(dst, want); diff != "" {
+			t.Errorf("urlvalues.MarshalInto(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		var target struct {
+			Ext map[string]string `urlvalue:"ext.,passthrough"`
+		}
+		if err := urlvalues.Unmarshal(url.Values{}, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %T) = <nil>, want error", url.Values{}, &target)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package urlvalues
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details object describing why URL values
+// failed to be decoded.
+type Problem struct {
+	// URI reference identifying the problem type.
+	Type string `json:"type,omitempty"`
+	// Short, human-readable summary of the problem type.
+	Title string `json:"title"`
+	// HTTP status code.
+	Status int `json:"status"`
+	// Human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Parameters that failed to be decoded.
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// InvalidParam describes a single URL value that failed to be decoded.
+type InvalidParam struct {
+	// Name of struct field.
+	Field string `json:"field"`
+	// Key into URL values.
+	Key string `json:"key"`
+	// Why the value is invalid.
+	Reason string `json:"reason"`
+}
+
+// NewProblem returns a [Problem] describing err. If err maps to status 400 Bad
+// Request by [StatusCode], each [ParseError] found in the tree of err,
+// including those joined by [errors.Join], is listed as an invalid parameter.
+// Other errors result in the status returned by StatusCode; the error message
+// is reported in the detail of client errors, while other errors are not
+// revealed. A [TooManyErrorsError] in the tree of err is reported in the
+// detail of the problem.
+func NewProblem(err error) *Problem {
+	status := StatusCode(err)
+	parseErrs := parseErrors(err)
+	if status != http.StatusBadRequest || len(parseErrs) == 0 {
+		p := &Problem{
+			Title:  http.StatusText(status),
+			Status: status,
+		}
+		if status < http.StatusInternalServerError {
+			p.Detail = err.Error()
+		}
+		return p
+	}
+
+	p := &Problem{
+		Title:  "Your request parameters didn't validate.",
+		Status: http.StatusBadRequest,
+	}
+	var tooMany *TooManyErrorsError
+	if errors.As(err, &tooMany) {
+		p.Detail = tooMany.Error()
+	}
+	for _, parseErr := range parseErrs {
+		p.InvalidParams = append(p.InvalidParams, InvalidParam{
+			Field:  parseErr.FieldName,
+			Key:    parseErr.Key,
+			Reason: parseErr.reason(),
+		})
+	}
+	return p
+}
+
+// WriteProblem writes the [Problem] describing err to w, with the content type
+// application/problem+json.
+func WriteProblem(w http.ResponseWriter, err error) {
+	p := NewProblem(err)
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// parseErrors returns all ParseErrors in the tree of err, in the order they
+// are found by a depth-first traversal.
+func parseErrors(err error) []*ParseError {
+	switch x := err.(type) {
+	case nil:
+		return nil
+	case *ParseError:
+		return []*ParseError{x}
+	case interface{ Unwrap() []error }:
+		var parseErrs []*ParseError
+		for _, err := range x.Unwrap() {
+			parseErrs = append(parseErrs, parseErrors(err)...)
+		}
+		return parseErrs
+	default:
+		return parseErrors(errors.Unwrap(err))
+	}
+}
@@ -0,0 +1,125 @@
+package urlvalues_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestNewProblem(t *testing.T) {
+	unmarshalErr := func(data url.Values) error {
+		var target struct {
+			Page  int  `urlvalue:"page"`
+			Debug bool `urlvalue:"debug"`
+		}
+		err := urlvalues.Unmarshal(data, &target)
+		if err == nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, ...) = <nil>, want error", data)
+		}
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want *urlvalues.Problem
+	}{
+		{
+			"parse error",
+			unmarshalErr(url.Values{"page": {"last"}}),
+			&urlvalues.Problem{
+				Title:  "Your request parameters didn't validate.",
+				Status: http.StatusBadRequest,
+				InvalidParams: []urlvalues.InvalidParam{
+					{Field: "Page", Key: "page", Reason: `strconv.ParseInt: parsing "last": invalid syntax`},
+				},
+			},
+		},
+		{
+			"joined parse errors",
+			errors.Join(unmarshalErr(url.Values{"page": {"last"}}), unmarshalErr(url.Values{"debug": {"maybe"}})),
+			&urlvalues.Problem{
+				Title:  "Your request parameters didn't validate.",
+				Status: http.StatusBadRequest,
+				InvalidParams: []urlvalues.InvalidParam{
+					{Field: "Page", Key: "page", Reason: `strconv.ParseInt: parsing "last": invalid syntax`},
+					{Field: "Debug", Key: "debug", Reason: `strconv.ParseBool: parsing "maybe": invalid syntax`},
+				},
+			},
+		},
+		{
+			"invalid form",
+			fmt.Errorf("%w: invalid semicolon separator in query", urlvalues.ErrInvalidForm),
+			&urlvalues.Problem{
+				Title:  "Bad Request",
+				Status: http.StatusBadRequest,
+				Detail: "urlvalues: invalid request form: invalid semicolon separator in query",
+			},
+		},
+		{
+			"body too large",
+			&http.MaxBytesError{Limit: 1024},
+			&urlvalues.Problem{
+				Title:  "Request Entity Too Large",
+				Status: http.StatusRequestEntityTooLarge,
+				Detail: "http: request body too large",
+			},
+		},
+		{
+			"parse error and internal error",
+			errors.Join(unmarshalErr(url.Values{"page": {"last"}}), urlvalues.ErrInvalidStruct),
+			&urlvalues.Problem{
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+		{
+			"internal error",
+			urlvalues.ErrInvalidStruct,
+			&urlvalues.Problem{
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := urlvalues.NewProblem(tt.err)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.NewProblem(%v) -got +want\n%s", tt.err, diff)
+			}
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	var target struct {
+		Page int `urlvalue:"page"`
+	}
+	err := urlvalues.Unmarshal(url.Values{"page": {"last"}}, &target)
+
+	rec := httptest.NewRecorder()
+	urlvalues.WriteProblem(rec, err)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if got, want := rec.Header().Get("Content-Type"), "application/problem+json"; got != want {
+		t.Errorf("Content-Type = %q, want %q", got, want)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["invalid-params"]; !ok {
+		t.Errorf("body = %v, want invalid-params member", body)
+	}
+}
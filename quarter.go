@@ -0,0 +1,84 @@
+package urlvalues
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// quarterLayout is the name of the layout of calendar quarters, such as
+// "2024-Q2". Times are decoded as the first instant of the quarter in UTC.
+const quarterLayout = "Quarter"
+
+// Quarter is a calendar quarter of a year, decoded from the year and the
+// number of the quarter, such as "2024-Q2" for April through June 2024. The
+// Q is case-insensitive. Use the "Quarter" layout to decode time.Time fields
+// from quarters instead, as the first instant of the quarter.
+type Quarter struct {
+	year, q int
+}
+
+// QuarterOf returns the quarter that t is in.
+func QuarterOf(t time.Time) Quarter {
+	return Quarter{year: t.Year(), q: (int(t.Month())-1)/3 + 1}
+}
+
+// Year returns the year of q.
+func (q Quarter) Year() int {
+	return q.year
+}
+
+// Q returns the number of q within its year, 1 through 4.
+func (q Quarter) Q() int {
+	return q.q
+}
+
+// Start returns the first instant of q in UTC.
+func (q Quarter) Start() time.Time {
+	return time.Date(q.year, time.Month(3*(q.q-1)+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// End returns the first instant of the quarter following q in UTC, such that
+// q spans the times t for which !t.Before(q.Start()) && t.Before(q.End()).
+func (q Quarter) End() time.Time {
+	return q.Start().AddDate(0, 3, 0)
+}
+
+// String returns q as its year and the number of the quarter, such as
+// "2024-Q2".
+func (q Quarter) String() string {
+	return fmt.Sprintf("%04d-Q%d", q.year, q.q)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (q Quarter) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (q *Quarter) UnmarshalText(text []byte) error {
+	quarter, err := parseQuarter(string(text))
+	if err != nil {
+		return err
+	}
+	*q = quarter
+	return nil
+}
+
+// parseQuarter parses s as a quarter, such as "2024-Q2".
+func parseQuarter(s string) (Quarter, error) {
+	year, q, ok := strings.Cut(s, "-")
+	if !ok || len(q) != 2 || (q[0] != 'Q' && q[0] != 'q') {
+		return Quarter{}, errors.New("want a quarter such as 2024-Q2")
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil || len(year) != 4 {
+		return Quarter{}, fmt.Errorf("invalid year %q", year)
+	}
+	if q[1] < '1' || q[1] > '4' {
+		return Quarter{}, fmt.Errorf("invalid quarter %q, want Q1 through Q4", q)
+	}
+	return Quarter{year: y, q: int(q[1] - '0')}, nil
+}
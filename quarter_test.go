@@ -0,0 +1,58 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestQuarter(t *testing.T) {
+	type Target struct {
+		Period urlvalues.Quarter `urlvalue:"period"`
+		Since  time.Time         `urlvalue:"since,layout:Quarter"`
+	}
+
+	in := url.Values{"period": {"2024-q2"}, "since": {"2023-Q4"}}
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if got.Period.Year() != 2024 || got.Period.Q() != 2 {
+		t.Errorf("urlvalues.Unmarshal(...) Period = %d Q%d, want 2024 Q2", got.Period.Year(), got.Period.Q())
+	}
+	if want := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC); !got.Period.Start().Equal(want) {
+		t.Errorf("Quarter.Start() = %v, want %v", got.Period.Start(), want)
+	}
+	if want := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC); !got.Period.End().Equal(want) {
+		t.Errorf("Quarter.End() = %v, want %v", got.Period.End(), want)
+	}
+	if want := time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC); !got.Since.Equal(want) {
+		t.Errorf("urlvalues.Unmarshal(...) Since = %v, want %v", got.Since, want)
+	}
+
+	encoded, err := urlvalues.Marshal(got)
+	if err != nil {
+		t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", got, err)
+	}
+	if diff := cmp.Diff(encoded, url.Values{"period": {"2024-Q2"}, "since": {"2023-Q4"}}); diff != "" {
+		t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", got, diff)
+	}
+
+	if q := urlvalues.QuarterOf(time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC)); q.String() != "2024-Q4" {
+		t.Errorf("urlvalues.QuarterOf(...) = %v, want 2024-Q4", q)
+	}
+
+	for _, value := range []string{"2024-Q5", "2024-Q0", "2024Q2", "24-Q2", "2024-2", "2024-Q12"} {
+		for _, key := range []string{"period", "since"} {
+			in := url.Values{key: {value}}
+			var target Target
+			if err := urlvalues.Unmarshal(in, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+			}
+		}
+	}
+}
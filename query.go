@@ -0,0 +1,87 @@
+package urlvalues
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseQuery parses the URL-encoded query string raw like [url.ParseQuery],
+// except that pairs are separated by any of the separators set by passing the
+// [WithSeparators] [SetParseOptionFunc]. Since Go 1.17, [url.ParseQuery]
+// rejects semicolons, which legacy clients may still use to separate pairs.
+//
+// Like [url.ParseQuery], ParseQuery returns the first error encountered, if
+// any, along with the values of all pairs that were parsed successfully.
+// Passing the [WithLenientQuery] [SetParseOptionFunc] keeps the raw keys and
+// values of pairs that fail to be unescaped instead, emitting a [Warning] for
+// each.
+func ParseQuery(raw string, setParseOpts ...SetParseOptionFunc) (url.Values, error) {
+	return parseQuery(raw, newParseOptions(setParseOpts))
+}
+
+// UnmarshalQuery parses the URL-encoded query string raw using [ParseQuery] and
+// unmarshals the values into the value pointed to by v using [Unmarshal]. If
+// raw fails to be parsed, an [ErrInvalidForm] error is returned.
+func UnmarshalQuery(raw string, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	defer pOpts.observe(v)(&err)
+
+	values, err := parseQuery(raw, pOpts)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidForm, err)
+	}
+
+	return unmarshalValues(values, v, pOpts)
+}
+
+func parseQuery(raw string, pOpts *ParseOptions) (url.Values, error) {
+	seps := pOpts.separators
+	if seps == "" {
+		seps = "&"
+	}
+
+	values := make(url.Values)
+	var firstErr error
+	for raw != "" {
+		var pair string
+		if i := strings.IndexAny(raw, seps); i >= 0 {
+			pair, raw = raw[:i], raw[i+1:]
+		} else {
+			pair, raw = raw, ""
+		}
+		if pair == "" {
+			continue
+		}
+
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, keyErr := url.QueryUnescape(rawKey)
+		value, valueErr := url.QueryUnescape(rawValue)
+		if err := firstError(keyErr, valueErr); err != nil {
+			if !pOpts.lenientQuery {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			pOpts.warn(Warning{
+				Key:     rawKey,
+				Message: fmt.Sprintf("pair %q kept raw: %v", pair, err),
+			})
+			key, value = rawKey, rawValue
+		}
+		values[key] = append(values[key], value)
+	}
+
+	return values, firstErr
+}
+
+// firstError returns the first of errs that is not nil, if any.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		opts    []urlvalues.SetParseOptionFunc
+		want    url.Values
+		wantErr bool
+	}{
+		{"ampersands", "a=1&b=2&a=3", nil, url.Values{"a": {"1", "3"}, "b": {"2"}}, false},
+		{"semicolon not a separator by default", "a=1;b=2", nil, url.Values{"a": {"1;b=2"}}, false},
+		{"semicolons", "a=1;b=2&c=x+y", []urlvalues.SetParseOptionFunc{urlvalues.WithSeparators("&;")}, url.Values{"a": {"1"}, "b": {"2"}, "c": {"x y"}}, false},
+		{"empty pairs and values", "&a&&b=", nil, url.Values{"a": {""}, "b": {""}}, false},
+		{"invalid escape", "a=%zz&b=2", nil, url.Values{"b": {"2"}}, true},
+		{"lenient", "a=%zz&b=2", []urlvalues.SetParseOptionFunc{urlvalues.WithLenientQuery()}, url.Values{"a": {"%zz"}, "b": {"2"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.ParseQuery(tt.raw, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("urlvalues.ParseQuery(%q) error = %v, want error %t", tt.raw, err, tt.wantErr)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.ParseQuery(%q) -got +want\n%s", tt.raw, diff)
+			}
+		})
+	}
+
+	t.Run("lenient warnings", func(t *testing.T) {
+		var warnings []urlvalues.Warning
+		handler := urlvalues.WithWarningHandler(func(w urlvalues.Warning) { warnings = append(warnings, w) })
+		if _, err := urlvalues.ParseQuery("a=%zz", urlvalues.WithLenientQuery(), handler); err != nil {
+			t.Fatalf("urlvalues.ParseQuery(...) = %q, want <nil>", err)
+		}
+		if len(warnings) != 1 || warnings[0].Key != "a" {
+			t.Errorf("warnings = %v, want one for key a", warnings)
+		}
+	})
+}
+
+func TestUnmarshalQuery(t *testing.T) {
+	type Target struct {
+		Page int    `urlvalue:"page"`
+		Sort string `urlvalue:"sort"`
+	}
+
+	var got Target
+	raw := "page=2;sort=name"
+	if err := urlvalues.UnmarshalQuery(raw, &got, urlvalues.WithSeparators("&;")); err != nil {
+		t.Fatalf("urlvalues.UnmarshalQuery(%q, %v) = %q, want <nil>", raw, &got, err)
+	}
+	if diff := cmp.Diff(got, Target{Page: 2, Sort: "name"}); diff != "" {
+		t.Errorf("urlvalues.UnmarshalQuery(...) -got +want\n%s", diff)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		var target Target
+		err := urlvalues.UnmarshalQuery("page=%zz", &target)
+		if !errors.Is(err, urlvalues.ErrInvalidForm) {
+			t.Errorf("urlvalues.UnmarshalQuery(...) = %v, want %v", err, urlvalues.ErrInvalidForm)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package urlvalues
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Range is a range of values of type T with optional bounds, decoded from
+// the bounds separated by two dots (..), such as "10..20". Either bound may
+// be omitted, such as in "..20" and "10..", but not both. The lower bound may
+// not be greater than the upper bound.
+//
+// The bounds are decoded like values of fields of type T, following the
+// options of the range field, such as the "layout" option of time.Time
+// bounds, as in "2024-01-01..2024-02-01".
+type Range[T cmp.Ordered | time.Time] struct {
+	// Lower bound of the range, or nil if unbounded.
+	Min *T
+	// Upper bound of the range, or nil if unbounded.
+	Max *T
+}
+
+// rangeSep separates the bounds of a Range.
+const rangeSep = ".."
+
+// Contains reports whether v is within the bounds of r, inclusively.
+func (r Range[T]) Contains(v T) bool {
+	return (r.Min == nil || compareOperands(*r.Min, v) <= 0) &&
+		(r.Max == nil || compareOperands(v, *r.Max) <= 0)
+}
+
+func (r *Range[T]) decodeOperands(value string, parse func(value string, dst reflect.Value) error) error {
+	lower, upper, ok := strings.Cut(value, rangeSep)
+	if !ok {
+		return fmt.Errorf("range %q missing %s", value, rangeSep)
+	}
+	if lower == "" && upper == "" {
+		return fmt.Errorf("range %q missing bounds", value)
+	}
+
+	var rng Range[T]
+	if lower != "" {
+		rng.Min = new(T)
+		if err := parse(lower, reflect.ValueOf(rng.Min).Elem()); err != nil {
+			return err
+		}
+	}
+	if upper != "" {
+		rng.Max = new(T)
+		if err := parse(upper, reflect.ValueOf(rng.Max).Elem()); err != nil {
+			return err
+		}
+	}
+	if rng.Min != nil && rng.Max != nil && compareOperands(*rng.Min, *rng.Max) > 0 {
+		return fmt.Errorf("range %q has a lower bound greater than its upper bound", value)
+	}
+
+	*r = rng
+	return nil
+}
+
+func (r Range[T]) encodeOperands(format func(v reflect.Value) (string, error)) (string, error) {
+	var lower, upper string
+	if r.Min != nil {
+		s, err := format(reflect.ValueOf(r.Min).Elem())
+		if err != nil {
+			return "", err
+		}
+		lower = s
+	}
+	if r.Max != nil {
+		s, err := format(reflect.ValueOf(r.Max).Elem())
+		if err != nil {
+			return "", err
+		}
+		upper = s
+	}
+	return lower + rangeSep + upper, nil
+}
+
+// compareOperands compares a and b like cmp.Compare, and times by
+// time.Time.Compare.
+func compareOperands[T cmp.Ordered | time.Time](a, b T) int {
+	if a, ok := any(a).(time.Time); ok {
+		return a.Compare(any(b).(time.Time))
+	}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch va.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(va.Int(), vb.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(va.Uint(), vb.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(va.Float(), vb.Float())
+	default:
+		return cmp.Compare(va.String(), vb.String())
+	}
+}
@@ -0,0 +1,77 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestRange(t *testing.T) {
+	type Target struct {
+		Price   urlvalues.Range[int]       `urlvalue:"price"`
+		Rating  urlvalues.Range[float64]   `urlvalue:"rating"`
+		Created urlvalues.Range[time.Time] `urlvalue:"created,layout:2006-01-02"`
+	}
+
+	ptr := func(v int) *int { return &v }
+	fptr := func(v float64) *float64 { return &v }
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{"both bounds", url.Values{"price": {"10..20"}}, Target{Price: urlvalues.Range[int]{Min: ptr(10), Max: ptr(20)}}},
+		{"upper bound", url.Values{"price": {"..20"}}, Target{Price: urlvalues.Range[int]{Max: ptr(20)}}},
+		{"lower bound", url.Values{"price": {"10.."}}, Target{Price: urlvalues.Range[int]{Min: ptr(10)}}},
+		{"negative", url.Values{"price": {"-20..-10"}}, Target{Price: urlvalues.Range[int]{Min: ptr(-20), Max: ptr(-10)}}},
+		{"floats", url.Values{"rating": {"1.5..4.5"}}, Target{Rating: urlvalues.Range[float64]{Min: fptr(1.5), Max: fptr(4.5)}}},
+		{"times", url.Values{"created": {"2024-01-01..2024-02-01"}}, Target{Created: urlvalues.Range[time.Time]{Min: date(2024, 1, 1), Max: date(2024, 2, 1)}}},
+		{"equal bounds", url.Values{"price": {"10..10"}}, Target{Price: urlvalues.Range[int]{Min: ptr(10), Max: ptr(10)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+
+			encoded, err := urlvalues.Marshal(got)
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", got, err)
+			}
+			if diff := cmp.Diff(encoded, tt.in); diff != "" {
+				t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, value := range []string{"10", "..", "20..10", "a..b"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			in := url.Values{"price": {value}}
+			var target Target
+			if err := urlvalues.Unmarshal(in, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+			}
+		})
+	}
+
+	t.Run("contains", func(t *testing.T) {
+		rng := urlvalues.Range[int]{Min: ptr(10)}
+		for v, want := range map[int]bool{9: false, 10: true, 1000: true} {
+			if got := rng.Contains(v); got != want {
+				t.Errorf("Contains(%d) = %t, want %t", v, got, want)
+			}
+		}
+	})
+}
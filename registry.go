@@ -0,0 +1,221 @@
+package urlvalues
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// defaultDiscriminator is the key of the name of the implementation decoded
+// into an interface field, unless set by the "discriminator" tag option.
+const defaultDiscriminator = "type"
+
+// UnknownTypeError occurs when the discriminator key of an interface field
+// holds a name that no implementation is registered by. See [Register].
+type UnknownTypeError struct {
+	// Name held by the discriminator key.
+	Name string
+}
+
+func (err *UnknownTypeError) Error() string {
+	return fmt.Sprintf("unknown type %q", err.Name)
+}
+
+// Register registers the concrete type of impl, a struct or struct pointer, as
+// an implementation of the interface type I under name. Fields of type I are
+// decoded by reading name from the discriminator key of the field, and
+// decoding a new value of the concrete type of impl from the keys of its
+// fields. The keys are composed with the key of the interface field like the
+// keys of a nested struct, such as "notify.type" and "notify.address" given
+// the [DotKeys] key style and an interface field keyed "notify". The
+// discriminator key defaults to "type", and can be changed by the
+// "discriminator" tag option.
+//
+// Interface fields are left nil if their discriminator keys are absent. An
+// [UnknownTypeError] is returned if the name is not registered for I.
+//
+// Register is meant to be called from init functions, and panics if I is not
+// an interface type, if impl is not a struct or struct pointer, or if name or
+// the concrete type of impl is already registered for I.
+func Register[I any](name string, impl I) {
+	iface := reflect.TypeFor[I]()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("urlvalues: Register of non-interface type %s", iface))
+	}
+	typ := reflect.TypeOf(impl)
+	if typ == nil || !isStructType(typ) {
+		panic(fmt.Sprintf("urlvalues: Register of %s implementation %v, want struct or struct pointer", iface, typ))
+	}
+	if name == "" {
+		panic(fmt.Sprintf("urlvalues: Register of %s implementation %s with empty name", iface, typ))
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	impls := registry.impls[iface]
+	if impls == nil {
+		impls = &implementationSet{
+			types: make(map[string]reflect.Type),
+			names: make(map[reflect.Type]string),
+		}
+		registry.impls[iface] = impls
+	}
+	if _, ok := impls.types[name]; ok {
+		panic(fmt.Sprintf("urlvalues: Register of %s implementation %s with duplicate name %q", iface, typ, name))
+	}
+	if _, ok := impls.names[typ]; ok {
+		panic(fmt.Sprintf("urlvalues: Register of duplicate %s implementation %s", iface, typ))
+	}
+	impls.types[name] = typ
+	impls.names[typ] = name
+}
+
+// registry holds the implementations registered by Register, keyed by the
+// interface types they implement.
+var registry = struct {
+	mu    sync.RWMutex
+	impls map[reflect.Type]*implementationSet
+}{impls: make(map[reflect.Type]*implementationSet)}
+
+// implementationSet is the set of implementations registered for an
+// interface type, by name and by concrete type.
+type implementationSet struct {
+	types map[string]reflect.Type
+	names map[reflect.Type]string
+}
+
+// isStructType reports whether typ is a struct or struct pointer type.
+func isStructType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
+// isPolymorphic reports whether v is an interface with registered
+// implementations.
+func isPolymorphic(v reflect.Value) bool {
+	if v.Kind() != reflect.Interface {
+		return false
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.impls[v.Type()] != nil
+}
+
+// implementationOf returns the implementation of iface registered under name.
+func implementationOf(iface reflect.Type, name string) (reflect.Type, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	typ, ok := registry.impls[iface].types[name]
+	return typ, ok
+}
+
+// implementationName returns the name that typ is registered under as an
+// implementation of iface.
+func implementationName(iface, typ reflect.Type) (string, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	name, ok := registry.impls[iface].names[typ]
+	return name, ok
+}
+
+// discriminatorKey returns the key of the name of the implementation of f, an
+// interface field, composed with the keys of the structs that f is nested in.
+func (f field) discriminatorKey(pOpts *ParseOptions) string {
+	key := f.options.discriminator
+	if key == "" {
+		key = defaultDiscriminator
+	}
+	return field{path: f.implPath}.fullKey(key, pOpts)
+}
+
+// decodeImplementation decodes the implementation of field, an interface
+// field, named by its discriminator key. The field is left untouched if the
+// discriminator key is absent.
+func decodeImplementation(in input, field field, pOpts *ParseOptions) error {
+	lookup := in.lookup(field.options.source)
+	if lookup == nil {
+		return nil
+	}
+	key := field.discriminatorKey(pOpts)
+	values := lookup(key)
+	if len(values) == 0 {
+		return nil
+	}
+
+	typ, ok := implementationOf(field.field.Type(), values[0])
+	if !ok {
+		return newParseError(field, key, values[0], &UnknownTypeError{Name: values[0]}, pOpts)
+	}
+	impl, fields, err := extractImplementation(field, typ, pOpts)
+	if err != nil {
+		return err
+	}
+	if err := decodeFields(in, fields, pOpts); err != nil {
+		return err
+	}
+	// Set the implementation once decoded, since interfaces hold copies of
+	// struct values.
+	field.field.Set(impl)
+	field.allocate(true)
+
+	return nil
+}
+
+// encodeImplementation encodes the value of field, an interface field, into
+// dst along with its discriminator key.
+func encodeImplementation(dst url.Values, field field, pOpts *ParseOptions) error {
+	delete(dst, field.discriminatorKey(pOpts))
+	if field.field.IsNil() {
+		return nil
+	}
+
+	value := field.field.Elem()
+	name, ok := implementationName(field.field.Type(), value.Type())
+	if !ok {
+		return fmt.Errorf("urlvalues: encoding field %s: type %s not registered as implementation of %s", field.name, value.Type(), field.field.Type())
+	}
+	if value.Kind() == reflect.Ptr && value.IsNil() {
+		return nil
+	}
+
+	impl, fields, err := extractImplementation(field, value.Type(), pOpts)
+	if err != nil {
+		return err
+	}
+	// Encode a copy, since the fields of the value held by an interface
+	// aren't addressable.
+	if value.Kind() == reflect.Ptr {
+		impl.Elem().Set(value.Elem())
+	} else {
+		impl.Set(value)
+	}
+
+	dst[field.discriminatorKey(pOpts)] = []string{name}
+	return encodeFields(dst, fields, pOpts)
+}
+
+// extractImplementation returns a new value of typ, an implementation of
+// field, along with its fields keyed under field. The fields are extracted
+// as nested in the structs that field is nested in, so that implementations
+// are subject to the same depth limit as nested structs.
+func extractImplementation(field field, typ reflect.Type, pOpts *ParseOptions) (reflect.Value, []field, error) {
+	var impl, strct reflect.Value
+	if typ.Kind() == reflect.Ptr {
+		impl = reflect.New(typ.Elem())
+		strct = impl.Elem()
+	} else {
+		impl = reflect.New(typ).Elem()
+		strct = impl
+	}
+
+	fields, err := extractStructFields(nil, strct, pOpts, field.implParents, slices.Clip(field.allocs), field.implPath)
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return impl, fields, nil
+}
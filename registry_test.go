@@ -0,0 +1,203 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+type notifier interface {
+	notify()
+}
+
+type emailNotifier struct {
+	Address string `urlvalue:"address"`
+}
+
+func (emailNotifier) notify() {}
+
+type webhookNotifier struct {
+	URL     string `urlvalue:"url"`
+	Retries int    `urlvalue:"retries,default:3"`
+}
+
+func (*webhookNotifier) notify() {}
+
+type step interface {
+	step()
+}
+
+type chainStep struct {
+	Next step `urlvalue:"next"`
+}
+
+func (chainStep) step() {}
+
+func init() {
+	urlvalues.Register[notifier]("email", emailNotifier{})
+	urlvalues.Register[notifier]("webhook", &webhookNotifier{})
+	urlvalues.Register[step]("chain", chainStep{})
+}
+
+func TestUnmarshal_Interface(t *testing.T) {
+	type Target struct {
+		Name   string   `urlvalue:"name"`
+		Notify notifier `urlvalue:"notify"`
+		Backup notifier `urlvalue:"backup,prefix,discriminator:kind"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{
+			name: "struct implementation",
+			in:   url.Values{"notify.type": {"email"}, "notify.address": {"a@example.com"}},
+			want: Target{Notify: emailNotifier{Address: "a@example.com"}},
+		},
+		{
+			name: "pointer implementation",
+			in:   url.Values{"notify.type": {"webhook"}, "notify.url": {"https://example.com"}},
+			want: Target{Notify: &webhookNotifier{URL: "https://example.com", Retries: 3}},
+		},
+		{
+			name: "custom discriminator",
+			in:   url.Values{"backup.kind": {"email"}, "backup.address": {"b@example.com"}, "name": {"x"}},
+			want: Target{Name: "x", Backup: emailNotifier{Address: "b@example.com"}},
+		},
+		{
+			name: "absent discriminator",
+			in:   url.Values{"notify.address": {"a@example.com"}},
+			want: Target{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got, urlvalues.WithKeyStyle(urlvalues.DotKeys)); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("unknown type", func(t *testing.T) {
+		in := url.Values{"notify.type": {"sms"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithKeyStyle(urlvalues.DotKeys))
+
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", in, &target, err)
+		}
+		if parseErr.Key != "notify.type" {
+			t.Errorf("Key = %q, want %q", parseErr.Key, "notify.type")
+		}
+		var typeErr *urlvalues.UnknownTypeError
+		if !errors.As(err, &typeErr) || typeErr.Name != "sms" {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.UnknownTypeError", in, &target, err)
+		}
+	})
+
+	t.Run("nested implementations", func(t *testing.T) {
+		chain := func(n int) url.Values {
+			in := make(url.Values)
+			key := "step"
+			for range n {
+				in.Set(key+".type", "chain")
+				key += ".next"
+			}
+			return in
+		}
+		var target struct {
+			Step step `urlvalue:"step"`
+		}
+
+		in := chain(3)
+		if err := urlvalues.Unmarshal(in, &target, urlvalues.WithKeyStyle(urlvalues.DotKeys)); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+		want := chainStep{Next: chainStep{Next: chainStep{}}}
+		if diff := cmp.Diff(target.Step, step(want)); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+
+		in = chain(100)
+		if err := urlvalues.Unmarshal(in, &target, urlvalues.WithKeyStyle(urlvalues.DotKeys)); err == nil {
+			t.Errorf("urlvalues.Unmarshal(<100 nested implementations>, %v) = <nil>, want error", &target)
+		}
+	})
+
+	t.Run("invalid discriminator option", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Name string `urlvalue:"name,discriminator:kind"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestMarshal_Interface(t *testing.T) {
+	type Target struct {
+		Notify notifier `urlvalue:"notify,prefix"`
+	}
+
+	tests := []struct {
+		name string
+		in   Target
+		want url.Values
+	}{
+		{"nil", Target{}, url.Values{}},
+		{"struct implementation", Target{Notify: emailNotifier{Address: "a@example.com"}}, url.Values{"notify.type": {"email"}, "notify.address": {"a@example.com"}}},
+		{"pointer implementation", Target{Notify: &webhookNotifier{URL: "u", Retries: 3}}, url.Values{"notify.type": {"webhook"}, "notify.url": {"u"}, "notify.retries": {"3"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := urlvalues.Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", tt.in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+			}
+
+			var decoded Target
+			if err := urlvalues.Unmarshal(got, &decoded); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", got, &decoded, err)
+			}
+			if diff := cmp.Diff(decoded, tt.in); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{"non-interface", func() { urlvalues.Register[emailNotifier]("email", emailNotifier{}) }},
+		{"nil implementation", func() { urlvalues.Register[notifier]("nil", nil) }},
+		{"duplicate name", func() { urlvalues.Register[notifier]("email", &webhookNotifier{}) }},
+		{"duplicate type", func() { urlvalues.Register[notifier]("mail", emailNotifier{}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("urlvalues.Register did not panic")
+				}
+			}()
+			tt.register()
+		})
+	}
+}
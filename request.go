@@ -0,0 +1,180 @@
+package urlvalues
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// UnmarshalRequest unmarshals the URL query and form body of r into the value
+// pointed to by v. The form body is parsed using [http.Request.ParseForm], or
+// [http.Request.ParseMultipartForm] for multipart/form-data requests, if it has
+// not been parsed already. The maximum number of bytes of a multipart form
+// stored in memory can be set by passing the [WithMaxMemory]
+// [SetParseOptionFunc], and the maximum number of bytes of the body read by
+// passing the [WithMaxBodySize] SetParseOptionFunc, which defaults to 64 MB.
+// If the form fails to be parsed, an [ErrInvalidForm] error is returned.
+//
+// When a key is present in both the URL query and the form body, the values
+// of the form body are used. This can be changed by passing the
+// [WithPrecedence] [SetParseOptionFunc], which can also restrict decoding to
+// either the URL query or the form body. Values of the same key are never
+// mixed between the two. A [Warning] is emitted for each key whose values are
+// ignored in favour of the other, see [WithWarningHandler].
+//
+// Text parts of multipart forms are decoded like any other form value. Fields
+// of type *[multipart.FileHeader] are assigned the first uploaded file of
+// their key, and fields of type []*[multipart.FileHeader] all uploaded files of
+// their key. File fields are left untouched by [Unmarshal].
+//
+// The "source" tag option restricts which part of r a field is decoded from:
+//
+//	// Field is decoded from the URL query only.
+//	Field int `urlvalue:"myName,source:query"`
+//
+//	// Field is decoded from the form body only.
+//	Field int `urlvalue:"myName,source:form"`
+//
+//	// Field is decoded from the {myName} path wildcard.
+//	Field int `urlvalue:"myName,source:path"`
+//
+// Path wildcards are those matched by [http.ServeMux] and returned by
+// [http.Request.PathValue]. For example, a field tagged
+// `urlvalue:"id,source:path"` is decoded from the {id} segment of the pattern
+// "/users/{id}". Fields with the "header" and "cookie" sources are left
+// untouched, except for their default values. Use [Bind] to decode those as
+// well.
+//
+// See [Unmarshal] for details on how the merged values are decoded.
+func UnmarshalRequest(r *http.Request, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	pOpts.ctx = r.Context()
+	defer pOpts.observe(v)(&err)
+
+	in, err := requestInput(r, pOpts)
+	if err != nil {
+		return err
+	}
+	in, traceUnused := traceKeys(in, pOpts)
+	defer traceUnused()
+
+	return unmarshal(in, v, pOpts)
+}
+
+// Bind unmarshals r into the value pointed to by v. It behaves like
+// [UnmarshalRequest], but additionally decodes fields with the "header" and
+// "cookie" sources, so that all parts of a request can be bound to a single
+// struct value:
+//
+//	// Field is decoded from the My-Name header, see UnmarshalHeader.
+//	Field int `urlvalue:"my-name,source:header"`
+//
+//	// Field is decoded from the myName cookie, see UnmarshalCookies.
+//	Field int `urlvalue:"myName,source:cookie"`
+func Bind(r *http.Request, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	pOpts.ctx = r.Context()
+	defer pOpts.observe(v)(&err)
+
+	in, err := requestInput(r, pOpts)
+	if err != nil {
+		return err
+	}
+	in.sources[sourceHeader] = headerLookup(headerValues(r.Header))
+	in.sources[sourceCookie] = valuesLookup(cookieValues(r.Cookies()))
+	in, traceUnused := traceKeys(in, pOpts)
+	defer traceUnused()
+
+	return unmarshal(in, v, pOpts)
+}
+
+// requestInput parses the form of r and returns an input of its URL query,
+// form body, path wildcards and uploaded files. The body of r is limited to
+// the maximum body size of pOpts, unless the form is already parsed.
+func requestInput(r *http.Request, pOpts *ParseOptions) (input, error) {
+	if n := pOpts.MaxBodySize(); n >= 0 && r.Body != nil && r.PostForm == nil && r.MultipartForm == nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, n)
+	}
+
+	// ParseMultipartForm reports errors of non-multipart forms as
+	// http.ErrNotMultipart, so parse those explicitly first.
+	if err := r.ParseForm(); err != nil {
+		return input{}, fmt.Errorf("%w: %w", ErrInvalidForm, err)
+	}
+	err := r.ParseMultipartForm(pOpts.MaxMemory())
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return input{}, fmt.Errorf("%w: %w", ErrInvalidForm, err)
+	}
+
+	var query url.Values
+	if r.URL != nil {
+		query = r.URL.Query()
+	}
+
+	var data url.Values
+	switch pOpts.precedence {
+	case QueryOnly:
+		data = query
+	case FormOnly:
+		data = r.PostForm
+	case QueryPrecedence:
+		data = mergeValues(r.PostForm, query)
+		warnIgnored(pOpts, r.PostForm, query, "form value ignored in favour of URL query value")
+	default:
+		data = mergeValues(query, r.PostForm)
+		warnIgnored(pOpts, query, r.PostForm, "URL query value ignored in favour of form value")
+	}
+
+	in := input{
+		values: valuesLookup(data),
+		sources: map[string]lookup{
+			sourceQuery: valuesLookup(query),
+			sourceForm:  valuesLookup(r.PostForm),
+			sourcePath:  pathLookup(r),
+		},
+		valueKeys: valuesKeys(data),
+		sourceKeys: map[string]keyLister{
+			sourceQuery: valuesKeys(query),
+			sourceForm:  valuesKeys(r.PostForm),
+		},
+	}
+	if r.MultipartForm != nil {
+		in.files = r.MultipartForm.File
+	}
+
+	return in, nil
+}
+
+// mergeValues returns a new url.Values with all keys of the given values. If a
+// key is present in more than one of them, the values of the last one wins.
+func mergeValues(vs ...url.Values) url.Values {
+	merged := make(url.Values)
+	for _, v := range vs {
+		for key, values := range v {
+			merged[key] = values
+		}
+	}
+	return merged
+}
+
+// warnIgnored emits a warning with the given message for each key of ignored
+// that is also present in preferred, in lexical order of the keys.
+func warnIgnored(pOpts *ParseOptions, ignored, preferred url.Values, msg string) {
+	for _, key := range keysOf(ignored) {
+		if _, ok := preferred[key]; ok {
+			pOpts.warn(Warning{Key: key, Message: msg})
+		}
+	}
+}
+
+// pathLookup returns a lookup of the path wildcards of r, as matched by
+// [http.ServeMux].
+func pathLookup(r *http.Request) lookup {
+	return func(key string) []string {
+		if v := r.PathValue(key); v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+}
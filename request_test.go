@@ -0,0 +1,282 @@
+package urlvalues_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshalRequest(t *testing.T) {
+	type Target struct {
+		Name  string `urlvalue:"name"`
+		Page  int    `urlvalue:"page,default:1"`
+		Query string `urlvalue:"q"`
+	}
+
+	tests := []struct {
+		name string
+		opts []urlvalues.SetParseOptionFunc
+		want Target
+	}{
+		{
+			"form precedence",
+			nil,
+			Target{Name: "form", Page: 3, Query: "search"},
+		},
+		{
+			"query precedence",
+			[]urlvalues.SetParseOptionFunc{urlvalues.WithPrecedence(urlvalues.QueryPrecedence)},
+			Target{Name: "query", Page: 3, Query: "search"},
+		},
+		{
+			"query only",
+			[]urlvalues.SetParseOptionFunc{urlvalues.WithPrecedence(urlvalues.QueryOnly)},
+			Target{Name: "query", Page: 1, Query: "search"},
+		},
+		{
+			"form only",
+			[]urlvalues.SetParseOptionFunc{urlvalues.WithPrecedence(urlvalues.FormOnly)},
+			Target{Name: "form", Page: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/?name=query&q=search", strings.NewReader("name=form&page=3"))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			var got Target
+			if err := urlvalues.UnmarshalRequest(r, &got, tt.opts...); err != nil {
+				t.Fatalf("urlvalues.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &got, err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.UnmarshalRequest(...) -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRequest_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if err := mw.WriteField("title", "holiday"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		fw, err := mw.CreateFormFile("photos", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	var got struct {
+		Title   string                  `urlvalue:"title"`
+		Cover   *multipart.FileHeader   `urlvalue:"photos"`
+		Photos  []*multipart.FileHeader `urlvalue:"photos"`
+		Missing *multipart.FileHeader   `urlvalue:"missing"`
+	}
+	if err := urlvalues.UnmarshalRequest(r, &got, urlvalues.WithMaxMemory(1<<10)); err != nil {
+		t.Fatalf("urlvalues.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &got, err)
+	}
+
+	if got.Title != "holiday" {
+		t.Errorf("Title = %q, want %q", got.Title, "holiday")
+	}
+	if got.Cover == nil || got.Cover.Filename != "a.jpg" {
+		t.Errorf("Cover = %v, want file a.jpg", got.Cover)
+	}
+	var names []string
+	for _, fh := range got.Photos {
+		names = append(names, fh.Filename)
+	}
+	if diff := cmp.Diff(names, []string{"a.jpg", "b.jpg"}); diff != "" {
+		t.Errorf("Photos -got +want\n%s", diff)
+	}
+	if got.Missing != nil {
+		t.Errorf("Missing = %v, want <nil>", got.Missing)
+	}
+}
+
+func TestUnmarshalRequest_PathValues(t *testing.T) {
+	type Target struct {
+		ID      int    `urlvalue:"id,source:path"`
+		Tab     string `urlvalue:"tab,source:path,default:overview"`
+		Verbose bool   `urlvalue:"verbose"`
+	}
+
+	var (
+		got    Target
+		gotErr error
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		gotErr = urlvalues.UnmarshalRequest(r, &got)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42?verbose=true&id=7", nil))
+	if gotErr != nil {
+		t.Fatalf("urlvalues.UnmarshalRequest(...) = %q, want <nil>", gotErr)
+	}
+
+	want := Target{ID: 42, Tab: "overview", Verbose: true}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.UnmarshalRequest(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalRequest_PathValuesInvalid(t *testing.T) {
+	var target struct {
+		ID int `urlvalue:"id,source:path"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/users/abc", nil)
+	r.SetPathValue("id", "abc")
+	got := urlvalues.UnmarshalRequest(r, &target)
+
+	var parseErr *urlvalues.ParseError
+	if !errors.As(got, &parseErr) {
+		t.Errorf("urlvalues.UnmarshalRequest(%v, %v) = %v, want %q", r, &target, got, reflect.TypeOf(parseErr).String())
+	}
+}
+
+func TestBind(t *testing.T) {
+	type Target struct {
+		ID       int    `urlvalue:"id,source:path"`
+		Page     int    `urlvalue:"page,source:query,default:1"`
+		Name     string `urlvalue:"name,source:form"`
+		Token    string `urlvalue:"x-token,source:header"`
+		Theme    string `urlvalue:"theme,source:cookie,default:light"`
+		Merged   string `urlvalue:"merged"`
+		NotQuery string `urlvalue:"name,source:query"`
+	}
+
+	var (
+		got    Target
+		gotErr error
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		gotErr = urlvalues.Bind(r, &got)
+	})
+	r := httptest.NewRequest(http.MethodPost, "/users/42?page=3&merged=query", strings.NewReader("name=form&merged=form"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Token", "secret")
+	r.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+	if gotErr != nil {
+		t.Fatalf("urlvalues.Bind(...) = %q, want <nil>", gotErr)
+	}
+
+	want := Target{ID: 42, Page: 3, Name: "form", Token: "secret", Theme: "dark", Merged: "form"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Bind(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalRequest_Warnings(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/?name=query&q=search", strings.NewReader("name=form"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var got []urlvalues.Warning
+	var target struct {
+		Name string `urlvalue:"name"`
+	}
+	err := urlvalues.UnmarshalRequest(r, &target, urlvalues.WithWarningHandler(func(w urlvalues.Warning) {
+		got = append(got, w)
+	}))
+	if err != nil {
+		t.Fatalf("urlvalues.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &target, err)
+	}
+
+	want := []urlvalues.Warning{
+		{Key: "name", Message: "URL query value ignored in favour of form value"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("warnings -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshalRequest_WarningsOrder(t *testing.T) {
+	type Target struct {
+		A string `urlvalue:"a"`
+		B string `urlvalue:"b"`
+		C string `urlvalue:"c"`
+		D string `urlvalue:"d"`
+	}
+
+	want := []urlvalues.Warning{
+		{Key: "a", Message: "URL query value ignored in favour of form value"},
+		{Key: "b", Message: "URL query value ignored in favour of form value"},
+		{Key: "c", Message: "URL query value ignored in favour of form value"},
+		{Key: "d", Message: "URL query value ignored in favour of form value"},
+	}
+
+	// Map iteration order is random, so repeat to catch unstable ordering.
+	for range 20 {
+		r := httptest.NewRequest(http.MethodPost, "/?d=1&c=1&b=1&a=1", strings.NewReader("a=2&b=2&c=2&d=2"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var got []urlvalues.Warning
+		var target Target
+		err := urlvalues.UnmarshalRequest(r, &target, urlvalues.WithWarningHandler(func(w urlvalues.Warning) {
+			got = append(got, w)
+		}))
+		if err != nil {
+			t.Fatalf("urlvalues.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &target, err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Fatalf("warnings -got +want\n%s", diff)
+		}
+	}
+}
+
+func TestUnmarshalRequest_WithMaxBodySize(t *testing.T) {
+	var target struct {
+		Name string `urlvalue:"name"`
+	}
+	body := "name=" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		name    string
+		opts    []urlvalues.SetParseOptionFunc
+		wantErr bool
+	}{
+		{"default", nil, false},
+		{"exceeded", []urlvalues.SetParseOptionFunc{urlvalues.WithMaxBodySize(16)}, true},
+		{"unlimited", []urlvalues.SetParseOptionFunc{urlvalues.WithMaxBodySize(-1)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			err := urlvalues.UnmarshalRequest(r, &target, tt.opts...)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("urlvalues.UnmarshalRequest(%v, %v) = %q, want <nil>", r, &target, err)
+				}
+				return
+			}
+			var tooLarge *http.MaxBytesError
+			if !errors.Is(err, urlvalues.ErrInvalidForm) || !errors.As(err, &tooLarge) {
+				t.Errorf("urlvalues.UnmarshalRequest(%v, %v) = %v, want %v wrapping *http.MaxBytesError", r, &target, err, urlvalues.ErrInvalidForm)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package rsql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nahojer/urlvalues/internal/nesting"
+)
+
+// reserved holds the characters that unquoted selectors and arguments may not
+// contain.
+const reserved = "\"'();,=!~<> \t\n\r"
+
+// isKeyword reports whether s is a keyword of a logical operator.
+func isKeyword(s string) bool {
+	return s == "and" || s == "or"
+}
+
+// Parse parses the RSQL expression s into a syntax tree. Logical nodes with a
+// single operand are collapsed into that operand.
+func Parse(s string) (Node, error) {
+	p := &parser{s: s}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return node, nil
+}
+
+type parser struct {
+	s   string
+	pos int
+	// Depth of nested expressions being parsed.
+	depth int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid rsql expression at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// enter enters a nested expression, returning an error if it is nested
+// deeper than nesting.MaxDepth. Each call must be paired with a call to leave.
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > nesting.MaxDepth {
+		return p.errorf("expression nested deeper than %d levels", nesting.MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// consume consumes the logical operator op, given as either its symbol or its
+// keyword surrounded by whitespace, reporting whether it was consumed.
+func (p *parser) consume(symbol byte, keyword string) bool {
+	start := p.pos
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == symbol {
+		p.pos++
+		return true
+	}
+	if p.pos > start && strings.HasPrefix(p.s[p.pos:], keyword) {
+		end := p.pos + len(keyword)
+		if end < len(p.s) && strings.IndexByte(" \t\n\r", p.s[end]) >= 0 {
+			p.pos = end
+			return true
+		}
+	}
+	p.pos = start
+	return false
+}
+
+func (p *parser) parseOr() (Node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	var operands []Node
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, node)
+		if !p.consume(',', "or") {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &Or{Operands: operands}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var operands []Node
+	for {
+		node, err := p.parseConstraint()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, node)
+		if !p.consume(';', "and") {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &And{Operands: operands}, nil
+}
+
+func (p *parser) parseConstraint() (Node, error) {
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (*Comparison, error) {
+	selector := p.unreserved()
+	if selector == "" {
+		return nil, p.errorf("missing selector")
+	}
+
+	p.skipSpace()
+	op, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+
+	c := &Comparison{Selector: selector, Operator: op}
+	if p.pos < len(p.s) && p.s[p.pos] == '(' {
+		p.pos++
+		for {
+			p.skipSpace()
+			arg, err := p.parseArgument()
+			if err != nil {
+				return nil, err
+			}
+			c.Arguments = append(c.Arguments, arg)
+			p.skipSpace()
+			if p.pos < len(p.s) && p.s[p.pos] == ',' {
+				p.pos++
+				continue
+			}
+			break
+		}
+		if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+			return nil, p.errorf("missing ) after arguments")
+		}
+		p.pos++
+		return c, nil
+	}
+
+	arg, err := p.parseArgument()
+	if err != nil {
+		return nil, err
+	}
+	c.Arguments = []string{arg}
+	return c, nil
+}
+
+// aliases maps the aliases of comparison operators to their FIQL forms.
+var aliases = map[string]string{
+	"<":  "=lt=",
+	"<=": "=le=",
+	">":  "=gt=",
+	">=": "=ge=",
+}
+
+func (p *parser) parseOperator() (string, error) {
+	rest := p.s[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "!="):
+		p.pos += 2
+		return "!=", nil
+	case strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, ">="):
+		p.pos += 2
+		return aliases[rest[:2]], nil
+	case strings.HasPrefix(rest, "<"), strings.HasPrefix(rest, ">"):
+		p.pos++
+		return aliases[rest[:1]], nil
+	case strings.HasPrefix(rest, "="):
+		// FIQL operators are letters or dashes between equal signs, such as
+		// "=gt=", or nothing, as in "==".
+		i := 1
+		for i < len(rest) && (rest[i] == '-' || 'a' <= rest[i] && rest[i] <= 'z' || 'A' <= rest[i] && rest[i] <= 'Z') {
+			i++
+		}
+		if i < len(rest) && rest[i] == '=' {
+			p.pos += i + 1
+			return rest[:i+1], nil
+		}
+	}
+	return "", p.errorf("missing comparison operator")
+}
+
+func (p *parser) parseArgument() (string, error) {
+	if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		return p.quoted()
+	}
+	arg := p.unreserved()
+	if arg == "" {
+		return "", p.errorf("missing argument")
+	}
+	return arg, nil
+}
+
+// unreserved consumes and returns the longest run of unreserved characters.
+func (p *parser) unreserved() string {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte(reserved, p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// quoted consumes and returns a string in double or single quotes, in which
+// backslashes escape the next character.
+func (p *parser) quoted() (string, error) {
+	q := p.s[p.pos]
+	start := p.pos
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch c := p.s[p.pos]; {
+		case c == '\\' && p.pos+1 < len(p.s):
+			p.pos++
+			b.WriteByte(p.s[p.pos])
+		case c == q:
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated quote")
+}
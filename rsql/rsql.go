@@ -0,0 +1,177 @@
+// Package rsql parses RSQL filter expressions, a superset of FIQL, into
+// abstract syntax trees, and provides the [Filter] field type decoding them
+// in structs decoded by [urlvalues.Unmarshal].
+//
+// An expression is made up of comparisons, such as "age=gt=30", joined by
+// semicolons (;) or "and" for logical and, and commas (,) or "or" for logical
+// or, where and binds tighter than or. Parentheses group expressions, such as
+// in "name==foo;(age=gt=30,age=lt=18)".
+//
+// Note that semicolons and commas must be escaped as %3B and %2C in URL
+// queries, since [url.ParseQuery] rejects unescaped semicolons.
+//
+// See https://github.com/jirutka/rsql-parser for the grammar.
+package rsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Node is a node of the syntax tree of an expression: an [*And], [*Or] or
+// [*Comparison].
+type Node interface {
+	// String returns the node as an RSQL expression.
+	String() string
+	node()
+}
+
+// And is the logical and of its operands.
+type And struct {
+	Operands []Node
+}
+
+// Or is the logical or of its operands.
+type Or struct {
+	Operands []Node
+}
+
+// Comparison compares the value of a selector, such as a field name, to its
+// arguments by an operator.
+type Comparison struct {
+	// Selector is the identifier of what is compared, such as "age".
+	Selector string
+	// Operator is the comparison operator in its FIQL form, such as "==",
+	// "!=" or "=gt=". The aliases "<", "<=", ">" and ">=" are normalized to
+	// "=lt=", "=le=", "=gt=" and "=ge=".
+	Operator string
+	// Arguments holds one value, or several values given in parentheses,
+	// such as for the "=in=" operator.
+	Arguments []string
+}
+
+func (*And) node()        {}
+func (*Or) node()         {}
+func (*Comparison) node() {}
+
+func (n *And) String() string {
+	ops := make([]string, len(n.Operands))
+	for i, op := range n.Operands {
+		ops[i] = op.String()
+		// Or binds looser than and.
+		if _, ok := op.(*Or); ok {
+			ops[i] = "(" + ops[i] + ")"
+		}
+	}
+	return strings.Join(ops, ";")
+}
+
+func (n *Or) String() string {
+	ops := make([]string, len(n.Operands))
+	for i, op := range n.Operands {
+		ops[i] = op.String()
+	}
+	return strings.Join(ops, ",")
+}
+
+func (n *Comparison) String() string {
+	args := make([]string, len(n.Arguments))
+	for i, arg := range n.Arguments {
+		args[i] = quote(arg)
+	}
+	if len(args) == 1 {
+		return n.Selector + n.Operator + args[0]
+	}
+	return n.Selector + n.Operator + "(" + strings.Join(args, ",") + ")"
+}
+
+// quote returns arg quoted if it contains reserved characters.
+func quote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, reserved) && !isKeyword(arg) {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+// Visitor translates the nodes of a syntax tree into values of type T, such
+// as SQL conditions or database queries. See [Visit].
+type Visitor[T any] interface {
+	// VisitAnd translates the logical and of operands, already translated.
+	VisitAnd(operands []T) (T, error)
+	// VisitOr translates the logical or of operands, already translated.
+	VisitOr(operands []T) (T, error)
+	// VisitComparison translates a comparison.
+	VisitComparison(c *Comparison) (T, error)
+}
+
+// Visit translates node by v, translating the operands of logical nodes
+// before the nodes themselves. The first error returned by v is returned.
+func Visit[T any](node Node, v Visitor[T]) (T, error) {
+	var zero T
+	switch n := node.(type) {
+	case *And:
+		operands, err := visitAll(n.Operands, v)
+		if err != nil {
+			return zero, err
+		}
+		return v.VisitAnd(operands)
+	case *Or:
+		operands, err := visitAll(n.Operands, v)
+		if err != nil {
+			return zero, err
+		}
+		return v.VisitOr(operands)
+	case *Comparison:
+		return v.VisitComparison(n)
+	default:
+		return zero, fmt.Errorf("rsql: unknown node %T", node)
+	}
+}
+
+func visitAll[T any](nodes []Node, v Visitor[T]) ([]T, error) {
+	ts := make([]T, len(nodes))
+	for i, n := range nodes {
+		t, err := Visit(n, v)
+		if err != nil {
+			return nil, err
+		}
+		ts[i] = t
+	}
+	return ts, nil
+}
+
+// Filter is a field type decoding an RSQL expression, such as in
+// `urlvalue:"filter"` for "?filter=name==foo%3Bage=gt=30".
+type Filter struct {
+	// Node is the root of the syntax tree, or nil if the filter was not
+	// decoded.
+	Node Node
+}
+
+// String returns the filter as an RSQL expression.
+func (f Filter) String() string {
+	if f.Node == nil {
+		return ""
+	}
+	return f.Node.String()
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (f Filter) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Empty text decodes to
+// a filter without a syntax tree.
+func (f *Filter) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "" {
+		f.Node = nil
+		return nil
+	}
+	node, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	f.Node = node
+	return nil
+}
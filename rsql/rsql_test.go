@@ -0,0 +1,153 @@
+package rsql_test
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+	"github.com/nahojer/urlvalues/rsql"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want rsql.Node
+	}{
+		{"name==foo", &rsql.Comparison{Selector: "name", Operator: "==", Arguments: []string{"foo"}}},
+		{
+			in: "name==foo;age=gt=30",
+			want: &rsql.And{Operands: []rsql.Node{
+				&rsql.Comparison{Selector: "name", Operator: "==", Arguments: []string{"foo"}},
+				&rsql.Comparison{Selector: "age", Operator: "=gt=", Arguments: []string{"30"}},
+			}},
+		},
+		{
+			in: "a==1,b==2;c==3",
+			want: &rsql.Or{Operands: []rsql.Node{
+				&rsql.Comparison{Selector: "a", Operator: "==", Arguments: []string{"1"}},
+				&rsql.And{Operands: []rsql.Node{
+					&rsql.Comparison{Selector: "b", Operator: "==", Arguments: []string{"2"}},
+					&rsql.Comparison{Selector: "c", Operator: "==", Arguments: []string{"3"}},
+				}},
+			}},
+		},
+		{
+			in: "(a==1 or b==2) and c<=3",
+			want: &rsql.And{Operands: []rsql.Node{
+				&rsql.Or{Operands: []rsql.Node{
+					&rsql.Comparison{Selector: "a", Operator: "==", Arguments: []string{"1"}},
+					&rsql.Comparison{Selector: "b", Operator: "==", Arguments: []string{"2"}},
+				}},
+				&rsql.Comparison{Selector: "c", Operator: "=le=", Arguments: []string{"3"}},
+			}},
+		},
+		{`genre=in=(sci-fi, "action movies")`, &rsql.Comparison{Selector: "genre", Operator: "=in=", Arguments: []string{"sci-fi", "action movies"}}},
+		{`title=='Don\'t panic'`, &rsql.Comparison{Selector: "title", Operator: "==", Arguments: []string{"Don't panic"}}},
+		{"author.name!=*Doe", &rsql.Comparison{Selector: "author.name", Operator: "!=", Arguments: []string{"*Doe"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := rsql.Parse(tt.in)
+			if err != nil {
+				t.Fatalf("rsql.Parse(%q) = %q, want <nil>", tt.in, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("rsql.Parse(...) -got +want\n%s", diff)
+			}
+
+			// The string form parses into the same tree.
+			reparsed, err := rsql.Parse(got.String())
+			if err != nil {
+				t.Fatalf("rsql.Parse(%q) = %q, want <nil>", got.String(), err)
+			}
+			if diff := cmp.Diff(reparsed, tt.want); diff != "" {
+				t.Errorf("rsql.Parse(%q) -got +want\n%s", got.String(), diff)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "name", "name==", "name=foo", "(a==1", "a==1;", "a=in=(1,2", `a=="x`, "a==1 b==2"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if _, err := rsql.Parse(in); err == nil {
+				t.Errorf("rsql.Parse(%q) = <nil>, want error", in)
+			}
+		})
+	}
+
+	t.Run("nesting", func(t *testing.T) {
+		nested := func(n int) string {
+			return strings.Repeat("(", n) + "a==1" + strings.Repeat(")", n)
+		}
+		if _, err := rsql.Parse(nested(50)); err != nil {
+			t.Errorf("rsql.Parse(<50 nested groups>) = %q, want <nil>", err)
+		}
+		if _, err := rsql.Parse(nested(1_000_000)); err == nil {
+			t.Errorf("rsql.Parse(<1000000 nested groups>) = <nil>, want error")
+		}
+	})
+}
+
+// sqlVisitor translates syntax trees into SQL conditions.
+type sqlVisitor struct{}
+
+func (sqlVisitor) VisitAnd(operands []string) (string, error) {
+	return "(" + strings.Join(operands, " AND ") + ")", nil
+}
+
+func (sqlVisitor) VisitOr(operands []string) (string, error) {
+	return "(" + strings.Join(operands, " OR ") + ")", nil
+}
+
+func (sqlVisitor) VisitComparison(c *rsql.Comparison) (string, error) {
+	ops := map[string]string{"==": "=", "!=": "<>", "=gt=": ">", "=lt=": "<"}
+	op, ok := ops[c.Operator]
+	if !ok {
+		return "", fmt.Errorf("unsupported operator %s", c.Operator)
+	}
+	return fmt.Sprintf("%s %s '%s'", c.Selector, op, c.Arguments[0]), nil
+}
+
+func TestVisit(t *testing.T) {
+	node, err := rsql.Parse("name==foo;(age=gt=30,age=lt=18)")
+	if err != nil {
+		t.Fatalf("rsql.Parse(...) = %q, want <nil>", err)
+	}
+	got, err := rsql.Visit[string](node, sqlVisitor{})
+	if err != nil {
+		t.Fatalf("rsql.Visit(...) = %q, want <nil>", err)
+	}
+	want := "(name = 'foo' AND (age > '30' OR age < '18'))"
+	if got != want {
+		t.Errorf("rsql.Visit(...) = %q, want %q", got, want)
+	}
+
+	node, _ = rsql.Parse("a=in=(1,2)")
+	if _, err := rsql.Visit[string](node, sqlVisitor{}); err == nil {
+		t.Errorf("rsql.Visit(...) = <nil>, want error")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var target struct {
+		Filter rsql.Filter `urlvalue:"filter"`
+		Limit  int         `urlvalue:"limit"`
+	}
+	in := url.Values{"filter": {"name==foo;age=gt=30"}, "limit": {"10"}}
+	if err := urlvalues.Unmarshal(in, &target); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+	}
+	if got, want := target.Filter.String(), "name==foo;age=gt=30"; got != want {
+		t.Errorf("Filter = %q, want %q", got, want)
+	}
+
+	got, err := urlvalues.Marshal(target)
+	if err != nil {
+		t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", target, err)
+	}
+	if diff := cmp.Diff(got, in); diff != "" {
+		t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+	}
+}
@@ -0,0 +1,143 @@
+package urlvalues
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SearchTerm is a term of a [SearchQuery].
+type SearchTerm struct {
+	// Key of a qualifier, such as "host" in "host:db1", or empty if the term
+	// is not a qualifier.
+	Key string
+	// Value of the term, without any quotes.
+	Value string
+	// Whether the value was given as a quoted phrase, such as
+	// "connection refused".
+	Phrase bool
+	// Whether the term was negated by a minus sign (-), such as in "-debug".
+	Negated bool
+}
+
+// String returns the term in the syntax of a SearchQuery.
+func (t SearchTerm) String() string {
+	var b strings.Builder
+	if t.Negated {
+		b.WriteByte('-')
+	}
+	if t.Key != "" {
+		b.WriteString(t.Key)
+		b.WriteByte(':')
+	}
+	if t.Phrase || t.Value == "" || strings.ContainsFunc(t.Value, unicode.IsSpace) {
+		b.WriteString(`"` + t.Value + `"`)
+	} else {
+		b.WriteString(t.Value)
+	}
+	return b.String()
+}
+
+// SearchQuery is the text of a search box tokenized into terms, such as
+// `error "connection refused" -debug host:db1`. Terms are separated by
+// whitespace, and may be:
+//
+//   - words, such as error;
+//   - phrases in double quotes, such as "connection refused";
+//   - negated by a minus sign (-), such as -debug;
+//   - qualifiers of a key and a value separated by a colon (:), such as
+//     host:db1 or host:"db 1".
+//
+// Decoding a SearchQuery never fails: a quote without a closing quote
+// extends to the end of the text.
+type SearchQuery []SearchTerm
+
+// Terms returns the values of the terms that are neither qualifiers nor
+// negated, such as the words and phrases to search for.
+func (q SearchQuery) Terms() []string {
+	var terms []string
+	for _, t := range q {
+		if t.Key == "" && !t.Negated {
+			terms = append(terms, t.Value)
+		}
+	}
+	return terms
+}
+
+// Qualifiers returns the values of the qualifiers of key that are not
+// negated, in order.
+func (q SearchQuery) Qualifiers(key string) []string {
+	var values []string
+	for _, t := range q {
+		if t.Key == key && !t.Negated {
+			values = append(values, t.Value)
+		}
+	}
+	return values
+}
+
+// String returns the terms of q separated by spaces.
+func (q SearchQuery) String() string {
+	terms := make([]string, len(q))
+	for i, t := range q {
+		terms[i] = t.String()
+	}
+	return strings.Join(terms, " ")
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (q SearchQuery) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (q *SearchQuery) UnmarshalText(text []byte) error {
+	*q = tokenizeSearch(string(text))
+	return nil
+}
+
+// tokenizeSearch tokenizes s into terms. See SearchQuery.
+func tokenizeSearch(s string) SearchQuery {
+	q := SearchQuery{}
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return q
+		}
+
+		var t SearchTerm
+		if len(s) > 1 && s[0] == '-' && !unicode.IsSpace(rune(s[1])) {
+			t.Negated = true
+			s = s[1:]
+		}
+
+		// Qualifiers are keyed by a word before a colon, with a value.
+		if s[0] != '"' {
+			end := strings.IndexFunc(s, unicode.IsSpace)
+			if end < 0 {
+				end = len(s)
+			}
+			if key, value, ok := strings.Cut(s[:end], ":"); ok && key != "" && value != "" && !strings.Contains(key, `"`) {
+				t.Key = key
+				s = s[len(key)+1:]
+			}
+		}
+
+		t.Value, t.Phrase, s = cutSearchValue(s)
+		q = append(q, t)
+	}
+}
+
+// cutSearchValue cuts the first value off s, either a phrase in double quotes
+// or a word ending at whitespace, returning the value, whether it is a
+// phrase, and the rest of s.
+func cutSearchValue(s string) (value string, phrase bool, rest string) {
+	if strings.HasPrefix(s, `"`) {
+		value, rest, _ = strings.Cut(s[1:], `"`)
+		return value, true, rest
+	}
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end < 0 {
+		return s, false, ""
+	}
+	return s[:end], false, s[end:]
+}
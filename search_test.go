@@ -0,0 +1,77 @@
+package urlvalues_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestSearchQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want urlvalues.SearchQuery
+	}{
+		{"", urlvalues.SearchQuery{}},
+		{
+			in: `error "connection refused" -debug host:db1`,
+			want: urlvalues.SearchQuery{
+				{Value: "error"},
+				{Value: "connection refused", Phrase: true},
+				{Value: "debug", Negated: true},
+				{Key: "host", Value: "db1"},
+			},
+		},
+		{
+			in: `-host:"db 1" -"stack trace" a:b:c`,
+			want: urlvalues.SearchQuery{
+				{Key: "host", Value: "db 1", Phrase: true, Negated: true},
+				{Value: "stack trace", Phrase: true, Negated: true},
+				{Key: "a", Value: "b:c"},
+			},
+		},
+		{
+			in: `- host: :x "unterminated phrase`,
+			want: urlvalues.SearchQuery{
+				{Value: "-"},
+				{Value: "host:"},
+				{Value: ":x"},
+				{Value: "unterminated phrase", Phrase: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			in := url.Values{"q": {tt.in}}
+			var target struct {
+				Query urlvalues.SearchQuery `urlvalue:"q"`
+			}
+			if err := urlvalues.Unmarshal(in, &target); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+			}
+			if diff := cmp.Diff(target.Query, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+
+			var reparsed urlvalues.SearchQuery
+			if err := reparsed.UnmarshalText([]byte(target.Query.String())); err != nil {
+				t.Fatalf("UnmarshalText(%q) = %q, want <nil>", target.Query.String(), err)
+			}
+			if diff := cmp.Diff(reparsed, tt.want); diff != "" {
+				t.Errorf("UnmarshalText(%q) -got +want\n%s", target.Query.String(), diff)
+			}
+		})
+	}
+
+	t.Run("accessors", func(t *testing.T) {
+		var q urlvalues.SearchQuery
+		_ = q.UnmarshalText([]byte(`error "connection refused" -debug host:db1 host:db2 -host:db3`))
+		if diff := cmp.Diff(q.Terms(), []string{"error", "connection refused"}); diff != "" {
+			t.Errorf("Terms() -got +want\n%s", diff)
+		}
+		if diff := cmp.Diff(q.Qualifiers("host"), []string{"db1", "db2"}); diff != "" {
+			t.Errorf("Qualifiers(%q) -got +want\n%s", "host", diff)
+		}
+	})
+}
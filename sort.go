@@ -0,0 +1,100 @@
+package urlvalues
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SortField is a field to sort by, as given in a [Sort] expression.
+type SortField struct {
+	// Name of the field to sort by.
+	Field string
+	// Whether to sort in descending rather than ascending order.
+	Descending bool
+}
+
+// String returns f as a sort expression, with a minus sign (-) before the
+// name of the field if descending.
+func (f SortField) String() string {
+	if f.Descending {
+		return "-" + f.Field
+	}
+	return f.Field
+}
+
+// Sort is the order to sort the results of a list endpoint in, decoded from a
+// comma-separated list of fields such as "-created_at,name". Fields prefixed
+// by a minus sign (-) are sorted in descending order, while fields without a
+// prefix or with a plus sign (+) are sorted in ascending order. A field may
+// only be listed once.
+//
+// The fields that may be sorted by are declared by the "oneof" tag option,
+// such as in `urlvalue:"sort,oneof:created_at|name"`.
+type Sort []SortField
+
+// String returns s as a comma-separated sort expression.
+func (s Sort) String() string {
+	var b strings.Builder
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s Sort) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (s *Sort) UnmarshalText(text []byte) error {
+	sort := Sort{}
+	for _, item := range strings.Split(string(text), ",") {
+		// Plus signs unescaped as spaces are trimmed along with the spaces.
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		var f SortField
+		switch item[0] {
+		case '-':
+			f.Descending = true
+			item = item[1:]
+		case '+':
+			item = item[1:]
+		}
+		f.Field = strings.TrimSpace(item)
+		if f.Field == "" {
+			return fmt.Errorf("sort field missing a name")
+		}
+		if slices.ContainsFunc(sort, func(g SortField) bool { return g.Field == f.Field }) {
+			return fmt.Errorf("sort field %q given more than once", f.Field)
+		}
+		sort = append(sort, f)
+	}
+	*s = sort
+	return nil
+}
+
+var sortType = reflect.TypeFor[Sort]()
+
+// validateSort returns a ConstraintError if any of the fields of s is not one
+// of the fields allowed by the "oneof" tag option.
+func validateSort(s Sort, oneOf []string) *ConstraintError {
+	for _, f := range s {
+		if !slices.Contains(oneOf, f.Field) {
+			return &ConstraintError{
+				Constraint: "oneof",
+				Limit:      strings.Join(oneOf, "|"),
+				msg:        fmt.Sprintf("cannot sort by %q, want one of %s", f.Field, strings.Join(oneOf, ", ")),
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestSort(t *testing.T) {
+	type Target struct {
+		Sort urlvalues.Sort `urlvalue:"sort,default:-created_at,oneof:created_at|name"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want urlvalues.Sort
+	}{
+		{"default", url.Values{}, urlvalues.Sort{{Field: "created_at", Descending: true}}},
+		{"prefixes", url.Values{"sort": {"-created_at,+name"}}, urlvalues.Sort{{Field: "created_at", Descending: true}, {Field: "name"}}},
+		{"unescaped plus", url.Values{"sort": {" name"}}, urlvalues.Sort{{Field: "name"}}},
+		{"empty", url.Values{"sort": {""}}, urlvalues.Sort{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			if err := urlvalues.Unmarshal(tt.in, &target); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+			}
+			if diff := cmp.Diff(target.Sort, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		in := url.Values{"sort": {"name,-password"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+
+		var constrErr *urlvalues.ConstraintError
+		if !errors.As(err, &constrErr) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ConstraintError", in, &target, err)
+		}
+		if constrErr.Constraint != "oneof" {
+			t.Errorf("Constraint = %q, want %q", constrErr.Constraint, "oneof")
+		}
+	})
+
+	for _, value := range []string{"-", "name,-name"} {
+		t.Run("invalid "+value, func(t *testing.T) {
+			in := url.Values{"sort": {value}}
+			var target Target
+			if err := urlvalues.Unmarshal(in, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+			}
+		})
+	}
+
+	t.Run("marshal", func(t *testing.T) {
+		in := Target{Sort: urlvalues.Sort{{Field: "name"}, {Field: "created_at", Descending: true}}}
+		got, err := urlvalues.Marshal(in)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+		}
+		want := url.Values{"sort": {"name,-created_at"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Marshal(...) -got +want\n%s", diff)
+		}
+	})
+}
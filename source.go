@@ -0,0 +1,108 @@
+package urlvalues
+
+import (
+	"mime/multipart"
+	"net/url"
+	"slices"
+)
+
+// Names of the sources that fields can be decoded from, as referenced by the
+// "source" tag option.
+const (
+	sourceQuery  = "query"
+	sourceForm   = "form"
+	sourcePath   = "path"
+	sourceHeader = "header"
+	sourceCookie = "cookie"
+)
+
+// isSource reports whether name is the name of a known source.
+func isSource(name string) bool {
+	switch name {
+	case sourceQuery, sourceForm, sourcePath, sourceHeader, sourceCookie:
+		return true
+	}
+	return false
+}
+
+// A lookup returns the values of key in a source, or nil if key is not
+// present.
+type lookup func(key string) []string
+
+// valuesLookup returns a lookup of the values in data.
+func valuesLookup(data url.Values) lookup {
+	return func(key string) []string {
+		return data[key]
+	}
+}
+
+// A keyLister returns the keys present in a source, in lexical order.
+type keyLister func() []string
+
+// valuesKeys returns a keyLister of the keys in data.
+func valuesKeys(data url.Values) keyLister {
+	return func() []string {
+		return keysOf(data)
+	}
+}
+
+// keysOf returns the keys of data in lexical order, so that work derived from
+// the keys, such as emitting warnings, is reproducible.
+func keysOf(data url.Values) []string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// input holds everything that fields can be decoded from.
+type input struct {
+	// Lookup of fields without a "source" tag option.
+	values lookup
+	// Lookups of fields with a "source" tag option, keyed by source name.
+	// Fields of sources missing from the map are left untouched.
+	sources map[string]lookup
+	// Listers of the keys of values and sources, for fields decoded from
+	// keys that are not known in advance, such as Fieldsets. Sources without
+	// a lister are treated as having no keys.
+	valueKeys  keyLister
+	sourceKeys map[string]keyLister
+	// Converts keys to the form returned by the listers, for sources whose
+	// lookups normalize keys, such as HTTP headers. Nil if keys are listed as
+	// they are looked up.
+	canonicalKey func(key string) string
+	// Uploaded files of multipart forms.
+	files map[string][]*multipart.FileHeader
+}
+
+// lookup returns the lookup of fields with the given "source" tag option, or
+// nil if the source is not available.
+func (in input) lookup(source string) lookup {
+	if source == "" {
+		return in.values
+	}
+	return in.sources[source]
+}
+
+// canonical returns key in the form returned by the key listers of in.
+func (in input) canonical(key string) string {
+	if in.canonicalKey == nil {
+		return key
+	}
+	return in.canonicalKey(key)
+}
+
+// keys returns the keys present in the source of fields with the given
+// "source" tag option.
+func (in input) keys(source string) []string {
+	list := in.valueKeys
+	if source != "" {
+		list = in.sourceKeys[source]
+	}
+	if list == nil {
+		return nil
+	}
+	return list()
+}
@@ -1,97 +1,288 @@
 package urlvalues
 
 import (
-	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 func parseTime(layout, value string) (time.Time, error) {
-	now := time.Now()
-	if value == "now" {
-		return now, nil
+	// Parse time based on now. For example, "now-2y+3m-2d" subtracts 2 years,
+	// adds 3 months and subtracts 2 days from now.
+	if isNowBased(value) {
+		return addDateOffsets(time.Now(), value[len("now"):], len("now"))
 	}
 
-	// Parse time based on now. For example, "now-2y+3m-2d" subtracts 2 years,
-	// adds 3 months and subtracts 3 days from now.
-	if strings.HasPrefix(value, "now") {
-		var years, months, days int
-
-		// Remove "now" prefix from value.
-		value = strings.TrimSpace(value[3:])
-
-		// Separate parts by space so that we can split on it.
-		value = strings.TrimSpace(strings.NewReplacer("+", " +", "-", " -").Replace(value))
-
-		parts := strings.Split(value, " ")
-		for _, part := range parts {
-			// A part consist of at least 3 characters: a sign (+-), followed by one or
-			// more digits, followed by a year/month/day (y/m/d) identifier.
-			if len(part) < 3 {
-				return time.Time{}, errors.New("invalid \"now\" based format")
-			}
-
-			var sign int
-			switch part[0] {
-			case '+':
-				sign = 1
-			case '-':
-				sign = -1
-			default:
-				return time.Time{}, fmt.Errorf("invalid sign %q", part[0])
-			}
-
-			v, err := strconv.Atoi(part[1 : len(part)-1])
-			if err != nil {
-				return time.Time{}, fmt.Errorf("%q is not a valid integer", part[1:len(part)-1])
-			}
-
-			switch part[len(part)-1] {
-			case 'y':
-				years = sign * v
-			case 'm':
-				months = sign * v
-			case 'd':
-				days = sign * v
-			default:
-				return time.Time{}, fmt.Errorf("invalid year/month/day identifier %q", part[len(part)-1])
-			}
+	if layout == autoLayout {
+		return parseAutoTime(value)
+	}
+	if layout == quarterLayout {
+		q, err := parseQuarter(value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return q.Start(), nil
+	}
+
+	t, err := time.Parse(timeLayout(layout), value)
+	if err != nil && layout == "" {
+		// Fall back to the native parsing of time.Time, so that ISO 8601
+		// timestamps work without a layout. The error of the default layout
+		// is reported, since that is the layout the field asks for.
+		var native time.Time
+		if native.UnmarshalText([]byte(value)) == nil {
+			return native, nil
+		}
+	}
+	return t, err
+}
+
+// parseAgo parses value as a duration before now, such as "24h" for 24 hours
+// ago, as understood by time.ParseDuration. Values that aren't durations are
+// parsed by parse in layout, such that encoded times decode as well. The error
+// of parsing the duration is returned if both fail.
+func parseAgo(parse func(layout, value string) (time.Time, error), layout, value string) (time.Time, error) {
+	d, err := time.ParseDuration(value)
+	if err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, perr := parse(layout, value); perr == nil {
+		return t, nil
+	}
+	return time.Time{}, err
+}
+
+// isTimeType reports whether typ is time.Time, or a pointer to or slice of
+// it.
+func isTimeType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	return typ == timeType
+}
+
+// isNowBased reports whether value is relative to now, such as "now-1d" or
+// "NOW - 1D".
+func isNowBased(value string) bool {
+	return len(value) >= len("now") && strings.EqualFold(value[:len("now")], "now")
+}
+
+// addDateOffsets adds the years (y), months (m) and days (d) of the offsets in
+// s to t, such as "-2y+3m-2d", as used by "now" based values. Each offset is a
+// sign (+ or -) followed by a number and a unit, all of which may be separated
+// by spaces, and units are case-insensitive, such as in "- 2Y + 3m". The sign
+// of the first offset may be omitted, in which case it is added. Offsets of
+// the same unit add up. s starts at the zero-based position pos of the value
+// it is part of, such that errors report positions within that value.
+func addDateOffsets(t time.Time, s string, pos int) (time.Time, error) {
+	var years, months, days int
+	i := 0
+	skipSpaces := func() {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+			i++
+		}
+	}
+
+	for term := 0; ; term++ {
+		skipSpaces()
+		if i == len(s) {
+			break
+		}
+
+		sign := 1
+		switch {
+		case s[i] == '+':
+			i++
+		case s[i] == '-':
+			sign = -1
+			i++
+		case term > 0 || !isDigit(s[i]):
+			return time.Time{}, offsetError(s, i, pos, "sign (+ or -)")
+		}
+
+		skipSpaces()
+		start := i
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		if i == start {
+			return time.Time{}, offsetError(s, i, pos, "number")
+		}
+		n, err := strconv.Atoi(s[start:i])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("number %s at position %d out of range", s[start:i], pos+start+1)
+		}
+		n *= sign
+
+		skipSpaces()
+		if i == len(s) {
+			return time.Time{}, offsetError(s, i, pos, "unit (y, m or d)")
+		}
+		switch s[i] {
+		case 'y', 'Y':
+			years += n
+		case 'm', 'M':
+			months += n
+		case 'd', 'D':
+			days += n
+		default:
+			return time.Time{}, offsetError(s, i, pos, "unit (y, m or d)")
+		}
+		i++
+	}
+
+	return t.AddDate(years, months, days), nil
+}
+
+// isDigit reports whether c is a decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// offsetError returns an error telling that want was expected at index i of
+// s, which starts at position pos of the value it is part of. Positions are
+// reported one-based.
+func offsetError(s string, i, pos int, want string) error {
+	if i == len(s) {
+		return fmt.Errorf("want %s at position %d, got end of value", want, pos+i+1)
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return fmt.Errorf("want %s at position %d, got %q", want, pos+i+1, r)
+}
+
+// autoLayout is the name of the layout that detects the layout of values
+// among autoLayouts.
+const autoLayout = "Auto"
+
+// autoLayouts are the layouts tried in order by the "Auto" layout.
+var autoLayouts = []string{time.RFC3339, time.RFC3339Nano, time.DateOnly, time.DateTime}
+
+// parseAutoTime parses value using the first of autoLayouts that matches. The
+// error of the first layout is returned if none match.
+func parseAutoTime(value string) (time.Time, error) {
+	var firstErr error
+	for _, layout := range autoLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+		if firstErr == nil {
+			firstErr = err
 		}
+	}
+	return time.Time{}, firstErr
+}
+
+// fieldLayout returns the name of the layout of a time.Time field, which is
+// "Auto" if the field has no "layout" option and pOpts auto-detects layouts.
+func fieldLayout(fOpts fieldOptions, pOpts ParseOptions) string {
+	if fOpts.layout == "" && pOpts.autoTimeLayout {
+		return autoLayout
+	}
+	return fOpts.layout
+}
+
+// parseUnixTime parses value as the number of seconds since the Unix epoch, or
+// as a "now" based value. The seconds may have a fractional part of up to
+// nanosecond precision, such as "1700000000.123", as JavaScript clients emit
+// for Date.now()/1000. Further digits are truncated. The layout is ignored,
+// but accepted for symmetry with parseTime.
+func parseUnixTime(layout, value string) (time.Time, error) {
+	if isNowBased(value) {
+		return parseTime(layout, value)
+	}
+	secs, frac, hasFrac := strings.Cut(value, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !hasFrac {
+		return time.Unix(sec, 0), nil
+	}
+
+	if frac == "" || strings.TrimLeft(frac, "0123456789") != "" {
+		return time.Time{}, fmt.Errorf("invalid fractional seconds %q", frac)
+	}
+	if len(frac) > 9 {
+		frac = frac[:9]
+	}
+	nsec, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+	if strings.HasPrefix(secs, "-") {
+		nsec = -nsec
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+// formatUnixTime formats t as the number of seconds since the Unix epoch,
+// with a fractional part if t has a fraction of a second, such that
+// parseUnixTime parses it back into t.
+func formatUnixTime(t time.Time) string {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if nsec == 0 {
+		return strconv.FormatInt(sec, 10)
+	}
 
-		return now.AddDate(years, months, days), nil
+	sign := ""
+	if sec < 0 {
+		// Unix rounds towards the past, such that the fraction is added to
+		// sec, whereas the fraction of the formatted value is subtracted.
+		sign, sec, nsec = "-", -(sec + 1), 1e9-nsec
 	}
+	frac := strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+	return sign + strconv.FormatInt(sec, 10) + "." + frac
+}
+
+// formatTime formats t in the layout named layout, see timeLayout. The
+// "Quarter" layout formats t as the quarter it is in.
+func formatTime(layout string, t time.Time) string {
+	if layout == quarterLayout {
+		return QuarterOf(t).String()
+	}
+	return t.Format(timeLayout(layout))
+}
 
-	// Allow custom layouts. Valid layouts include the predefined layout constants in the
-	// time package, as well as custom layouts defined by the consumer that time.Parse
-	// understands. Defaults to time.Layout.
+// timeLayout returns the layout named layout. Valid layouts include the
+// predefined layout constants in the time package, as well as custom layouts
+// defined by the consumer that time.Parse understands. Defaults to
+// time.Layout. The "Auto" layout formats times as time.RFC3339Nano.
+func timeLayout(layout string) string {
 	switch layout {
 	case "", "Layout":
-		return time.Parse(time.Layout, value)
+		return time.Layout
+	case autoLayout:
+		return time.RFC3339Nano
 	case "ANSIC":
-		return time.Parse(time.ANSIC, value)
+		return time.ANSIC
 	case "UnixDate":
-		return time.Parse(time.UnixDate, value)
+		return time.UnixDate
 	case "RubyDate":
-		return time.Parse(time.RubyDate, value)
+		return time.RubyDate
 	case "RFC822":
-		return time.Parse(time.RFC822, value)
+		return time.RFC822
 	case "RFC822Z":
-		return time.Parse(time.RFC822Z, value)
+		return time.RFC822Z
 	case "RFC850":
-		return time.Parse(time.RFC850, value)
+		return time.RFC850
 	case "RFC1123":
-		return time.Parse(time.RFC1123, value)
+		return time.RFC1123
 	case "RFC1123Z":
-		return time.Parse(time.RFC1123Z, value)
+		return time.RFC1123Z
 	case "RFC3339":
-		return time.Parse(time.RFC3339, value)
+		return time.RFC3339
 	case "RFC3339Nano":
-		return time.Parse(time.RFC3339Nano, value)
+		return time.RFC3339Nano
 	case "Kitchen":
-		return time.Parse(time.Kitchen, value)
+		return time.Kitchen
+	case "DateTime":
+		return time.DateTime
+	case "DateOnly":
+		return time.DateOnly
+	case "TimeOnly":
+		return time.TimeOnly
 	default:
-		return time.Parse(layout, value)
+		return layout
 	}
 }
@@ -1,6 +1,8 @@
 package urlvalues_test
 
 import (
+	"errors"
+	"net/url"
 	"testing"
 	"time"
 
@@ -29,6 +31,13 @@ func TestParseTime(t *testing.T) {
 		{"now+3y-2m", "", "now+3y-2m", time.Now().AddDate(3, -2, 0)},
 		{"now+2m-7d", "", "now+2m-7d", time.Now().AddDate(0, 2, -7)},
 		{"now+2y+5d", "", "now+2y+5d", time.Now().AddDate(2, 0, 5)},
+		// Native parsing of time.Time without a layout.
+		{"RFC3339 fallback", "", "2024-03-01T12:30:00Z", time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)},
+		{"RFC3339Nano fallback", "", "2024-03-01T12:30:00.5+02:00", time.Date(2024, 3, 1, 10, 30, 0, 5e8, time.UTC)},
+		{"spaces", "", "now - 1y + 2d", time.Now().AddDate(-1, 0, 2)},
+		{"upper case", "", "NOW-1Y+2M-3D", time.Now().AddDate(-1, 2, -3)},
+		{"unsigned first offset", "", "now 10d", time.Now().AddDate(0, 0, 10)},
+		{"repeated unit", "", "now+1d+2d", time.Now().AddDate(0, 0, 3)},
 		// Layout based parsing.
 		{"default layout", "", time.Layout, parseTime(t, time.Layout, time.Layout)},
 		{"custom layout", "2006-01-02", "2006-01-02", parseTime(t, "2006-01-02", "2006-01-02")},
@@ -46,6 +55,13 @@ func TestParseTime(t *testing.T) {
 		{"RFC3339Nano variant 1", "RFC3339Nano", "2006-01-02T15:04:05.999999999Z", parseTime(t, time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z")},
 		{"RFC3339Nano variant 2", "RFC3339Nano", "2006-01-02T15:04:05.999999999+07:00", parseTime(t, time.RFC3339Nano, "2006-01-02T15:04:05.999999999+07:00")},
 		{"Kitchen", "Kitchen", time.Kitchen, parseTime(t, time.Kitchen, time.Kitchen)},
+		{"DateTime", "DateTime", time.DateTime, parseTime(t, time.DateTime, time.DateTime)},
+		{"DateOnly", "DateOnly", time.DateOnly, parseTime(t, time.DateOnly, time.DateOnly)},
+		{"TimeOnly", "TimeOnly", time.TimeOnly, parseTime(t, time.TimeOnly, time.TimeOnly)},
+		{"Auto RFC3339", "Auto", "2006-01-02T15:04:05+07:00", parseTime(t, time.RFC3339, "2006-01-02T15:04:05+07:00")},
+		{"Auto RFC3339Nano", "Auto", "2006-01-02T15:04:05.123Z", parseTime(t, time.RFC3339Nano, "2006-01-02T15:04:05.123Z")},
+		{"Auto DateOnly", "Auto", "2006-01-02", parseTime(t, time.DateOnly, "2006-01-02")},
+		{"Auto DateTime", "Auto", "2006-01-02 15:04:05", parseTime(t, time.DateTime, "2006-01-02 15:04:05")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -70,7 +86,12 @@ func TestParseTime_InvalidInput(t *testing.T) {
 		{"invalid integer 1aa", "", "now-1aad"},
 		{"nvalid sign <", "", "now<1y+1m+1d"},
 		{"invalid identifier <", "", "now+1y+1m+1z"},
+		{"unsigned second offset", "", "now-1d 2d"},
+		{"missing unit", "", "now-1"},
+		{"missing number", "", "now-d"},
 		{"wrong layout", "RFC822", "2006-01-02"},
+		{"no fallback with layout", "DateOnly", "2024-03-01T12:30:00Z"},
+		{"Auto no match", "Auto", "01/02/2006"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -81,6 +102,64 @@ func TestParseTime_InvalidInput(t *testing.T) {
 	}
 }
 
+func TestParseTime_ErrorPosition(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"now-1aad", `want unit (y, m or d) at position 6, got 'a'`},
+		{"now<1y", `want sign (+ or -) at position 4, got '<'`},
+		{"now + 1y 2m", `want sign (+ or -) at position 10, got '2'`},
+		{"now+1y+1", `want unit (y, m or d) at position 9, got end of value`},
+		{"now - ", `want number at position 7, got end of value`},
+		{"now+1é", `want unit (y, m or d) at position 6, got 'é'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := urlvalues.ExportParseTime("", tt.value)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("urlvalues.parseTime(%q, %q) = %v, want %s", "", tt.value, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_WithAutoTimeLayout(t *testing.T) {
+	type Target struct {
+		Since  time.Time `urlvalue:"since"`
+		Before time.Time `urlvalue:"before,layout:2006-01-02"`
+	}
+
+	in := url.Values{"since": {"2024-03-01"}, "before": {"2024-04-01"}}
+	want := Target{
+		Since:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Before: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithAutoTimeLayout()); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	encoded, err := urlvalues.Marshal(got, urlvalues.WithAutoTimeLayout())
+	if err != nil {
+		t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", got, err)
+	}
+	wantEncoded := url.Values{"since": {"2024-03-01T00:00:00Z"}, "before": {"2024-04-01"}}
+	if diff := cmp.Diff(encoded, wantEncoded); diff != "" {
+		t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", got, diff)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+		}
+	})
+}
+
 func parseTime(t *testing.T, layout string, value string) time.Time {
 	t.Helper()
 
@@ -91,3 +170,116 @@ func parseTime(t *testing.T, layout string, value string) time.Time {
 
 	return tim
 }
+
+func TestUnmarshal_FractionalUnixTime(t *testing.T) {
+	type Target struct {
+		At time.Time `urlvalue:"at"`
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"1700000000", time.Unix(1700000000, 0)},
+		{"1700000000.123", time.Unix(1700000000, 123e6)},
+		{"1700000000.1234567891", time.Unix(1700000000, 123456789)},
+		{"-1.5", time.Unix(-1, -5e8)},
+		{"-0.25", time.Unix(0, -25e7)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			in := url.Values{"at": {tt.value}}
+			var got Target
+			if err := urlvalues.Unmarshal(in, &got, urlvalues.WithUnixTime()); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+			}
+			if !got.At.Equal(tt.want) {
+				t.Errorf("urlvalues.Unmarshal(%v, ...) At = %v, want %v", in, got.At, tt.want)
+			}
+
+			// Round trip, with digits beyond nanoseconds truncated.
+			encoded, err := urlvalues.Marshal(got, urlvalues.WithUnixTime())
+			if err != nil {
+				t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", got, err)
+			}
+			var decoded Target
+			if err := urlvalues.Unmarshal(encoded, &decoded, urlvalues.WithUnixTime()); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", encoded, &decoded, err)
+			}
+			if !decoded.At.Equal(tt.want) {
+				t.Errorf("urlvalues.Unmarshal(%v, ...) At = %v, want %v", encoded, decoded.At, tt.want)
+			}
+		})
+	}
+
+	for _, value := range []string{"1700000000.", ".5", "1700000000.1e3", "1700000000.-1"} {
+		in := url.Values{"at": {value}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got, urlvalues.WithUnixTime()); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+		}
+	}
+}
+
+func TestUnmarshal_Ago(t *testing.T) {
+	type Target struct {
+		Since time.Time  `urlvalue:"since,ago,layout:RFC3339"`
+		Until *time.Time `urlvalue:"until,ago"`
+	}
+
+	tests := []struct {
+		name  string
+		in    url.Values
+		since time.Time
+	}{
+		{"duration", url.Values{"since": {"24h"}}, time.Now().Add(-24 * time.Hour)},
+		{"compound duration", url.Values{"since": {"1h30m"}}, time.Now().Add(-90 * time.Minute)},
+		{"negative duration", url.Values{"since": {"-15m"}}, time.Now().Add(15 * time.Minute)},
+		{"now based", url.Values{"since": {"now-1d"}}, time.Now().AddDate(0, 0, -1)},
+		{"layout", url.Values{"since": {"2024-03-01T00:00:00Z"}}, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if !cmp.Equal(got.Since, tt.since, cmpopts.EquateApproxTime(time.Second)) {
+				t.Errorf("urlvalues.Unmarshal(...) Since -got +want\n%s", cmp.Diff(got.Since, tt.since))
+			}
+		})
+	}
+
+	t.Run("pointer", func(t *testing.T) {
+		in := url.Values{"until": {"5m"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if want := time.Now().Add(-5 * time.Minute); got.Until == nil || !cmp.Equal(*got.Until, want, cmpopts.EquateApproxTime(time.Second)) {
+			t.Errorf("urlvalues.Unmarshal(%v, ...) Until = %v, want %v", in, got.Until, want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		in := url.Values{"since": {"yesterday"}}
+		var got Target
+		err := urlvalues.Unmarshal(in, &got)
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", in, &got, err)
+		}
+		if want := "a duration such as 24h"; parseErr.Expected != want {
+			t.Errorf("ParseError.Expected = %q, want %q", parseErr.Expected, want)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		var target struct {
+			Since time.Duration `urlvalue:"since,ago"`
+		}
+		if err := urlvalues.Unmarshal(url.Values{}, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %T) = <nil>, want error", url.Values{}, &target)
+		}
+	})
+}
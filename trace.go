@@ -0,0 +1,119 @@
+package urlvalues
+
+import "fmt"
+
+// TraceKind is the kind of a [TraceEvent].
+type TraceKind int
+
+const (
+	// TraceMatched is the kind of events of fields decoded from a key.
+	TraceMatched TraceKind = iota
+	// TraceDefault is the kind of events of default values set into fields.
+	TraceDefault
+	// TraceAbsent is the kind of events of fields whose keys are absent.
+	TraceAbsent
+	// TraceSkipped is the kind of events of fields that are not decoded
+	// because their source is not available, such as fields with the "header"
+	// source decoded by [UnmarshalRequest].
+	TraceSkipped
+	// TraceUnused is the kind of events of keys that no field is decoded
+	// from.
+	TraceUnused
+)
+
+func (k TraceKind) String() string {
+	switch k {
+	case TraceMatched:
+		return "matched"
+	case TraceDefault:
+		return "default"
+	case TraceAbsent:
+		return "absent"
+	case TraceSkipped:
+		return "skipped"
+	case TraceUnused:
+		return "unused"
+	default:
+		return fmt.Sprintf("TraceKind(%d)", int(k))
+	}
+}
+
+// TraceEvent describes a decision made while decoding, for diagnosing why a
+// value didn't end up in the expected field. See [WithTraceFunc].
+type TraceEvent struct {
+	Kind TraceKind
+	// Name of struct field, or empty for events of kind TraceUnused.
+	FieldName string
+	// Key into URL values. For events of kind TraceDefault and TraceSkipped,
+	// the key of the field.
+	Key string
+	// Value decoded for events of kind TraceMatched, and default value for
+	// events of kind TraceDefault.
+	Value string
+}
+
+func (e TraceEvent) String() string {
+	switch e.Kind {
+	case TraceMatched:
+		return fmt.Sprintf("urlvalues: field %s matched key %s with value %q", e.FieldName, e.Key, e.Value)
+	case TraceDefault:
+		return fmt.Sprintf("urlvalues: field %s set to default value %q", e.FieldName, e.Value)
+	case TraceAbsent:
+		return fmt.Sprintf("urlvalues: field %s not decoded, key %s absent", e.FieldName, e.Key)
+	case TraceSkipped:
+		return fmt.Sprintf("urlvalues: field %s (key %s) skipped, source not available", e.FieldName, e.Key)
+	case TraceUnused:
+		return fmt.Sprintf("urlvalues: key %s unused", e.Key)
+	default:
+		return fmt.Sprintf("urlvalues: %s event for field %s (key %s)", e.Kind, e.FieldName, e.Key)
+	}
+}
+
+// TraceFunc handles trace events emitted while decoding.
+type TraceFunc func(TraceEvent)
+
+// trace passes e on to the trace func, if any.
+func (o *ParseOptions) trace(e TraceEvent) {
+	if o.traceFunc != nil {
+		o.traceFunc(e)
+	}
+}
+
+// traceKeys returns in with its lookups recording the keys that are present,
+// along with a function emitting a TraceUnused event for each key of the
+// values of in that was never looked up once decoding is done. in is returned
+// as is if no trace func is set.
+func traceKeys(in input, pOpts *ParseOptions) (input, func()) {
+	if pOpts.traceFunc == nil {
+		return in, func() {}
+	}
+
+	used := make(map[string]bool)
+	record := func(l lookup) lookup {
+		if l == nil {
+			return nil
+		}
+		return func(key string) []string {
+			values := l(key)
+			if len(values) > 0 {
+				used[in.canonical(key)] = true
+			}
+			return values
+		}
+	}
+
+	traced := in
+	traced.values = record(in.values)
+	traced.sources = make(map[string]lookup, len(in.sources))
+	for name, l := range in.sources {
+		traced.sources[name] = record(l)
+	}
+
+	return traced, func() {
+		for _, key := range in.keys("") {
+			if !used[key] {
+				pOpts.trace(TraceEvent{Kind: TraceUnused, Key: key})
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package urlvalues_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestWithTraceFunc(t *testing.T) {
+	type Target struct {
+		Name   string `urlvalue:"name"`
+		Limit  int    `urlvalue:"limit,default:10"`
+		Offset int    `urlvalue:"offset"`
+		Token  string `urlvalue:"X-Token,source:header"`
+	}
+
+	in := url.Values{"name": {"foo"}, "limt": {"5"}, "debug": {"1"}}
+	var events []urlvalues.TraceEvent
+	var target Target
+	err := urlvalues.Unmarshal(in, &target, urlvalues.WithTraceFunc(func(e urlvalues.TraceEvent) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+	}
+
+	want := []urlvalues.TraceEvent{
+		{Kind: urlvalues.TraceMatched, FieldName: "Name", Key: "name", Value: "foo"},
+		{Kind: urlvalues.TraceDefault, FieldName: "Limit", Key: "limit", Value: "10"},
+		{Kind: urlvalues.TraceAbsent, FieldName: "Limit", Key: "limit"},
+		{Kind: urlvalues.TraceAbsent, FieldName: "Offset", Key: "offset"},
+		{Kind: urlvalues.TraceSkipped, FieldName: "Token", Key: "X-Token"},
+		{Kind: urlvalues.TraceUnused, Key: "debug"},
+		{Kind: urlvalues.TraceUnused, Key: "limt"},
+	}
+	if diff := cmp.Diff(events, want); diff != "" {
+		t.Errorf("trace events -got +want\n%s", diff)
+	}
+}
+
+func TestWithTraceFunc_request(t *testing.T) {
+	type Target struct {
+		Name  string `urlvalue:"name"`
+		Token string `urlvalue:"X-Token,source:header"`
+	}
+
+	r := httptest.NewRequest("GET", "/?name=foo&page=2", nil)
+	r.Header.Set("X-Token", "secret")
+	var events []urlvalues.TraceEvent
+	var target Target
+	err := urlvalues.Bind(r, &target, urlvalues.WithTraceFunc(func(e urlvalues.TraceEvent) {
+		events = append(events, e)
+	}))
+	if err != nil {
+		t.Fatalf("urlvalues.Bind(%v, %v) = %q, want <nil>", r, &target, err)
+	}
+
+	want := []urlvalues.TraceEvent{
+		{Kind: urlvalues.TraceMatched, FieldName: "Name", Key: "name", Value: "foo"},
+		{Kind: urlvalues.TraceMatched, FieldName: "Token", Key: "X-Token", Value: "secret"},
+		{Kind: urlvalues.TraceUnused, Key: "page"},
+	}
+	if diff := cmp.Diff(events, want); diff != "" {
+		t.Errorf("trace events -got +want\n%s", diff)
+	}
+}
+
+func TestTraceEvent_String(t *testing.T) {
+	tests := []struct {
+		e    urlvalues.TraceEvent
+		want string
+	}{
+		{urlvalues.TraceEvent{Kind: urlvalues.TraceMatched, FieldName: "Name", Key: "name", Value: "foo"}, `urlvalues: field Name matched key name with value "foo"`},
+		{urlvalues.TraceEvent{Kind: urlvalues.TraceDefault, FieldName: "Limit", Key: "limit", Value: "10"}, `urlvalues: field Limit set to default value "10"`},
+		{urlvalues.TraceEvent{Kind: urlvalues.TraceUnused, Key: "debug"}, "urlvalues: key debug unused"},
+	}
+	for _, tt := range tests {
+		if got := tt.e.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.e, got, tt.want)
+		}
+	}
+}
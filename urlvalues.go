@@ -1,15 +1,20 @@
 package urlvalues
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strings"
 )
 
 // ErrInvalidStruct indicates that the Unmarshal target is not of correct type.
 var ErrInvalidStruct = errors.New("urlvalues: target must be a struct pointer")
 
+// ErrInvalidForm indicates that the form of a request failed to be parsed.
+var ErrInvalidForm = errors.New("urlvalues: invalid request form")
+
 // ParseError occurs when a [url.Values] item failed to be parsed into a struct
 // field's type.
 type ParseError struct {
@@ -17,12 +22,44 @@ type ParseError struct {
 	FieldName string
 	// Key into URL values.
 	Key string
+	// What a valid value looks like, such as "format 2006-01-02", derived from
+	// the type and tag options of the field. Empty if unknown.
+	Expected string
 
 	fe *FieldError
+	// Message rendered from a template of Messages, if any.
+	msg string
 }
 
 func (e *ParseError) Error() string {
-	return fmt.Sprintf("error parsing value of %s: %s", e.Key, e.fe.err.Error())
+	if e.msg != "" {
+		return e.msg
+	}
+	return fmt.Sprintf("error parsing value of %s: %s%s", e.Key, e.fe.err.Error(), e.hint())
+}
+
+// Code returns the [ErrorCode] classifying why the value failed to be parsed.
+func (e *ParseError) Code() ErrorCode {
+	return errorCode(e.fe.err)
+}
+
+// reason returns a message describing why the value failed to be parsed,
+// without mentioning its key.
+func (e *ParseError) reason() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return e.fe.err.Error() + e.hint()
+}
+
+// hint returns Expected in parentheses, unless empty or already stated by the
+// underlying error, as constraint violations do.
+func (e *ParseError) hint() string {
+	var constrErr *ConstraintError
+	if e.Expected == "" || errors.As(e.fe.err, &constrErr) {
+		return ""
+	}
+	return " (expected " + e.Expected + ")"
 }
 
 // Unwrap returns the underlying [FieldError].
@@ -30,35 +67,271 @@ func (e *ParseError) Unwrap() error {
 	return e.fe
 }
 
+// MarshalJSON encodes e as a JSON object with the name of the field, the key
+// and value that failed to be parsed, the type of the field and a message
+// describing why, so that handlers can respond with e as is.
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errorJSON{
+		Field:        e.FieldName,
+		Key:          e.Key,
+		Value:        e.fe.value,
+		ExpectedType: e.fe.typeName,
+		Message:      e.reason(),
+	})
+}
+
+// errorJSON is the JSON encoding of ParseError and FieldError values.
+type errorJSON struct {
+	Field        string `json:"field"`
+	Key          string `json:"key,omitempty"`
+	Value        string `json:"value"`
+	ExpectedType string `json:"expected_type"`
+	Message      string `json:"message"`
+}
+
+// TooManyErrorsError follows the errors returned when more fields fail to be
+// decoded than allowed by the [WithMaxErrors] [SetParseOptionFunc].
+type TooManyErrorsError struct {
+	// Number of errors omitted.
+	Omitted int
+}
+
+func (e *TooManyErrorsError) Error() string {
+	return fmt.Sprintf("and %d more errors", e.Omitted)
+}
+
 // Unmarshal unmarshals data into the value pointed to by v. If v is nil or
 // not a struct pointer, Unmarshal returns an [ErrInvalidStruct] error.
 //
+// As a special case, v may be a pointer to a map[string]any, in which case
+// the type of each value is inferred rather than declared by a struct field.
+// Values are decoded as bool if they are "true" or "false", as int64 or
+// float64 if they are base 10 numbers, as [time.Time] if they are in the
+// [time.RFC3339] format, and as string otherwise. Keys with more than one
+// value, or whose value contains the delimiter, are decoded as []any. A nil
+// map is allocated.
+//
+// As another special case, v may be a pointer to a slice of structs or struct
+// pointers, in which case each item is decoded from keys with an index, such
+// as "[0].name" and "[1].name". Combined with the [WithPrefix]
+// [SetParseOptionFunc], the keys may be named, such as "items[0].name" given
+// the prefix "items". Items are ordered by index and gaps between indexes are
+// removed, so that the indexes 0, 2 and 7 result in three items. All items are
+// decoded even if some fail, in which case any errors are returned together
+// as [Errors] and the slice is left untouched. The number of items is limited
+// by the [WithMaxSliceLen] [SetParseOptionFunc].
+//
 // Slices are decoded by splitting values by a delimiter and parsing each
 // item individually. The delimiter defaults to semicolon (;), but can by
-// customized by passing the [WithDelimiter] [SetParseOptionFunc]. Key-value pairs
-// of maps are split using the same delimiter. Keys and their values are
+// customized by passing the [WithDelimiter] [SetParseOptionFunc], or split by
+// several delimiters by passing [WithDelimiters]. Key-value pairs of maps are
+// split using the same delimiters. Keys and their values are
 // separated by a colon (:), with the key to the left and the value to the
 // right of the colon.
 //
+// Keys with multiple values are decoded into slices and maps with each value
+// as an element of its own, without splitting values by the delimiter unless
+// the [WithSplitRepeated] [SetParseOptionFunc] is passed. For
+// fields that hold a single value, such as int fields, the values are joined
+// by the delimiter, which can be changed by passing the [WithMultiplePolicy]
+// [SetParseOptionFunc].
+//
 // Fields with types implementing [encoding.TextUnmarshaler] and/or
 // [encoding.BinaryUnmarshaler] will be decoded using those interfaces,
 // respectively. If a type implements both interfaces, the
-// [encoding.TextUnmarshaler] interface is used to decode the value.
+// [encoding.TextUnmarshaler] interface is used to decode the value. Fields of
+// enum types registered by [RegisterEnum] are decoded from the names of the
+// enum values instead. Use [UnmarshalContext] to decode fields implementing
+// [TextUnmarshalerContext] with a context of your own.
+//
+// Options can be declared once on the struct type by implementing
+// [OptionsProvider], instead of being passed at every call site.
+//
+// Fields of nested structs are decoded as if they were fields of the outer
+// struct, unless keys are composed with the keys of the nested structs by
+// passing the [WithKeyStyle] [SetParseOptionFunc]. The "prefix" option makes
+// the key of a nested struct, including an embedded one, prefix the keys of
+// its fields regardless of the key style, so that fields with the same names
+// in different nested structs don't collide. The "squash" option makes the
+// fields of a nested struct keyed as if they were fields of the outer struct
+// regardless of the key style.
+//
+// Interface fields are decoded into implementations registered by [Register],
+// named by the discriminator key of each field, such as "notify.type".
+//
+// Nil pointers to nested structs are only allocated if any of the fields of
+// the nested struct are decoded or have a default value. The "optional" option
+// leaves a nil struct pointer nil unless any of the keys of the fields of the
+// struct are present, even if the fields have default values, so that
+// handlers can tell whether the struct was provided at all. Constraints on
+// fields of optional structs are only checked if the structs are allocated.
 //
 // The decoding of each struct field can be customized by the name string
-// stored under the "urlvalue" key in the struct field's tag. The name string
+// stored under the "urlvalue" key in the struct field's tag, or the key set by
+// passing the [WithTagName] [SetParseOptionFunc]. The name string
 // acts as a key into data, possibly followed by a comma-separated list of
 // options. The name may be empty, in which case the field name of the struct
-// will act as as key into data in its stead.
+// will act as as key into data in its stead, converted to the naming set by
+// passing the [WithKeyNaming] [SetParseOptionFunc], if any.
+//
+// The "alias" option allows for decoding a field from several keys. Its value
+// is a list of alternative keys separated by a vertical bar (|). If more than
+// one of the keys is present in data, the name takes precedence over the
+// aliases, which in turn take precedence in the order they are listed. A
+// [Warning] is emitted for each key whose value is ignored.
+//
+// The "deprecated" option works like the "alias" option, but lists keys that
+// are being phased out. Deprecated keys take precedence after the aliases, and
+// decoding a field from one emits a [Warning] telling the client which key to
+// use instead.
 //
 // The "default" option allows for setting a default value on a field in case
 // corresponding URL value is not present in data, or if the value is the zero
 // value for the field's type.
 //
+// The "default_if" option sets a default value that depends on the value of
+// another field, referred to by its key, in the form key=value:default. For
+// example, `urlvalue:"sort,default_if:view=compact:name"` defaults the field
+// to "name" if the field keyed "view" holds "compact", either decoded from
+// data or by default. The option may be repeated, in which case the first
+// condition that holds applies. It takes precedence over the "default"
+// option.
+//
+// A default value starting with @ refers to another field by its key, and
+// defaults the field to the value of that field once it is decoded. Fields of
+// type time.Time may add offsets of years, months and days to the referenced
+// time like "now" based values do:
+//
+//	// Until defaults to 30 days after Since.
+//	Since time.Time `urlvalue:"since"`
+//	Until time.Time `urlvalue:"until,default:@since+30d"`
+//
+// The field is left untouched if the referenced field holds its zero value.
+//
+// The "example" option holds a sample value of a field. It doesn't affect
+// decoding, but documents the field. See [Describe] and [ExampleValues].
+//
+// The "dedupe" option removes repeated values from a slice field, keeping the
+// first occurrence of each value. Values are compared before being parsed. All
+// slice fields can be deduplicated by passing the [WithDedupeSlices]
+// [SetParseOptionFunc].
+//
+// The "trim" option removes leading and trailing whitespace from values before
+// they are parsed, and the "lower" and "upper" options convert values to lower
+// and upper case respectively. The options apply to each value of slice and
+// map fields, as well as to default values. All values can be transformed by
+// passing the [WithValueTransformer] [SetParseOptionFunc] instead.
+//
+// The "sort" option sorts the values of a slice field in increasing order after
+// they have been parsed. It is supported by slices of strings, numbers and
+// [time.Time]. Combined with the "lower" option, it makes the decoded slice
+// insensitive to the order and case of the values.
+//
+// The "maxslicelen" option limits the number of values that a slice or map
+// field may receive, overriding any limit set by the [WithMaxSliceLen]
+// [SetParseOptionFunc].
+//
+// The "minitems" and "maxitems" options constrain the number of items of a
+// slice or map field after it has been decoded, including any default value.
+// Violating a constraint results in a [ParseError] wrapping a
+// [ConstraintError], even if the field's key is not present in data.
+//
+// The "min" and "max" options constrain the value of a number field. The
+// bounds of a [time.Duration] field may be durations, such as in
+// `urlvalue:"timeout,default:5s,min:100ms,max:1m"`, while plain numbers are
+// nanoseconds. The "oneof" option constrains the value of a field to a list of
+// values separated by a vertical bar (|), or the fields of a [Sort] field to
+// the fields that may be sorted by. The "pattern" option requires the value of
+// a string field to match a regular expression. On slice fields, these
+// constraints apply to each element, and all elements violating them are
+// reported together as [Errors] of [ElementError] values. These constraints
+// are only checked if the field's key is present in data or the field has a
+// default value. Violating a constraint results in a [ParseError] wrapping a
+// [ConstraintError].
+//
+// The "validate" option names validators registered by
+// [Decoder.RegisterValidation] that check values of the field beyond the
+// declarative constraints.
+//
+// The "unescape" option unescapes values using [url.QueryUnescape] before they
+// are parsed, which decodes values that were encoded twice, such as by
+// upstream proxies. Values failing to be unescaped result in a [ParseError].
+// All values are unescaped if the [WithUnescape] [SetParseOptionFunc] is
+// passed.
+//
+// The "clamp" option clamps the value of a number field into a range, such
+// as in `urlvalue:"limit,default:20,clamp:1..100"`, instead of failing. Either
+// bound of the range may be omitted, such as in "clamp:0..". The bounds of
+// integer fields must be whole numbers. A [Warning] is emitted for each value
+// clamped. On slice fields, each element is clamped.
+//
+// The "before" and "after" options constrain the value of a [time.Time] field
+// to be strictly before and after a bound, such as in
+// `urlvalue:"since,after:2020-01-01,before:now"`. Bounds are in the layout of
+// the field, in any of the layouts of the "Auto" layout (see
+// [WithAutoTimeLayout]), or "now" based, in which case they are relative to
+// the time of decoding. Violating a bound results in a [ParseError] wrapping
+// a [ConstraintError] stating the bound.
+//
+// The "finite" option rejects the values "NaN", "Inf" and their variants of
+// a float field, which are otherwise accepted like by [strconv.ParseFloat].
+// Rejected values result in a [ParseError] wrapping a [ConstraintError]. All
+// float fields reject them if the [WithFiniteFloats] [SetParseOptionFunc] is
+// passed.
+//
+// The "truevals" and "falsevals" options declare values, separated by a
+// vertical bar (|), that a bool field is decoded as true and false from
+// respectively, such as in `urlvalue:"active,truevals:enabled|yes"`. Values
+// are matched case-insensitively, and values matching neither are parsed by
+// [strconv.ParseBool]. The first values of the options are used when
+// encoding.
+//
 // The "layout" option only applies to fields of type [time.Time] and allows for
 // customizing how values should be parsed by providing layouts understood
 // by [time.Parse]. See https://pkg.go.dev/time#pkg-constants for a complete list
-// of the predefined layouts.
+// of the predefined layouts. The "Auto" layout detects the layout of values,
+// see [WithAutoTimeLayout]. The "Quarter" layout decodes calendar quarters,
+// such as "2024-Q2", as their first instant in UTC, see [Quarter]. Fields
+// without the option use the [time.Layout] layout, falling back to
+// [time.RFC3339] as parsed by [time.Time.UnmarshalText], or the "Auto" layout
+// if the [WithAutoTimeLayout] [SetParseOptionFunc] is passed. Such fields are
+// encoded in the [time.Layout] layout.
+//
+// The "ago" option decodes a [time.Time] field from a duration before the time
+// of decoding, as understood by [time.ParseDuration], such that "24h" decodes
+// as 24 hours ago. Values that aren't durations are parsed like those of any
+// other time.Time field, so that encoded times decode as well:
+//
+//	// Since is decoded from "24h", "now-1d" or a time in the RFC3339 layout.
+//	Since time.Time `urlvalue:"since,ago,layout:RFC3339"`
+//
+// The "geopoint" option decodes a struct with float fields named Lat and Lng
+// like a [LatLng], such as from "59.33,18.07".
+//
+// The "passthrough" option collects all keys starting with the key of a
+// [url.Values] field, and their values, into the field as is, without parsing
+// them. This suits gateways forwarding extension parameters downstream:
+//
+//	// Ext holds all keys starting with "ext.", such as "ext.tracking".
+//	Ext url.Values `urlvalue:"ext.,passthrough"`
+//
+// The "source" option only applies to decoding of HTTP requests and selects
+// which part of the request a field is decoded from. See [UnmarshalRequest]
+// and [Bind]. Fields with a source option are otherwise left untouched, except
+// for their default values.
+//
+// The "encodeonly" option leaves a field untouched by decoding, including its
+// default value, so that the field is only encoded by [Marshal]. This suits
+// fields derived by the handler, such as a total count echoed in links. The
+// "decodeonly" option conversely leaves a field out of the encoding, which
+// suits sensitive fields, such as a token, that must not end up in links.
+// The two options are mutually exclusive.
+//
+// Option values containing commas can be enclosed in single quotes, such as
+// "default:'a,b'". Alternatively, any character can be escaped by a
+// backslash, which must itself be escaped within the struct tag literal, such
+// as "default:a\\,b". Unterminated quotes and trailing backslashes are
+// errors.
 //
 // As a special case, if the field tag is "-", the field is always omitted.
 // Note that a field with name "-" can still be generated using the tag "-,".
@@ -68,6 +341,39 @@ func (e *ParseError) Unwrap() error {
 //	// Field defaults to 42.
 //	Field int `urlvalue:"myName,default:42"`
 //
+//	// Field is decoded from myName, or oldName or otherName if missing.
+//	Field int `urlvalue:"myName,alias:oldName|otherName"`
+//
+//	// Field is decoded from myName, or oldName with a warning if missing.
+//	Field int `urlvalue:"myName,deprecated:oldName"`
+//
+//	// Field is documented with the sample value 3.
+//	Field int `urlvalue:"myName,example:3"`
+//
+//	// Field holds no repeated values.
+//	Field []string `urlvalue:"myName,dedupe"`
+//
+//	// Field holds lower case values in increasing order.
+//	Field []string `urlvalue:"myName,sort,lower"`
+//
+//	// Field holds between 1 and 50 items.
+//	Field []int `urlvalue:"myName,minitems:1,maxitems:50"`
+//
+//	// Field holds values between 1 and 100, defaulting to 20.
+//	Field int `urlvalue:"myName,default:20,min:1,max:100"`
+//
+//	// Field holds lower case slugs of the given kinds.
+//	Field []string `urlvalue:"myName,oneof:post|page,pattern:^[a-z-]+$"`
+//
+//	// Field defaults to the URL "https://example.com/?a=1,2".
+//	Field string `urlvalue:"myName,default:'https://example.com/?a=1,2'"`
+//
+//	// Fields of Field are decoded from keys such as "myName.page".
+//	Field Pagination `urlvalue:"myName,prefix"`
+//
+//	// Field is left nil unless any of the keys of its fields are present.
+//	Field *Filter `urlvalue:"myName,optional"`
+//
 //	// Field is parsed using the RFC850 layout.
 //	Field time.Time `urlvalue:"myName,layout:RFC850"`
 //
@@ -80,23 +386,76 @@ func (e *ParseError) Unwrap() error {
 //	// Field is decoded as time.Now().AddDate(3, -4, 9).
 //	Field time.Time `urlvalue:"myName,default:now+3y-4m+9d"`
 //
+// Fields of type rune, which is int32, are decoded from a single character,
+// such as "x" or "é", into its code point, as well as from numbers. Single
+// digits are decoded as numbers.
+//
 // The parsing of [time.Time] is extended to support a "now" based parsing.
 // It parses the value "now" to [time.Now]. Furthermore, it extends this
 // syntax by allowing the consumer to subtract or add days (d), months (m)
-// and years (y) to "now". This is done by prepending the date identifiers
-// (d,m,y) with a minus (-) or plus (+) sign.
+// and years (y) to "now". This is done by prepending a number followed by
+// the date identifiers (d,m,y) with a minus (-) or plus (+) sign, such as in
+// "now-1y+2d". Spaces are allowed between the parts, the sign of the first
+// offset may be omitted, in which case it is added, and "now" and the date
+// identifiers are case-insensitive, such that "NOW - 1Y + 2D" and "now 7d",
+// as a plus sign in an unescaped query decodes to, are valid as well.
 //
 // Any error that occurs while processing struct fields results in a [FieldError].
 // [ParseError] wraps around FieldError and is returned if any error occurs while
 // parsing the [url.Values] that was passed into Unmarshal. ParseError is
-// never returned from errors occuring while parsing default values.
-func Unmarshal(data url.Values, v any, setParseOpts ...SetParseOptionFunc) error {
-	pOpts := &ParseOptions{}
-	for _, f := range setParseOpts {
-		f(pOpts)
+// never returned from errors occuring while parsing default values. The
+// messages of ParseError values can be customized, for example translated
+// into the language of the client, by passing the [WithMessages]
+// [SetParseOptionFunc]. Decoding stops at the first ParseError, unless the
+// [WithAllErrors] SetParseOptionFunc is passed, in which case all of them are
+// returned together as [Errors], limited by [WithMaxErrors].
+//
+// Fields are decoded in the order they are declared, descending into nested
+// structs where they are declared, except that constraints on fields of
+// optional structs are checked after all other fields. Keys that aren't
+// declared by fields, such as those of dynamic maps, are processed in lexical
+// order, and batch items in order of their indexes. Errors, warnings and trace
+// events are thereby reported in the same order for the same input.
+func Unmarshal(data url.Values, v any, setParseOpts ...SetParseOptionFunc) (err error) {
+	pOpts := newParseOptionsFor(v, setParseOpts)
+	defer pOpts.observe(v)(&err)
+
+	return unmarshalValues(data, v, pOpts)
+}
+
+// unmarshalValues unmarshals data into the value pointed to by v. See
+// Unmarshal for details.
+func unmarshalValues(data url.Values, v any, pOpts *ParseOptions) error {
+	return unmarshalInput(input{values: valuesLookup(data), valueKeys: valuesKeys(data)}, v, pOpts)
+}
+
+// unmarshalInput unmarshals in, holding the values of a single source, into
+// the value pointed to by v, which may also be a dynamic map or batch target.
+func unmarshalInput(in input, v any, pOpts *ParseOptions) error {
+	in, traceUnused := traceKeys(in, pOpts)
+	defer traceUnused()
+
+	return unmarshalTarget(in, v, pOpts)
+}
+
+// unmarshalTarget unmarshals in into the value pointed to by v, which may also
+// be a dynamic map or batch target, without tracing unused keys.
+func unmarshalTarget(in input, v any, pOpts *ParseOptions) error {
+	if m, ok := v.(*map[string]any); ok && m != nil {
+		return unmarshalDynamic(in, in.keys(""), m, pOpts)
+	}
+
+	if rv := reflect.ValueOf(v); isBatchTarget(rv) {
+		return unmarshalBatch(in, in.keys(""), rv, pOpts)
 	}
 
-	fields, err := extractFields(v)
+	return unmarshal(in, v, pOpts)
+}
+
+func unmarshal(in input, v any, pOpts *ParseOptions) error {
+	buf, fields := getFields()
+	fields, err := extractFields(v, fields, pOpts)
+	defer func() { putFields(buf, fields) }()
 	if err != nil {
 		return err
 	}
@@ -104,12 +463,67 @@ func Unmarshal(data url.Values, v any, setParseOpts ...SetParseOptionFunc) error
 		return errors.New("urlvalues: no fields identified in target struct")
 	}
 
-	for _, field := range fields {
-		field := field
+	return decodeFields(in, fields, pOpts)
+}
+
+// decodeFields decodes and validates fields from in.
+func decodeFields(in input, fields []field, pOpts *ParseOptions) error {
+	if pOpts.decoded != nil {
+		*pOpts.decoded += len(fields)
+	}
+
+	// Fields whose default values refer to other fields are decoded after
+	// the fields they refer to.
+	ordered, err := orderReferences(fields)
+	if err != nil {
+		return err
+	}
+
+	// Fields of optional structs are validated once all fields are decoded,
+	// and only if their structs are assigned by then.
+	var optional []decodedField
+	var errs Errors
+	for _, field := range ordered {
+		if err := pOpts.context().Err(); err != nil {
+			return err
+		}
+		if field.options.encodeOnly {
+			pOpts.trace(TraceEvent{Kind: TraceSkipped, FieldName: field.name, Key: field.fullKey(field.key(), pOpts)})
+			continue
+		}
+		if isPolymorphic(field.field) {
+			if err := decodeImplementation(in, field, pOpts); err != nil {
+				if errs, err = pOpts.collect(errs, err); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if field.field.Type() == fieldsetsType {
+			if err := decodeFieldsets(in, field, pOpts); err != nil {
+				if errs, err = pOpts.collect(errs, err); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if field.options.passthrough {
+			decodePassthrough(in, field, pOpts)
+			continue
+		}
 
-		// Set any default value into the struct for this field.
-		if field.options.defaultValue != "" {
-			if err := processField(true, field.options.defaultValue, field.field, field.options, *pOpts); err != nil {
+		if len(field.options.defaultIfs) > 0 {
+			def, err := conditionalDefault(in, field, fields, pOpts)
+			if err != nil {
+				return err
+			}
+			if def != "" {
+				field.options.defaultValue = def
+			}
+		}
+		if isReference(field.options.defaultValue) {
+			set, err := setReference(field, fields)
+			if err != nil {
 				return &FieldError{
 					fieldName: field.name,
 					typeName:  field.field.Type().String(),
@@ -117,38 +531,285 @@ func Unmarshal(data url.Values, v any, setParseOpts ...SetParseOptionFunc) error
 					err:       err,
 				}
 			}
+			if set {
+				field.allocate(false)
+				pOpts.trace(TraceEvent{Kind: TraceDefault, FieldName: field.name, Key: field.fullKey(field.key(), pOpts), Value: field.options.defaultValue})
+			}
 		}
 
-		// Extract access key into data for this field. Default to field name if
-		// custom key not set in tags.
-		key := field.options.key
-		if key == "" {
-			key = field.name
+		key, value, state, err := decodeField(in, field, pOpts)
+		if err != nil {
+			if errs, err = pOpts.collect(errs, err); err != nil {
+				return err
+			}
+			continue
 		}
-
-		values, ok := data[key]
-		if !ok || len(values) == 0 {
+		if state == fieldSkipped {
+			continue
+		}
+		if field.optional() {
+			optional = append(optional, decodedField{field, key, value, state})
 			continue
 		}
 
-		value := values[0]
-		if len(values) > 1 {
-			value = strings.Join(values, pOpts.Delim())
+		if err := validateField(field, state == fieldPresent || field.options.defaultValue != ""); err != nil {
+			if errs, err = pOpts.collect(errs, newParseError(field, key, value, err, pOpts)); err != nil {
+				return err
+			}
 		}
+	}
 
-		if err := processField(false, value, field.field, field.options, *pOpts); err != nil {
-			return &ParseError{
-				FieldName: field.name,
-				Key:       key,
-				fe: &FieldError{
-					fieldName: field.name,
-					typeName:  field.field.Type().String(),
-					value:     value,
-					err:       err,
-				},
+	for _, d := range optional {
+		if d.field.optional() {
+			continue
+		}
+		if err := validateField(d.field, d.state == fieldPresent || d.field.options.defaultValue != ""); err != nil {
+			if errs, err = pOpts.collect(errs, newParseError(d.field, d.key, d.value, err, pOpts)); err != nil {
+				return err
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
+
+// errorCount counts the ParseErrors of a call aggregating errors.
+type errorCount struct {
+	n int
+	// Follows the errors kept, once more than the maximum number of errors
+	// occur.
+	tooMany *TooManyErrorsError
+}
+
+// collect appends err to errs if errors are aggregated and err is a
+// ParseError, or Errors collected by decoding nested fields, in which case
+// the returned error is nil. Otherwise, err is returned as is and decoding
+// stops. ParseErrors beyond the maximum number of errors are counted rather
+// than appended.
+func (o *ParseOptions) collect(errs Errors, err error) (Errors, error) {
+	if o.errorCount == nil {
+		return errs, err
+	}
+	switch err := err.(type) {
+	case *ParseError:
+		c := o.errorCount
+		c.n++
+		if o.maxErrors > 0 && c.n > o.maxErrors {
+			if c.tooMany == nil {
+				c.tooMany = &TooManyErrorsError{}
+				errs = append(errs, c.tooMany)
+			}
+			c.tooMany.Omitted++
+			return errs, nil
+		}
+		return append(errs, err), nil
+	case Errors:
+		return append(errs, err...), nil
+	}
+	return errs, err
+}
+
+// decodedField is a field decoded from key and value.
+type decodedField struct {
+	field      field
+	key, value string
+	state      fieldState
+}
+
+// fieldState describes the outcome of decoding a field.
+type fieldState int
+
+const (
+	// The field is not subject to decoding nor validation, either because it
+	// is a file upload or because its source is not available.
+	fieldSkipped fieldState = iota
+	// The key of the field is not present.
+	fieldAbsent
+	// The field was decoded from a present key.
+	fieldPresent
+)
+
+// decodeField decodes field from in. It returns the key and value that field
+// was decoded from, or the key of field and an empty value if its key is not
+// present in in.
+func decodeField(in input, field field, pOpts *ParseOptions) (string, string, fieldState, error) {
+	// File uploads are never parsed, only assigned as is.
+	if isFileField(field.field) {
+		for _, key := range field.keys() {
+			if files := in.files[field.fullKey(key, pOpts)]; len(files) > 0 {
+				setFiles(field.field, files)
+				field.allocate(true)
+				break
+			}
+		}
+		return "", "", fieldSkipped, nil
+	}
+
+	// Set any default value into the struct for this field. Default values
+	// referring to other fields are set by decodeFields.
+	if field.options.defaultValue != "" && !isReference(field.options.defaultValue) {
+		if err := processField(true, field.options.defaultValue, field.field, field.options, *pOpts); err != nil {
+			return "", "", fieldSkipped, &FieldError{
+				fieldName: field.name,
+				typeName:  field.field.Type().String(),
+				value:     field.options.defaultValue,
+				err:       err,
+			}
+		}
+		field.allocate(false)
+		pOpts.trace(TraceEvent{Kind: TraceDefault, FieldName: field.name, Key: field.fullKey(field.key(), pOpts), Value: field.options.defaultValue})
+	}
+
+	lookup := in.lookup(field.options.source)
+	if lookup == nil {
+		pOpts.trace(TraceEvent{Kind: TraceSkipped, FieldName: field.name, Key: field.fullKey(field.key(), pOpts)})
+		return "", "", fieldSkipped, nil
+	}
+
+	key, values := lookupField(field, lookup, pOpts)
+	if len(values) == 0 {
+		key = field.fullKey(field.key(), pOpts)
+		pOpts.trace(TraceEvent{Kind: TraceAbsent, FieldName: field.name, Key: key})
+		return key, "", fieldAbsent, nil
+	}
+
+	// Check the values as received, before spending any work on them.
+	if err := checkSize(values, pOpts); err != nil {
+		return "", "", fieldSkipped, newParseError(field, key, strings.Join(values, pOpts.Delim()), err, pOpts)
+	}
+	if err := checkChars(values, pOpts); err != nil {
+		return "", "", fieldSkipped, newParseError(field, key, strings.Join(values, pOpts.Delim()), err, pOpts)
+	}
+
+	if field.options.unescape || pOpts.unescape {
+		unescaped := make([]string, len(values))
+		for i, v := range values {
+			var err error
+			if unescaped[i], err = url.QueryUnescape(v); err != nil {
+				return "", "", fieldSkipped, newParseError(field, key, v, fmt.Errorf("unescaping value: %w", err), pOpts)
+			}
+		}
+		values = unescaped
+	}
+
+	if pOpts.valueTransformer != nil {
+		transformed := make([]string, len(values))
+		for i, v := range values {
+			transformed[i] = pOpts.valueTransformer(key, v)
+		}
+		values = transformed
+	}
+
+	picked, err := applyMultiplePolicy(field, key, values, pOpts)
+	if err != nil {
+		return "", "", fieldSkipped, newParseError(field, key, strings.Join(values, pOpts.Delim()), err, pOpts)
+	}
+	values = picked
+
+	value := values[0]
+	if len(values) > 1 {
+		value = strings.Join(values, pOpts.Delim())
+	}
+
+	// Unescaping and transforming may introduce control characters, such as
+	// from "%0D".
+	if field.options.unescape || pOpts.unescape || pOpts.valueTransformer != nil {
+		if err := checkChars(values, pOpts); err != nil {
+			return "", "", fieldSkipped, newParseError(field, key, value, err, pOpts)
+		}
+	}
+	validators, err := validatorsOf(field, pOpts)
+	if err != nil {
+		return "", "", fieldSkipped, err
+	}
+	if err := runValidators(validators, field, values, pOpts); err != nil {
+		return "", "", fieldSkipped, newParseError(field, key, value, err, pOpts)
+	}
+
+	// Values of a key present multiple times are decoded as separate elements
+	// of fields holding multiple values, so that values containing the
+	// delimiter are kept intact.
+	if len(values) > 1 && holdsMultiple(field.field) {
+		err = processValues(values, field.field, field.options, *pOpts)
+	} else {
+		err = processField(false, value, field.field, field.options, *pOpts)
+	}
+	if err != nil {
+		return "", "", fieldSkipped, newParseError(field, key, value, err, pOpts)
+	}
+	clampField(field, key, pOpts)
+	field.allocate(true)
+	pOpts.trace(TraceEvent{Kind: TraceMatched, FieldName: field.name, Key: key, Value: value})
+
+	return key, value, fieldPresent, nil
+}
+
+// newParseError returns a ParseError of field, decoded from key, failing to
+// parse value due to err.
+func newParseError(field field, key, value string, err error, pOpts *ParseOptions) *ParseError {
+	return renderParseError(&ParseError{
+		FieldName: field.name,
+		Key:       key,
+		Expected:  expected(field, pOpts),
+		fe: &FieldError{
+			fieldName: field.name,
+			typeName:  field.field.Type().String(),
+			value:     value,
+			err:       err,
+		},
+	}, pOpts)
+}
+
+// renderParseError renders the message of parseErr using the error formatter
+// or messages of pOpts, if any, and returns parseErr.
+func renderParseError(parseErr *ParseError, pOpts *ParseOptions) *ParseError {
+	if pOpts.errorFormatter != nil {
+		parseErr.msg = pOpts.errorFormatter(parseErr.info())
+	} else if msg, ok := pOpts.messages.message(parseErr); ok {
+		parseErr.msg = msg
+	}
+	return parseErr
+}
+
+// lookupField returns the values of the first key of field that is present in
+// lookup, along with the key itself. Keys are tried in the order returned by
+// field.keys, composed by field.fullKey. A warning is emitted for
+// each other key of field that is present as well, since their values are
+// ignored.
+func lookupField(field field, lookup lookup, pOpts *ParseOptions) (string, []string) {
+	var (
+		key        string
+		values     []string
+		deprecated bool
+	)
+	for i, k := range field.keys() {
+		k = field.fullKey(k, pOpts)
+		vals := lookup(k)
+		if len(vals) == 0 {
+			continue
+		}
+		if values != nil {
+			pOpts.warn(Warning{
+				FieldName: field.name,
+				Key:       k,
+				Message:   fmt.Sprintf("value ignored in favour of value of %s", key),
+			})
+			continue
+		}
+		key, values = k, vals
+		deprecated = i > len(field.options.aliases)
+	}
+
+	if deprecated {
+		pOpts.warn(Warning{
+			FieldName: field.name,
+			Key:       key,
+			Message:   fmt.Sprintf("key %s is deprecated, use %s instead", key, field.fullKey(field.key(), pOpts)),
+		})
+	}
+
+	return key, values
+}
@@ -1,10 +1,13 @@
 package urlvalues_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -246,6 +249,82 @@ func TestUnmarshal_WithParseOptions(t *testing.T) {
 	}
 }
 
+func TestUnmarshal_WithDelimiters(t *testing.T) {
+	type Target struct {
+		Tags   []string       `urlvalue:"tags"`
+		Counts map[string]int `urlvalue:"counts"`
+	}
+
+	in := url.Values{"tags": {"a,b;c"}, "counts": {"x:1,y:2"}}
+	want := Target{Tags: []string{"a", "b", "c"}, Counts: map[string]int{"x": 1, "y": 2}}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithDelimiters(";", ",")); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("max slice len", func(t *testing.T) {
+		var got Target
+		err := urlvalues.Unmarshal(in, &got, urlvalues.WithDelimiters(";", ","), urlvalues.WithMaxSliceLen(2))
+		var tooMany *urlvalues.TooManyValuesError
+		if !errors.As(err, &tooMany) || tooMany.Count != 3 {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want TooManyValuesError of 3 values", in, &got, err)
+		}
+	})
+
+	t.Run("WithDelimiter resets", func(t *testing.T) {
+		in := url.Values{"tags": {"a,b;c"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got, urlvalues.WithDelimiters(";", ","), urlvalues.WithDelimiter(";")); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got.Tags, []string{"a,b", "c"}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+}
+
+func TestUnmarshal_WithSplitRepeated(t *testing.T) {
+	type Target struct {
+		Tags   []string       `urlvalue:"tags"`
+		Counts map[string]int `urlvalue:"counts"`
+	}
+
+	in := url.Values{"tags": {"a;b", "c"}, "counts": {"x:1", "y:2;z:3"}}
+	want := Target{Tags: []string{"a", "b", "c"}, Counts: map[string]int{"x": 1, "y": 2, "z": 3}}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithSplitRepeated()); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("max slice len", func(t *testing.T) {
+		var got Target
+		err := urlvalues.Unmarshal(in, &got, urlvalues.WithSplitRepeated(), urlvalues.WithMaxSliceLen(2))
+		var tooMany *urlvalues.TooManyValuesError
+		if !errors.As(err, &tooMany) || tooMany.Count != 3 {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want TooManyValuesError of 3 values", in, &got, err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		in := url.Values{"tags": {"a;b", "c"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got.Tags, []string{"a;b", "c"}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+}
+
 func TestUnmarshal_Validation(t *testing.T) {
 	t.Run("valid struct", func(t *testing.T) {
 		in := make(url.Values)
@@ -289,3 +368,1101 @@ func TestUnmarshal_Validation(t *testing.T) {
 func ptr[T any](v T) *T {
 	return &v
 }
+
+func TestUnmarshal_Alias(t *testing.T) {
+	type Target struct {
+		Query string `urlvalue:"q,alias:query | search"`
+	}
+
+	tests := []struct {
+		name         string
+		in           url.Values
+		want         Target
+		wantWarnings []urlvalues.Warning
+	}{
+		{"name", url.Values{"q": {"a"}}, Target{"a"}, nil},
+		{"alias", url.Values{"search": {"b"}}, Target{"b"}, nil},
+		{
+			"name precedes aliases",
+			url.Values{"q": {"a"}, "search": {"b"}},
+			Target{"a"},
+			[]urlvalues.Warning{{FieldName: "Query", Key: "search", Message: "value ignored in favour of value of q"}},
+		},
+		{
+			"aliases in listed order",
+			url.Values{"search": {"b"}, "query": {"c"}},
+			Target{"c"},
+			[]urlvalues.Warning{{FieldName: "Query", Key: "search", Message: "value ignored in favour of value of query"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got         Target
+				gotWarnings []urlvalues.Warning
+			)
+			err := urlvalues.Unmarshal(tt.in, &got, urlvalues.WithWarningHandler(func(w urlvalues.Warning) {
+				gotWarnings = append(gotWarnings, w)
+			}))
+			if err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+			if diff := cmp.Diff(gotWarnings, tt.wantWarnings); diff != "" {
+				t.Errorf("warnings -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Deprecated(t *testing.T) {
+	type Target struct {
+		PageSize int `urlvalue:"page_size,alias:limit,deprecated:per_page|size"`
+	}
+
+	tests := []struct {
+		name         string
+		in           url.Values
+		want         Target
+		wantWarnings []urlvalues.Warning
+	}{
+		{"name", url.Values{"page_size": {"10"}}, Target{10}, nil},
+		{"alias", url.Values{"limit": {"20"}}, Target{20}, nil},
+		{
+			"deprecated",
+			url.Values{"size": {"30"}},
+			Target{30},
+			[]urlvalues.Warning{{FieldName: "PageSize", Key: "size", Message: "key size is deprecated, use page_size instead"}},
+		},
+		{
+			"alias precedes deprecated",
+			url.Values{"per_page": {"30"}, "limit": {"20"}},
+			Target{20},
+			[]urlvalues.Warning{{FieldName: "PageSize", Key: "per_page", Message: "value ignored in favour of value of limit"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got         Target
+				gotWarnings []urlvalues.Warning
+			)
+			err := urlvalues.Unmarshal(tt.in, &got, urlvalues.WithWarningHandler(func(w urlvalues.Warning) {
+				gotWarnings = append(gotWarnings, w)
+			}))
+			if err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+			if diff := cmp.Diff(gotWarnings, tt.wantWarnings); diff != "" {
+				t.Errorf("warnings -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_WithPrefix(t *testing.T) {
+	type Filter struct {
+		Status string `urlvalue:"status,default:open"`
+		Owner  string `urlvalue:"owner"`
+	}
+	type Page struct {
+		Number int `urlvalue:"number,default:1"`
+		Size   int `urlvalue:"size,default:20"`
+	}
+
+	in := url.Values{
+		"filter.status": {"closed"},
+		"owner":         {"ignored"},
+		"page.size":     {"50"},
+	}
+
+	var gotFilter Filter
+	if err := urlvalues.Unmarshal(in, &gotFilter, urlvalues.WithPrefix("filter.")); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &gotFilter, err)
+	}
+	if diff := cmp.Diff(gotFilter, Filter{Status: "closed"}); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	var gotPage Page
+	if err := urlvalues.Unmarshal(in, &gotPage, urlvalues.WithPrefix("page.")); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &gotPage, err)
+	}
+	if diff := cmp.Diff(gotPage, Page{Number: 1, Size: 50}); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("error reports prefixed key", func(t *testing.T) {
+		in := url.Values{"page.size": {"big"}}
+		var target Page
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithPrefix("page."))
+
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, reflect.TypeOf(parseErr).String())
+		}
+		if parseErr.Key != "page.size" {
+			t.Errorf("Key = %q, want %q", parseErr.Key, "page.size")
+		}
+	})
+}
+
+func TestUnmarshal_NestedStructs(t *testing.T) {
+	t.Run("cyclic", func(t *testing.T) {
+		type Node struct {
+			Value int
+			Next  *Node
+		}
+
+		in := url.Values{"Value": {"1"}}
+		var target Node
+		err := urlvalues.Unmarshal(in, &target)
+		if err == nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+		if target.Next != nil {
+			t.Errorf("Next = %v, want <nil>", target.Next)
+		}
+	})
+
+	t.Run("lazy allocation", func(t *testing.T) {
+		type Inner struct {
+			Value int `urlvalue:"value"`
+		}
+		type Middle struct {
+			Inner *Inner
+		}
+		type Target struct {
+			Present *Middle
+			Absent  *struct {
+				Value int `urlvalue:"absent"`
+			}
+			Default *struct {
+				Value int `urlvalue:"default,default:1"`
+			}
+		}
+
+		in := url.Values{"value": {"1"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+
+		if got.Present == nil || got.Present.Inner == nil || got.Present.Inner.Value != 1 {
+			t.Errorf("Present = %+v, want Inner.Value 1", got.Present)
+		}
+		if got.Absent != nil {
+			t.Errorf("Absent = %+v, want <nil>", got.Absent)
+		}
+		if got.Default == nil || got.Default.Value != 1 {
+			t.Errorf("Default = %+v, want Value 1", got.Default)
+		}
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		type Filter struct {
+			Status string   `urlvalue:"status,default:open"`
+			Tags   []string `urlvalue:"tags,minitems:1"`
+		}
+		type Target struct {
+			Filter *Filter `urlvalue:"filter,optional"`
+		}
+
+		tests := []struct {
+			name string
+			in   url.Values
+			want *Filter
+		}{
+			{"absent", url.Values{}, nil},
+			{"present", url.Values{"tags": {"a"}}, &Filter{Status: "open", Tags: []string{"a"}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var got Target
+				if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+				}
+				if diff := cmp.Diff(got.Filter, tt.want); diff != "" {
+					t.Errorf("urlvalues.Unmarshal(...) Filter -got +want\n%s", diff)
+				}
+			})
+		}
+
+		t.Run("constraint", func(t *testing.T) {
+			in := url.Values{"status": {"closed"}}
+			var got Target
+			if err := urlvalues.Unmarshal(in, &got); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+			}
+		})
+
+		t.Run("not a struct pointer", func(t *testing.T) {
+			var target struct {
+				Filter Filter `urlvalue:"filter,optional"`
+			}
+			if err := urlvalues.Unmarshal(url.Values{}, &target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(..., %v) = <nil>, want error", &target)
+			}
+		})
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		typ := reflect.TypeOf(struct{ Value int }{})
+		for i := 0; i < 40; i++ {
+			typ = reflect.StructOf([]reflect.StructField{{Name: "Inner", Type: typ}})
+		}
+
+		in := url.Values{"Value": {"1"}}
+		target := reflect.New(typ).Interface()
+		if err := urlvalues.Unmarshal(in, target); err == nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, target)
+		}
+	})
+}
+
+func TestUnmarshal_Dedupe(t *testing.T) {
+	type Target struct {
+		Tags  []string `urlvalue:"tags,dedupe"`
+		Other []string `urlvalue:"other"`
+	}
+
+	in := url.Values{
+		"tags":  {"a", "b", "a", "c", "b"},
+		"other": {"x", "x"},
+	}
+
+	tests := []struct {
+		name string
+		opts []urlvalues.SetParseOptionFunc
+		want Target
+	}{
+		{"tag", nil, Target{Tags: []string{"a", "b", "c"}, Other: []string{"x", "x"}}},
+		{"option", []urlvalues.SetParseOptionFunc{urlvalues.WithDedupeSlices()}, Target{Tags: []string{"a", "b", "c"}, Other: []string{"x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(in, &got, tt.opts...); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+			}
+
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_Sort(t *testing.T) {
+	type Target struct {
+		Tags  []string    `urlvalue:"tags,sort,lower,dedupe"`
+		IDs   []int       `urlvalue:"ids,sort"`
+		Dates []time.Time `urlvalue:"dates,sort,layout:2006-01-02"`
+	}
+
+	in := url.Values{
+		"tags":  {"b", "A", "a", "C"},
+		"ids":   {"10;9;100"},
+		"dates": {"2023-02-01;2022-12-31"},
+	}
+	want := Target{
+		Tags: []string{"a", "b", "c"},
+		IDs:  []int{9, 10, 100},
+		Dates: []time.Time{
+			time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("unsortable type", func(t *testing.T) {
+		var target struct {
+			Flags []bool `urlvalue:"flags,sort"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_Normalize(t *testing.T) {
+	type Target struct {
+		Name   string            `urlvalue:"name,trim"`
+		Code   string            `urlvalue:"code,trim,upper"`
+		Tags   []string          `urlvalue:"tags,trim,lower,dedupe"`
+		Limit  int               `urlvalue:"limit,trim"`
+		Labels map[string]string `urlvalue:"labels,trim"`
+		Mode   string            `urlvalue:"mode,upper,default:fast"`
+	}
+
+	in := url.Values{
+		"name":   {"  Foo Bar "},
+		"code":   {" se "},
+		"tags":   {" Go ; go;RUST "},
+		"limit":  {" 10 "},
+		"labels": {" a : b "},
+	}
+	want := Target{
+		Name:   "Foo Bar",
+		Code:   "SE",
+		Tags:   []string{"go", "rust"},
+		Limit:  10,
+		Labels: map[string]string{"a": "b"},
+		Mode:   "FAST",
+	}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("lower and upper", func(t *testing.T) {
+		var target struct {
+			Name string `urlvalue:"name,lower,upper"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestWithValueTransformer(t *testing.T) {
+	type Target struct {
+		Name  string   `urlvalue:"name"`
+		Email string   `urlvalue:"email"`
+		Tags  []string `urlvalue:"tags"`
+		Mode  string   `urlvalue:"mode,default:Fast"`
+	}
+
+	in := url.Values{"name": {" Foo "}, "email": {" Foo@Example.com "}, "tags": {" a ", "b "}}
+	transform := func(key, value string) string {
+		value = strings.TrimSpace(value)
+		if key == "email" || key == "mode" {
+			value = strings.ToLower(value)
+		}
+		return value
+	}
+	want := Target{Name: "Foo", Email: "foo@example.com", Tags: []string{"a", "b"}, Mode: "Fast"}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithValueTransformer(transform)); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+	if in.Get("name") != " Foo " {
+		t.Errorf("urlvalues.Unmarshal(...) modified the values of the caller: %v", in)
+	}
+}
+
+func TestUnmarshal_RepeatedKeys(t *testing.T) {
+	type Target struct {
+		Repeated  []string          `urlvalue:"repeated"`
+		Delimited []string          `urlvalue:"delimited"`
+		Pairs     map[string]string `urlvalue:"pairs"`
+		Joined    string            `urlvalue:"joined"`
+	}
+
+	in := url.Values{
+		"repeated":  {"a;b", "c"},
+		"delimited": {"a;b;c"},
+		"pairs":     {"a:1", "b:2"},
+		"joined":    {"a", "b"},
+	}
+	want := Target{
+		Repeated:  []string{"a;b", "c"},
+		Delimited: []string{"a", "b", "c"},
+		Pairs:     map[string]string{"a": "1", "b": "2"},
+		Joined:    "a;b",
+	}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshal_TagEscaping(t *testing.T) {
+	type Target struct {
+		Quoted  string   `urlvalue:"quoted,default:'a,b:c'"`
+		Escaped string   `urlvalue:"escaped,default:a\\,b"`
+		Spaces  string   `urlvalue:"spaces,default:' x '"`
+		URL     string   `urlvalue:"url,default:https://example.com/?a=1"`
+		List    []string `urlvalue:"list,default:'x,y';z,dedupe"`
+	}
+
+	var got Target
+	in := make(url.Values)
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+
+	want := Target{
+		Quoted:  "a,b:c",
+		Escaped: "a,b",
+		Spaces:  " x ",
+		URL:     "https://example.com/?a=1",
+		List:    []string{"x,y", "z"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		var target struct {
+			Value string `urlvalue:"value,default:'a,b"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+
+	t.Run("trailing backslash", func(t *testing.T) {
+		var target struct {
+			Value string `urlvalue:"value,default:a\\"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_WithStrictNumbers(t *testing.T) {
+	type Target struct {
+		ID    int  `urlvalue:"id"`
+		Count uint `urlvalue:"count"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		want    Target
+		wantErr bool
+	}{
+		{"decimal", url.Values{"id": {"-42"}, "count": {"0"}}, Target{ID: -42}, false},
+		{"leading zero", url.Values{"id": {"0755"}}, Target{ID: 755}, false},
+		{"hex", url.Values{"id": {"0x1F"}}, Target{}, true},
+		{"octal", url.Values{"count": {"0o17"}}, Target{}, true},
+		{"underscore", url.Values{"id": {"1_000"}}, Target{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			err := urlvalues.Unmarshal(tt.in, &got, urlvalues.WithStrictNumbers())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", tt.in, &got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		in := url.Values{"id": {"0x1F"}, "count": {"0755"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		if diff := cmp.Diff(got, Target{ID: 31, Count: 493}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+}
+
+func TestUnmarshal_Overflow(t *testing.T) {
+	type Target struct {
+		Small  int8     `urlvalue:"small"`
+		Big    int64    `urlvalue:"big"`
+		Byte   uint8    `urlvalue:"byte"`
+		Count  uint     `urlvalue:"count"`
+		Counts []uint16 `urlvalue:"counts"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.OverflowError
+		wantMsg string
+	}{
+		{"int8", url.Values{"small": {"300"}}, &urlvalues.OverflowError{Min: -128, Max: 127}, "value out of range, must be between -128 and 127"},
+		{"int64", url.Values{"big": {"-9223372036854775809"}}, &urlvalues.OverflowError{Min: math.MinInt64, Max: math.MaxInt64}, ""},
+		{"uint8", url.Values{"byte": {"256"}}, &urlvalues.OverflowError{Max: 255}, "value out of range, must be between 0 and 255"},
+		{"negative uint", url.Values{"count": {"-1"}}, &urlvalues.OverflowError{Max: math.MaxUint64}, ""},
+		{"element", url.Values{"counts": {"1;70000"}}, &urlvalues.OverflowError{Max: math.MaxUint16}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target)
+
+			var got *urlvalues.OverflowError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr, cmpopts.IgnoreUnexported(urlvalues.OverflowError{})); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+			if tt.wantMsg != "" && got.Error() != tt.wantMsg {
+				t.Errorf("OverflowError.Error() = %q, want %q", got.Error(), tt.wantMsg)
+			}
+			var parseErr *urlvalues.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Code() != urlvalues.CodeOutOfRange {
+				t.Errorf("urlvalues.Unmarshal(...) = %v, want ParseError with code %q", err, urlvalues.CodeOutOfRange)
+			}
+		})
+	}
+
+	t.Run("invalid syntax", func(t *testing.T) {
+		in := url.Values{"count": {"-abc"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+		var got *urlvalues.OverflowError
+		if errors.As(err, &got) {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want no OverflowError", in, &target, err)
+		}
+	})
+}
+
+func TestUnmarshal_BoolValues(t *testing.T) {
+	type Target struct {
+		Active bool   `urlvalue:"active,truevals:enabled|yes,falsevals:disabled|no"`
+		Flags  []bool `urlvalue:"flags,truevals:on,falsevals:off"`
+		Public *bool  `urlvalue:"public,truevals:y,default:y"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		want    Target
+		wantErr bool
+	}{
+		{"true value", url.Values{"active": {"Enabled"}}, Target{Active: true, Public: ptr(true)}, false},
+		{"false value", url.Values{"active": {"no"}, "public": {"false"}}, Target{Public: ptr(false)}, false},
+		{"fallback", url.Values{"active": {"true"}}, Target{Active: true, Public: ptr(true)}, false},
+		{"slice", url.Values{"flags": {"on;off;1"}}, Target{Flags: []bool{true, false, true}, Public: ptr(true)}, false},
+		{"invalid", url.Values{"active": {"maybe"}}, Target{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			err := urlvalues.Unmarshal(tt.in, &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", tt.in, &got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("encode", func(t *testing.T) {
+		in := Target{Active: true, Flags: []bool{true, false}, Public: ptr(false)}
+		got, err := urlvalues.Marshal(in)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+		}
+		want := url.Values{"active": {"enabled"}, "flags": {"on", "off"}, "public": {"false"}}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Name string `urlvalue:"name,truevals:yes"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_Unescape(t *testing.T) {
+	type Target struct {
+		Redirect string   `urlvalue:"redirect,unescape"`
+		Name     string   `urlvalue:"name"`
+		Tags     []string `urlvalue:"tags,unescape"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		opts []urlvalues.SetParseOptionFunc
+		want Target
+	}{
+		{
+			name: "unescape",
+			in:   url.Values{"redirect": {"https%3A%2F%2Fexample.com%2F%3Fa%3Db"}, "name": {"a%20b"}, "tags": {"a%3Bb", "c+d"}},
+			want: Target{Redirect: "https://example.com/?a=b", Name: "a%20b", Tags: []string{"a;b", "c d"}},
+		},
+		{
+			name: "WithUnescape",
+			in:   url.Values{"name": {"a%20b"}},
+			opts: []urlvalues.SetParseOptionFunc{urlvalues.WithUnescape()},
+			want: Target{Name: "a b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got, tt.opts...); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid escape", func(t *testing.T) {
+		in := url.Values{"redirect": {"100%"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+
+		var parseErr *urlvalues.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", in, &target, err)
+		}
+		if parseErr.Code() != urlvalues.CodeInvalidSyntax {
+			t.Errorf("ParseError.Code() = %q, want %q", parseErr.Code(), urlvalues.CodeInvalidSyntax)
+		}
+		want := `error parsing value of redirect: unescaping value: invalid URL escape "%"`
+		if err.Error() != want {
+			t.Errorf("urlvalues.Unmarshal(...) = %q, want %q", err, want)
+		}
+	})
+}
+
+func TestUnmarshal_WithPlusAsSpace(t *testing.T) {
+	type Target struct {
+		Query  string            `urlvalue:"q"`
+		Count  int               `urlvalue:"count"`
+		Tags   []string          `urlvalue:"tags"`
+		Labels map[string]string `urlvalue:"labels"`
+		Sign   string            `urlvalue:"sign,default:a+b"`
+	}
+
+	in := url.Values{"q": {"hello+world"}, "count": {"+5"}, "tags": {"a+b;c"}, "labels": {"x+y:1+2"}}
+	want := Target{
+		Query:  "hello world",
+		Count:  5,
+		Tags:   []string{"a b", "c"},
+		Labels: map[string]string{"x y": "1 2"},
+		Sign:   "a+b",
+	}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got, urlvalues.WithPlusAsSpace()); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+}
+
+func TestUnmarshal_WithAllErrors(t *testing.T) {
+	type Target struct {
+		A    int    `urlvalue:"a"`
+		B    int    `urlvalue:"b,max:5"`
+		C    bool   `urlvalue:"c"`
+		Name string `urlvalue:"name"`
+		D    uint8  `urlvalue:"d"`
+	}
+
+	in := url.Values{"a": {"x"}, "b": {"10"}, "c": {"maybe"}, "name": {"alice"}, "d": {"300"}}
+
+	keysOf := func(err error) []string {
+		var keys []string
+		for _, err := range err.(urlvalues.Errors) {
+			var parseErr *urlvalues.ParseError
+			if errors.As(err, &parseErr) {
+				keys = append(keys, parseErr.Key)
+			}
+		}
+		return keys
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+		if _, ok := err.(*urlvalues.ParseError); !ok {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", in, &target, err)
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithAllErrors())
+		if _, ok := err.(urlvalues.Errors); !ok {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want urlvalues.Errors", in, &target, err)
+		}
+		if diff := cmp.Diff(keysOf(err), []string{"a", "b", "c", "d"}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) error keys -got +want\n%s", diff)
+		}
+		if target.Name != "alice" {
+			t.Errorf("urlvalues.Unmarshal(...) Name = %q, want %q", target.Name, "alice")
+		}
+	})
+
+	t.Run("max", func(t *testing.T) {
+		var target Target
+		err := urlvalues.Unmarshal(in, &target, urlvalues.WithAllErrors(), urlvalues.WithMaxErrors(2))
+		errs, ok := err.(urlvalues.Errors)
+		if !ok || len(errs) != 3 {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want 3 urlvalues.Errors", in, &target, err)
+		}
+		if diff := cmp.Diff(keysOf(err), []string{"a", "b"}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) error keys -got +want\n%s", diff)
+		}
+		if diff := cmp.Diff(errs[2], error(&urlvalues.TooManyErrorsError{Omitted: 2})); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) last error -got +want\n%s", diff)
+		}
+		if got, want := errs[2].Error(), "and 2 more errors"; got != want {
+			t.Errorf("TooManyErrorsError.Error() = %q, want %q", got, want)
+		}
+		if got := urlvalues.NewProblem(err); got.Detail != "and 2 more errors" || len(got.InvalidParams) != 2 {
+			t.Errorf("urlvalues.NewProblem(%v) = %+v, want 2 invalid params and detail", err, got)
+		}
+	})
+}
+
+func TestParseError_MarshalJSON(t *testing.T) {
+	type Target struct {
+		Page int `urlvalue:"page,max:100"`
+	}
+
+	in := url.Values{"page": {"500"}}
+	var target Target
+	err := urlvalues.Unmarshal(in, &target)
+
+	var parseErr *urlvalues.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want *urlvalues.ParseError", in, &target, err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"ParseError", parseErr, `{"field":"Page","key":"page","value":"500","expected_type":"int","message":"got 500, want at most 100"}`},
+		{"FieldError", errors.Unwrap(parseErr), `{"field":"Page","value":"500","expected_type":"int","message":"got 500, want at most 100"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.err)
+			if err != nil {
+				t.Fatalf("json.Marshal(%v) = %q, want <nil>", tt.err, err)
+			}
+			if diff := cmp.Diff(string(got), tt.want); diff != "" {
+				t.Errorf("json.Marshal(%v) -got +want\n%s", tt.err, diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_UintptrAndRune(t *testing.T) {
+	type Target struct {
+		Addr  uintptr `urlvalue:"addr"`
+		Sep   rune    `urlvalue:"sep"`
+		Seps  []rune  `urlvalue:"seps"`
+		Count int32   `urlvalue:"count"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{"uintptr", url.Values{"addr": {"0x10"}}, Target{Addr: 16}},
+		{"character", url.Values{"sep": {"é"}}, Target{Sep: 'é'}},
+		{"code point", url.Values{"sep": {"44"}}, Target{Sep: ','}},
+		{"digit", url.Values{"sep": {"7"}}, Target{Sep: 7}},
+		{"slice", url.Values{"seps": {",;|"}}, Target{Seps: []rune{',', '|'}}},
+		{"slice elements", url.Values{"seps": {"a", "-", "b"}}, Target{Seps: []rune{'a', '-', 'b'}}},
+		{"int32", url.Values{"count": {"-3"}}, Target{Count: -3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, in := range []url.Values{{"sep": {"ab"}}, {"addr": {"-1"}}} {
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+		}
+	}
+
+	in := Target{Addr: 16, Sep: 'x'}
+	encoded, err := urlvalues.Marshal(in)
+	if err != nil {
+		t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", in, err)
+	}
+	if diff := cmp.Diff(encoded, url.Values{"addr": {"16"}, "sep": {"120"}}); diff != "" {
+		t.Errorf("urlvalues.Marshal(%v) -got +want\n%s", in, diff)
+	}
+}
+
+func TestUnmarshal_PointerCollections(t *testing.T) {
+	type Target struct {
+		Ints    *[]int            `urlvalue:"ints,sort"`
+		Strings []*string         `urlvalue:"strings,dedupe,sort,lower"`
+		Times   []*time.Time      `urlvalue:"times,layout:DateOnly,sort"`
+		Both    *[]*int           `urlvalue:"both,max:5"`
+		Map     *map[string]*int  `urlvalue:"map"`
+		Sort    *urlvalues.Sort   `urlvalue:"sort"`
+		Sorts   []*urlvalues.Sort `urlvalue:"sorts"`
+	}
+
+	in := url.Values{
+		"ints":    {"3;1;2"},
+		"strings": {"b", "A", "a"},
+		"times":   {"2024-01-02;2023-01-01"},
+		"both":    {"5;4"},
+		"map":     {"a:1;b:2"},
+		"sort":    {"-name"},
+		"sorts":   {"name", "-age"},
+	}
+	want := Target{
+		Ints:    &[]int{1, 2, 3},
+		Strings: []*string{ptr("a"), ptr("b")},
+		Times: []*time.Time{
+			ptr(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+			ptr(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+		},
+		Both:  &[]*int{ptr(5), ptr(4)},
+		Map:   &map[string]*int{"a": ptr(1), "b": ptr(2)},
+		Sort:  &urlvalues.Sort{{Field: "name", Descending: true}},
+		Sorts: []*urlvalues.Sort{{{Field: "name"}}, {{Field: "age", Descending: true}}},
+	}
+
+	var got Target
+	if err := urlvalues.Unmarshal(in, &got); err != nil {
+		t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+	}
+
+	t.Run("absent", func(t *testing.T) {
+		var got Target
+		if err := urlvalues.Unmarshal(url.Values{}, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", url.Values{}, &got, err)
+		}
+		if diff := cmp.Diff(got, Target{}); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("constraint", func(t *testing.T) {
+		in := url.Values{"both": {"4;9"}}
+		var got Target
+		if err := urlvalues.Unmarshal(in, &got); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &got)
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		encoded, err := urlvalues.Marshal(want)
+		if err != nil {
+			t.Fatalf("urlvalues.Marshal(%v) = %q, want <nil>", want, err)
+		}
+		var got Target
+		if err := urlvalues.Unmarshal(encoded, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", encoded, &got, err)
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(urlvalues.Marshal(...)) -got +want\n%s", diff)
+		}
+	})
+}
+
+func TestUnmarshal_DefaultIf(t *testing.T) {
+	type Target struct {
+		Sort  string `urlvalue:"sort,default:relevance,default_if:view=compact:name,default_if:view=grid:date"`
+		Limit int    `urlvalue:"limit,default_if:view=compact:50"`
+		View  string `urlvalue:"view,default:full,lower"`
+	}
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{"no condition holds", url.Values{}, Target{Sort: "relevance", View: "full"}},
+		{"first condition", url.Values{"view": {"compact"}}, Target{Sort: "name", Limit: 50, View: "compact"}},
+		{"second condition", url.Values{"view": {"grid"}}, Target{Sort: "date", View: "grid"}},
+		{"normalized", url.Values{"view": {"COMPACT"}}, Target{Sort: "name", Limit: 50, View: "compact"}},
+		{"value wins", url.Values{"view": {"compact"}, "sort": {"price"}}, Target{Sort: "price", Limit: 50, View: "compact"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("condition on default", func(t *testing.T) {
+		var got struct {
+			Sort string `urlvalue:"sort,default_if:view=compact:name"`
+			View string `urlvalue:"view,default:compact"`
+		}
+		if err := urlvalues.Unmarshal(url.Values{}, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(...) = %q, want <nil>", err)
+		}
+		if got.Sort != "name" {
+			t.Errorf("urlvalues.Unmarshal(...) Sort = %q, want %q", got.Sort, "name")
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		targets := []any{
+			&struct {
+				Sort string `urlvalue:"sort,default_if:view:name"`
+			}{},
+			&struct {
+				Sort string `urlvalue:"sort,default_if:mode=compact:name"`
+			}{},
+		}
+		for _, target := range targets {
+			if err := urlvalues.Unmarshal(url.Values{}, target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %T) = <nil>, want error", url.Values{}, target)
+			}
+		}
+	})
+}
+
+func TestUnmarshal_DefaultReference(t *testing.T) {
+	type Target struct {
+		Until   time.Time  `urlvalue:"until,layout:DateOnly,default:@since+1m-1d"`
+		Ends    *time.Time `urlvalue:"ends,layout:DateOnly,default:@until"`
+		Since   time.Time  `urlvalue:"since,layout:DateOnly"`
+		PerPage int        `urlvalue:"per-page,default:@page-size"`
+		Size    int        `urlvalue:"page-size,default:20"`
+	}
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name string
+		in   url.Values
+		want Target
+	}{
+		{"referenced absent", url.Values{}, Target{PerPage: 20, Size: 20}},
+		{
+			"offsets",
+			url.Values{"since": {"2024-01-15"}},
+			Target{Until: date(2024, 2, 14), Ends: ptr(date(2024, 2, 14)), Since: date(2024, 1, 15), PerPage: 20, Size: 20},
+		},
+		{
+			"value wins",
+			url.Values{"since": {"2024-01-15"}, "until": {"2024-01-20"}, "per-page": {"5"}, "page-size": {"10"}},
+			Target{Until: date(2024, 1, 20), Ends: ptr(date(2024, 1, 20)), Since: date(2024, 1, 15), PerPage: 5, Size: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			if err := urlvalues.Unmarshal(tt.in, &got); err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("preset", func(t *testing.T) {
+		in := url.Values{"since": {"2024-01-15"}, "page-size": {"7"}}
+		preset := date(2024, 3, 1)
+		got := Target{PerPage: 99, Ends: &preset}
+		if err := urlvalues.Unmarshal(in, &got); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &got, err)
+		}
+		want := Target{Until: date(2024, 2, 14), Ends: ptr(preset), Since: date(2024, 1, 15), PerPage: 99, Size: 7}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		in := url.Values{"since": {"2024-01-15"}, "name": {"x"}}
+		targets := []any{
+			&struct {
+				Until time.Time `urlvalue:"until,default:@start"`
+			}{},
+			&struct {
+				A string `urlvalue:"a,default:@b"`
+				B string `urlvalue:"b,default:@a"`
+			}{},
+			&struct {
+				Since time.Time `urlvalue:"since,layout:DateOnly"`
+				Until string    `urlvalue:"until,default:@since"`
+			}{},
+			&struct {
+				Name  string `urlvalue:"name"`
+				Other string `urlvalue:"other,default:@name+1d"`
+			}{},
+		}
+		for _, target := range targets {
+			if err := urlvalues.Unmarshal(in, target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %T) = <nil>, want error", in, target)
+			}
+		}
+	})
+}
@@ -0,0 +1,221 @@
+// Package urlvaluestest provides helpers for testing that struct types survive
+// round trips through [urlvalues.Marshal] and [urlvalues.Unmarshal], which
+// verifies their struct tags and custom types.
+package urlvaluestest
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/nahojer/urlvalues"
+)
+
+// Check reports an error through t unless v, a struct or struct pointer,
+// survives a round trip. That is, the URL values that v marshals into must
+// unmarshal into a struct that marshals into the same URL values. The same
+// options are used for marshaling and unmarshaling.
+//
+// Comparing URL values rather than structs allows for lossy layouts, such as a
+// time.Time field with a layout without time of day. Values equal to default
+// values are omitted from the compared URL values, as if the
+// [urlvalues.WithOmitDefaults] option was passed, since absent keys decode
+// into default values.
+func Check(t testing.TB, v any, setParseOpts ...urlvalues.SetParseOptionFunc) {
+	t.Helper()
+
+	if err := check(v, setParseOpts); err != nil {
+		t.Error(err)
+	}
+}
+
+// CheckRandom runs [Check] on n random values of type T, which must be a
+// struct type, generated by [Generate]. Values violating constraints declared
+// in struct tags, such as "min" and "oneof", fail to unmarshal and are skipped.
+func CheckRandom[T any](t testing.TB, n int, setParseOpts ...urlvalues.SetParseOptionFunc) {
+	t.Helper()
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < n; i++ {
+		v := Generate[T](rnd)
+		err := check(&v, setParseOpts)
+		var constraintErr *urlvalues.ConstraintError
+		if errors.As(err, &constraintErr) {
+			continue
+		}
+		if err != nil {
+			t.Errorf("value %d of %d: %v", i+1, n, err)
+			return
+		}
+	}
+}
+
+// check returns an error unless v survives a round trip. See Check.
+func check(v any, setParseOpts []urlvalues.SetParseOptionFunc) error {
+	typ := reflect.TypeOf(v)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return urlvalues.ErrInvalidStruct
+	}
+
+	marshalOpts := append(slices.Clip(setParseOpts), urlvalues.WithOmitDefaults())
+
+	want, err := urlvalues.Marshal(v, marshalOpts...)
+	if err != nil {
+		return &RoundTripError{Value: v, Err: err}
+	}
+
+	decoded := reflect.New(typ).Interface()
+	if err := urlvalues.Unmarshal(want, decoded, setParseOpts...); err != nil {
+		return &RoundTripError{Value: v, Encoded: want, Err: err}
+	}
+
+	got, err := urlvalues.Marshal(decoded, marshalOpts...)
+	if err != nil {
+		return &RoundTripError{Value: v, Encoded: want, Err: err}
+	}
+	if !reflect.DeepEqual(got, want) {
+		return &RoundTripError{Value: v, Encoded: want, Reencoded: got}
+	}
+
+	return nil
+}
+
+// RoundTripError describes a value that failed to survive a round trip.
+type RoundTripError struct {
+	// Value that failed to survive the round trip.
+	Value any
+	// URL values that Value marshaled into, if any.
+	Encoded url.Values
+	// URL values that the decoded Value marshaled into, if any.
+	Reencoded url.Values
+	// Error marshaling or unmarshaling, if any.
+	Err error
+}
+
+func (err *RoundTripError) Error() string {
+	switch {
+	case err.Err != nil && err.Encoded == nil:
+		return fmt.Sprintf("urlvaluestest: marshaling %+v: %v", err.Value, err.Err)
+	case err.Err != nil:
+		return fmt.Sprintf("urlvaluestest: round trip of %+v via %v: %v", err.Value, err.Encoded, err.Err)
+	default:
+		return fmt.Sprintf("urlvaluestest: round trip of %+v encoded %v, then %v", err.Value, err.Encoded, err.Reencoded)
+	}
+}
+
+// Unwrap returns the underlying error, if any.
+func (err *RoundTripError) Unwrap() error {
+	return err.Err
+}
+
+// Generate returns a random value of type T. Strings are generated from a set
+// of characters excluding the delimiter (;) and the separator of map keys and
+// values (:), which don't survive round trips when part of values.
+//
+// Types implementing [quick.Generator] generate themselves. Other types
+// implementing [encoding.TextUnmarshaler] or [encoding.BinaryUnmarshaler],
+// except [time.Time], as well as interfaces, channels and functions, are left
+// as zero values.
+func Generate[T any](rnd *rand.Rand) T {
+	var v T
+	generate(rnd, reflect.ValueOf(&v).Elem(), 0)
+	return v
+}
+
+// maxGenerateDepth bounds the depth of generated values of recursive types.
+const maxGenerateDepth = 8
+
+var (
+	timeType              = reflect.TypeFor[time.Time]()
+	generatorType         = reflect.TypeFor[quick.Generator]()
+	textUnmarshalerType   = reflect.TypeFor[encoding.TextUnmarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+const letters = "abcxyzABCXYZ0189 -_.~/?&=+%éß漢"
+
+func generate(rnd *rand.Rand, v reflect.Value, depth int) {
+	typ := v.Type()
+
+	switch {
+	case typ.Implements(generatorType):
+		v.Set(reflect.Zero(typ).Interface().(quick.Generator).Generate(rnd, 3))
+		return
+	case typ == timeType:
+		// Years with two digits in the default layout range from 1969 to
+		// 2068.
+		sec := rnd.Int63n(int64(50 * 365 * 24 * time.Hour / time.Second))
+		v.Set(reflect.ValueOf(time.Unix(946684800+sec, 0).UTC()))
+		return
+	case typ.Implements(textUnmarshalerType), reflect.PointerTo(typ).Implements(textUnmarshalerType),
+		typ.Implements(binaryUnmarshalerType), reflect.PointerTo(typ).Implements(binaryUnmarshalerType):
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		runes := []rune(letters)
+		b := make([]rune, rnd.Intn(8))
+		for i := range b {
+			b[i] = runes[rnd.Intn(len(runes))]
+		}
+		v.SetString(string(b))
+	case reflect.Bool:
+		v.SetBool(rnd.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(rnd.Int63() >> (64 - typ.Bits()))
+		if rnd.Intn(2) == 1 {
+			v.SetInt(-v.Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(rnd.Uint64() >> (64 - typ.Bits()))
+	case reflect.Float32:
+		v.SetFloat(float64(rnd.Float32()) * float64(rnd.Intn(1000)-500))
+	case reflect.Float64:
+		v.SetFloat(rnd.NormFloat64() * 1000)
+	case reflect.Ptr:
+		if depth >= maxGenerateDepth || rnd.Intn(4) == 0 {
+			return
+		}
+		v.Set(reflect.New(typ.Elem()))
+		generate(rnd, v.Elem(), depth+1)
+	case reflect.Slice:
+		n := rnd.Intn(4)
+		if depth >= maxGenerateDepth {
+			n = 0
+		}
+		v.Set(reflect.MakeSlice(typ, n, n))
+		for i := 0; i < n; i++ {
+			generate(rnd, v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		n := rnd.Intn(4)
+		if depth >= maxGenerateDepth {
+			n = 0
+		}
+		v.Set(reflect.MakeMapWithSize(typ, n))
+		for i := 0; i < n; i++ {
+			k := reflect.New(typ.Key()).Elem()
+			generate(rnd, k, depth+1)
+			e := reflect.New(typ.Elem()).Elem()
+			generate(rnd, e, depth+1)
+			v.SetMapIndex(k, e)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				generate(rnd, v.Field(i), depth+1)
+			}
+		}
+	}
+}
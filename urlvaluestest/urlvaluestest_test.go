@@ -0,0 +1,111 @@
+package urlvaluestest_test
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nahojer/urlvalues"
+	"github.com/nahojer/urlvalues/urlvaluestest"
+)
+
+// ip generates IPv4 addresses.
+type ip struct{ net.IP }
+
+func (ip) Generate(rnd *rand.Rand, _ int) reflect.Value {
+	return reflect.ValueOf(ip{net.IPv4(byte(rnd.Intn(256)), byte(rnd.Intn(256)), byte(rnd.Intn(256)), byte(rnd.Intn(256)))})
+}
+
+type Page struct {
+	Number int `urlvalue:"page,default:1"`
+	Size   int `urlvalue:"size,default:20,min:1,max:100"`
+}
+
+type Params struct {
+	Query   string             `urlvalue:"q"`
+	Tags    []string           `urlvalue:"tags,lower,sort"`
+	IDs     []uint16           `urlvalue:"ids"`
+	Weights map[string]float64 `urlvalue:"weights"`
+	Exact   bool               `urlvalue:"exact,default:true"`
+	Since   time.Time          `urlvalue:"since,layout:2006-01-02"`
+	Until   *time.Time         `urlvalue:"until,layout:RFC3339"`
+	Wait    time.Duration      `urlvalue:"wait"`
+	Addr    ip                 `urlvalue:"addr"`
+	Page    *Page
+}
+
+func TestCheck(t *testing.T) {
+	urlvaluestest.Check(t, Params{Query: "shoes", Tags: []string{"b", "A"}, Page: &Page{Number: 2, Size: 50}})
+}
+
+func TestCheckRandom(t *testing.T) {
+	urlvaluestest.CheckRandom[Params](t, 200)
+	urlvaluestest.CheckRandom[Params](t, 200, urlvalues.WithKeyStyle(urlvalues.DotKeys), urlvalues.WithUnixTime())
+}
+
+// lossy loses everything after a comma when decoded.
+type lossy string
+
+func (l *lossy) UnmarshalText(text []byte) error {
+	s, _, _ := strings.Cut(string(text), ",")
+	*l = lossy(s)
+	return nil
+}
+
+func TestCheck_Failure(t *testing.T) {
+	type Target struct {
+		Value lossy `urlvalue:"value"`
+	}
+
+	rec := &recorder{TB: t}
+	urlvaluestest.Check(rec, Target{Value: "a,b"})
+	if len(rec.errs) != 1 {
+		t.Fatalf("urlvaluestest.Check(...) reported %d errors, want 1", len(rec.errs))
+	}
+
+	var rtErr *urlvaluestest.RoundTripError
+	if !errors.As(rec.errs[0], &rtErr) {
+		t.Fatalf("urlvaluestest.Check(...) reported %v, want *urlvaluestest.RoundTripError", rec.errs[0])
+	}
+	if got, want := rtErr.Reencoded.Get("value"), "a"; got != want {
+		t.Errorf("Reencoded value = %q, want %q", got, want)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		p := urlvaluestest.Generate[Params](rnd)
+		if strings.ContainsAny(p.Query, ";:") {
+			t.Fatalf("Generate[Params](...).Query = %q, want no delimiters", p.Query)
+		}
+		if !p.Since.IsZero() && p.Since.Location() != time.UTC {
+			t.Fatalf("Generate[Params](...).Since = %v, want UTC", p.Since)
+		}
+	}
+}
+
+// recorder records errors reported through it.
+type recorder struct {
+	testing.TB
+	errs []error
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Error(args ...any) {
+	if err, ok := args[0].(error); ok && len(args) == 1 {
+		r.errs = append(r.errs, err)
+		return
+	}
+	r.errs = append(r.errs, errors.New(fmt.Sprint(args...)))
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Errorf(format, args...))
+}
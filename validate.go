@@ -0,0 +1,472 @@
+package urlvalues
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConstraintError occurs when a field violates a constraint declared by an
+// option in its tag, such as the "maxitems" option.
+type ConstraintError struct {
+	// Name of the tag option declaring the constraint.
+	Constraint string
+	// Value of the tag option.
+	Limit string
+
+	msg string
+}
+
+func (err *ConstraintError) Error() string {
+	return err.msg
+}
+
+// ValidationError occurs when a value is rejected by a validator named by the
+// "validate" option in the tag of its field. See [Decoder.RegisterValidation].
+type ValidationError struct {
+	// Name of the validator.
+	Validator string
+
+	err error
+}
+
+func (err *ValidationError) Error() string {
+	return err.err.Error()
+}
+
+// Unwrap returns the error returned by the validator.
+func (err *ValidationError) Unwrap() error {
+	return err.err
+}
+
+// ElementError occurs when an element of a slice field violates a constraint.
+// All elements violating constraints are reported together as [Errors].
+type ElementError struct {
+	// Index of the element in the slice.
+	Index int
+	// Constraint violated by the element.
+	Err *ConstraintError
+}
+
+func (err *ElementError) Error() string {
+	return fmt.Sprintf("element %d: %s", err.Index, err.Err)
+}
+
+// Unwrap returns the underlying [ConstraintError].
+func (err *ElementError) Unwrap() error {
+	return err.Err
+}
+
+// validateField returns an error if the value of field violates any of the
+// constraints declared in its tag. Constraints on the values themselves are
+// only checked if present is true, while constraints on the number of items
+// are always checked.
+func validateField(field field, present bool) error {
+	fOpts := field.options
+
+	if fOpts.minItems != nil || fOpts.maxItems != nil {
+		n := itemCount(field.field)
+		if fOpts.minItems != nil && n < *fOpts.minItems {
+			return &ConstraintError{
+				Constraint: "minitems",
+				Limit:      fmt.Sprint(*fOpts.minItems),
+				msg:        fmt.Sprintf("got %d items, want at least %d", n, *fOpts.minItems),
+			}
+		}
+		if fOpts.maxItems != nil && n > *fOpts.maxItems {
+			return &ConstraintError{
+				Constraint: "maxitems",
+				Limit:      fmt.Sprint(*fOpts.maxItems),
+				msg:        fmt.Sprintf("got %d items, want at most %d", n, *fOpts.maxItems),
+			}
+		}
+	}
+
+	if !present || !fOpts.hasValueConstraints() {
+		return nil
+	}
+
+	v := field.field
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice || !holdsMultiple(v) {
+		if err := validateValue(v, fOpts); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	// Report all elements violating constraints, not just the first.
+	var errs Errors
+	for i := 0; i < v.Len(); i++ {
+		if err := validateValue(v.Index(i), fOpts); err != nil {
+			errs = append(errs, &ElementError{Index: i, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// hasValueConstraints reports whether any constraints on the values of a field
+// are declared.
+func (fOpts fieldOptions) hasValueConstraints() bool {
+	return fOpts.min != nil || fOpts.max != nil || fOpts.oneOf != nil || fOpts.pattern != nil ||
+		fOpts.before != "" || fOpts.after != ""
+}
+
+// validateValue returns a ConstraintError if v violates any of the constraints
+// on values declared in fOpts.
+func validateValue(v reflect.Value, fOpts fieldOptions) *ConstraintError {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if fOpts.min != nil || fOpts.max != nil {
+		format := formatNumber
+		if v.Type() == durationType {
+			format = formatDuration
+		}
+		n := numberOf(v)
+		if fOpts.min != nil && n < *fOpts.min {
+			return &ConstraintError{
+				Constraint: "min",
+				Limit:      format(*fOpts.min),
+				msg:        fmt.Sprintf("got %s, want at least %s", format(n), format(*fOpts.min)),
+			}
+		}
+		if fOpts.max != nil && n > *fOpts.max {
+			return &ConstraintError{
+				Constraint: "max",
+				Limit:      format(*fOpts.max),
+				msg:        fmt.Sprintf("got %s, want at most %s", format(n), format(*fOpts.max)),
+			}
+		}
+	}
+
+	if v.Type() == timeType && (fOpts.before != "" || fOpts.after != "") {
+		if err := validateTime(v.Interface().(time.Time), fOpts); err != nil {
+			return err
+		}
+	}
+
+	// The oneof option of a Sort declares the fields that may be sorted by.
+	if fOpts.oneOf != nil && v.Type() == sortType {
+		return validateSort(v.Interface().(Sort), fOpts.oneOf)
+	}
+
+	if fOpts.oneOf != nil {
+		s := fmt.Sprint(v.Interface())
+		if !slices.Contains(fOpts.oneOf, s) {
+			return &ConstraintError{
+				Constraint: "oneof",
+				Limit:      strings.Join(fOpts.oneOf, "|"),
+				msg:        fmt.Sprintf("got %q, want one of %s", s, strings.Join(fOpts.oneOf, ", ")),
+			}
+		}
+	}
+
+	if fOpts.pattern != nil {
+		if s := v.String(); !fOpts.pattern.MatchString(s) {
+			return &ConstraintError{
+				Constraint: "pattern",
+				Limit:      fOpts.pattern.String(),
+				msg:        fmt.Sprintf("got %q, want a match of %s", s, fOpts.pattern),
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkConstraintTypes returns an error if the constraints, and other options
+// on values, declared in fOpts are not supported by the type of a field.
+func checkConstraintTypes(typ reflect.Type, fOpts fieldOptions) error {
+	elem := typ
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() == reflect.Slice && holdsMultiple(reflect.New(elem).Elem()) {
+		elem = elem.Elem()
+	}
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	if (fOpts.min != nil || fOpts.max != nil) && !isNumber(elem) {
+		return fmt.Errorf("min and max options not supported by type %s", typ)
+	}
+	if fOpts.durationBounds && elem != durationType {
+		return fmt.Errorf("min and max options with durations not supported by type %s", typ)
+	}
+	if (fOpts.clampMin != nil || fOpts.clampMax != nil) && !isNumber(elem) {
+		return fmt.Errorf("clamp option not supported by type %s", typ)
+	}
+	if elem.Kind() != reflect.Float32 && elem.Kind() != reflect.Float64 {
+		for _, bound := range []*float64{fOpts.clampMin, fOpts.clampMax} {
+			if bound != nil && *bound != math.Trunc(*bound) {
+				return fmt.Errorf("clamp option with fractional bound %s not supported by type %s", formatNumber(*bound), typ)
+			}
+		}
+	}
+	if fOpts.finite && elem.Kind() != reflect.Float32 && elem.Kind() != reflect.Float64 {
+		return fmt.Errorf("finite option not supported by type %s", typ)
+	}
+	if (fOpts.trueValues != nil || fOpts.falseValues != nil) && elem.Kind() != reflect.Bool {
+		return fmt.Errorf("truevals and falsevals options not supported by type %s", typ)
+	}
+	for _, bound := range []struct{ name, value string }{{"before", fOpts.before}, {"after", fOpts.after}} {
+		if bound.value == "" {
+			continue
+		}
+		if elem != timeType {
+			return fmt.Errorf("%s option not supported by type %s", bound.name, typ)
+		}
+		if _, err := parseTimeBound(bound.value, fOpts); err != nil {
+			return fmt.Errorf("%s option has invalid value %q: %w", bound.name, bound.value, err)
+		}
+	}
+	if fOpts.pattern != nil && elem.Kind() != reflect.String {
+		return fmt.Errorf("pattern option not supported by type %s", typ)
+	}
+	return nil
+}
+
+// parseTimeBound parses the value of the "before" or "after" tag option, which
+// is either "now" based or in the layout of the field, or else in any of the
+// layouts of the "Auto" layout.
+func parseTimeBound(bound string, fOpts fieldOptions) (time.Time, error) {
+	t, err := parseTime(fOpts.layout, bound)
+	if err != nil {
+		if t, autoErr := parseAutoTime(bound); autoErr == nil {
+			return t, nil
+		}
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+// validateTime returns a ConstraintError if t is not strictly before the
+// bound of the "before" option, or strictly after the bound of the "after"
+// option. "now" based bounds are relative to the time of the call.
+func validateTime(t time.Time, fOpts fieldOptions) *ConstraintError {
+	if fOpts.before != "" {
+		// Bounds are checked when the tag is parsed.
+		bound, _ := parseTimeBound(fOpts.before, fOpts)
+		if !t.Before(bound) {
+			return &ConstraintError{
+				Constraint: "before",
+				Limit:      fOpts.before,
+				msg:        fmt.Sprintf("got %s, want before %s", t.Format(time.RFC3339), describeTimeBound(fOpts.before, bound)),
+			}
+		}
+	}
+	if fOpts.after != "" {
+		bound, _ := parseTimeBound(fOpts.after, fOpts)
+		if !t.After(bound) {
+			return &ConstraintError{
+				Constraint: "after",
+				Limit:      fOpts.after,
+				msg:        fmt.Sprintf("got %s, want after %s", t.Format(time.RFC3339), describeTimeBound(fOpts.after, bound)),
+			}
+		}
+	}
+	return nil
+}
+
+// describeTimeBound returns bound, parsed from s, formatted for messages.
+// "now" based bounds are followed by the time they resolved to.
+func describeTimeBound(s string, bound time.Time) string {
+	if isNowBased(s) {
+		return fmt.Sprintf("%s (%s)", s, bound.Format(time.RFC3339))
+	}
+	return bound.Format(time.RFC3339)
+}
+
+// parseClamp parses the value of the "clamp" tag option, a range such as
+// "1..100" of which either bound may be omitted.
+func parseClamp(s string) (lo, hi *float64, err error) {
+	min, max, ok := strings.Cut(s, "..")
+	if !ok || (strings.TrimSpace(min) == "" && strings.TrimSpace(max) == "") {
+		return nil, nil, errors.New("want a range such as 1..100")
+	}
+	for _, b := range []struct {
+		s   string
+		dst **float64
+	}{{min, &lo}, {max, &hi}} {
+		if b.s = strings.TrimSpace(b.s); b.s == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(b.s, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		*b.dst = &n
+	}
+	if lo != nil && hi != nil && *lo > *hi {
+		return nil, nil, errors.New("lower bound greater than upper bound")
+	}
+	return lo, hi, nil
+}
+
+// clampField clamps the number, or numbers, of field into the range of its
+// "clamp" option, emitting a warning for each number clamped.
+func clampField(field field, key string, pOpts *ParseOptions) {
+	fOpts := field.options
+	if fOpts.clampMin == nil && fOpts.clampMax == nil {
+		return
+	}
+
+	v := field.field
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	clamp := func(v reflect.Value) {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		n := numberOf(v)
+		switch {
+		case fOpts.clampMin != nil && n < *fOpts.clampMin:
+			setNumber(v, *fOpts.clampMin)
+		case fOpts.clampMax != nil && n > *fOpts.clampMax:
+			setNumber(v, *fOpts.clampMax)
+		default:
+			return
+		}
+		pOpts.warn(Warning{
+			FieldName: field.name,
+			Key:       key,
+			Message:   fmt.Sprintf("value %s clamped to %s", formatNumber(n), formatNumber(numberOf(v))),
+		})
+	}
+
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			clamp(v.Index(i))
+		}
+		return
+	}
+	clamp(v)
+}
+
+// setNumber sets the number v to f, truncated to an integer if v is one.
+func setNumber(v reflect.Value, f float64) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(f))
+	default:
+		v.SetFloat(f)
+	}
+}
+
+// isNumber reports whether typ is an integer or floating point number type.
+func isNumber(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// numberOf returns the value of the number v as a float64.
+func numberOf(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// formatDuration formats f, a number of nanoseconds, as a time.Duration.
+func formatDuration(f float64) string {
+	return time.Duration(f).String()
+}
+
+// itemCount returns the number of items in a slice or map field, or a pointer
+// to one. Nil pointers have no items.
+func itemCount(field reflect.Value) int {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return 0
+		}
+		field = field.Elem()
+	}
+	return field.Len()
+}
+
+// validatorsOf returns the validators named by the "validate" option of field,
+// or an error if any of them is not registered.
+func validatorsOf(field field, pOpts *ParseOptions) ([]ValidatorFunc, error) {
+	if len(field.options.validators) == 0 {
+		return nil, nil
+	}
+	fns := make([]ValidatorFunc, len(field.options.validators))
+	for i, name := range field.options.validators {
+		fn, ok := pOpts.validators[name]
+		if !ok {
+			return nil, fmt.Errorf("urlvalues: validator %q of field %s is not registered", name, field.name)
+		}
+		fns[i] = fn
+	}
+	return fns, nil
+}
+
+// runValidators passes each of values to fns, in order, returning a
+// ValidationError for the first value that is rejected. Values of fields
+// holding multiple values are split into their elements first, and all values
+// are normalized like before being parsed.
+func runValidators(fns []ValidatorFunc, field field, values []string, pOpts *ParseOptions) error {
+	if len(fns) == 0 {
+		return nil
+	}
+	if holdsMultiple(field.field) && (len(values) == 1 || pOpts.splitRepeated) {
+		var items []string
+		for _, v := range values {
+			items = append(items, pOpts.split(v)...)
+		}
+		values = items
+	}
+
+	for _, v := range values {
+		v = field.options.normalize(v)
+		for i, fn := range fns {
+			if err := fn(v); err != nil {
+				return &ValidationError{Validator: field.options.validators[i], err: err}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package urlvalues_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/nahojer/urlvalues"
+)
+
+func TestUnmarshal_ItemConstraints(t *testing.T) {
+	type Target struct {
+		IDs  []int             `urlvalue:"ids,minitems:1,maxitems:3"`
+		Meta map[string]string `urlvalue:"meta,maxitems:1"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.ConstraintError
+		wantKey string
+	}{
+		{"within constraints", url.Values{"ids": {"1;2;3"}, "meta": {"a:1"}}, nil, ""},
+		{"too few", url.Values{"ids": {}}, &urlvalues.ConstraintError{Constraint: "minitems", Limit: "1"}, "ids"},
+		{"too many", url.Values{"ids": {"1", "2", "3", "4"}}, &urlvalues.ConstraintError{Constraint: "maxitems", Limit: "3"}, "ids"},
+		{"map too many", url.Values{"ids": {"1"}, "meta": {"a:1;b:2"}}, &urlvalues.ConstraintError{Constraint: "maxitems", Limit: "1"}, "meta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target)
+
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var parseErr *urlvalues.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(parseErr).String())
+			}
+			if parseErr.Key != tt.wantKey {
+				t.Errorf("Key = %q, want %q", parseErr.Key, tt.wantKey)
+			}
+			if got := parseErr.Code(); got != urlvalues.CodeConstraint {
+				t.Errorf("Code() = %q, want %q", got, urlvalues.CodeConstraint)
+			}
+
+			var got *urlvalues.ConstraintError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr, cmpopts.IgnoreUnexported(urlvalues.ConstraintError{})); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			ID int `urlvalue:"id,maxitems:1"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_ValueConstraints(t *testing.T) {
+	type Target struct {
+		Limit  int      `urlvalue:"limit,default:20,min:1,max:100"`
+		Offset int      `urlvalue:"offset,min:0"`
+		Page   int      `urlvalue:"page,min:1"`
+		Kind   string   `urlvalue:"kind,oneof:post|page"`
+		Slugs  []string `urlvalue:"slugs,pattern:^[a-z-]+$"`
+		Scores []int    `urlvalue:"scores,min:0,max:10"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		in := url.Values{"kind": {"page"}, "slugs": {"a-b;c"}, "scores": {"0;10"}}
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.ConstraintError
+	}{
+		{"below min", url.Values{"limit": {"0"}}, &urlvalues.ConstraintError{Constraint: "min", Limit: "1"}},
+		{"above max", url.Values{"limit": {"101"}}, &urlvalues.ConstraintError{Constraint: "max", Limit: "100"}},
+		{"present zero value", url.Values{"page": {"0"}}, &urlvalues.ConstraintError{Constraint: "min", Limit: "1"}},
+		{"not one of", url.Values{"kind": {"comment"}}, &urlvalues.ConstraintError{Constraint: "oneof", Limit: "post|page"}},
+		{"pattern mismatch", url.Values{"slugs": {"Abc"}}, &urlvalues.ConstraintError{Constraint: "pattern", Limit: "^[a-z-]+$"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target)
+
+			var got *urlvalues.ConstraintError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr, cmpopts.IgnoreUnexported(urlvalues.ConstraintError{})); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("all failing elements", func(t *testing.T) {
+		in := url.Values{"scores": {"-1;5;11;3;12"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+
+		var errs urlvalues.Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want urlvalues.Errors", in, &target, err)
+		}
+		var gotIndexes []int
+		for _, err := range errs {
+			var elemErr *urlvalues.ElementError
+			if !errors.As(err, &elemErr) {
+				t.Fatalf("error %v is not a *urlvalues.ElementError", err)
+			}
+			gotIndexes = append(gotIndexes, elemErr.Index)
+		}
+		if diff := cmp.Diff(gotIndexes, []int{0, 2, 4}); diff != "" {
+			t.Errorf("failing indexes -got +want\n%s", diff)
+		}
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Name string `urlvalue:"name,min:1"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_Finite(t *testing.T) {
+	type Target struct {
+		Price  float64   `urlvalue:"price,finite"`
+		Ratio  float32   `urlvalue:"ratio"`
+		Scores []float64 `urlvalue:"scores,finite"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		opts    []urlvalues.SetParseOptionFunc
+		wantErr bool
+	}{
+		{"finite", url.Values{"price": {"1.5"}, "scores": {"1;-2e10"}}, nil, false},
+		{"nan", url.Values{"price": {"NaN"}}, nil, true},
+		{"inf", url.Values{"price": {"-Inf"}}, nil, true},
+		{"element", url.Values{"scores": {"1;+Infinity"}}, nil, true},
+		{"without option", url.Values{"ratio": {"nan"}}, nil, false},
+		{"WithFiniteFloats", url.Values{"ratio": {"inf"}}, []urlvalues.SetParseOptionFunc{urlvalues.WithFiniteFloats()}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target, tt.opts...)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &target, err)
+				}
+				return
+			}
+
+			var got *urlvalues.ConstraintError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, &urlvalues.ConstraintError{Constraint: "finite"}, cmpopts.IgnoreUnexported(urlvalues.ConstraintError{})); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Count int `urlvalue:"count,finite"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_Clamp(t *testing.T) {
+	type Target struct {
+		Limit  int      `urlvalue:"limit,default:20,clamp:1..100"`
+		Offset uint     `urlvalue:"offset,clamp:..1000"`
+		Ratio  *float64 `urlvalue:"ratio,clamp:0..1"`
+		Scores []int8   `urlvalue:"scores,clamp:0.."`
+	}
+
+	tests := []struct {
+		name         string
+		in           url.Values
+		want         Target
+		wantWarnings []urlvalues.Warning
+	}{
+		{"default", url.Values{}, Target{Limit: 20}, nil},
+		{"within range", url.Values{"limit": {"50"}, "ratio": {"0.5"}}, Target{Limit: 50, Ratio: ptr(0.5)}, nil},
+		{
+			name: "below min",
+			in:   url.Values{"limit": {"0"}},
+			want: Target{Limit: 1},
+			wantWarnings: []urlvalues.Warning{
+				{FieldName: "Limit", Key: "limit", Message: "value 0 clamped to 1"},
+			},
+		},
+		{
+			name: "above max",
+			in:   url.Values{"limit": {"500"}, "offset": {"5000"}, "ratio": {"1.5"}},
+			want: Target{Limit: 100, Offset: 1000, Ratio: ptr(1.0)},
+			wantWarnings: []urlvalues.Warning{
+				{FieldName: "Limit", Key: "limit", Message: "value 500 clamped to 100"},
+				{FieldName: "Offset", Key: "offset", Message: "value 5000 clamped to 1000"},
+				{FieldName: "Ratio", Key: "ratio", Message: "value 1.5 clamped to 1"},
+			},
+		},
+		{
+			name: "elements",
+			in:   url.Values{"scores": {"-1;5;-3"}},
+			want: Target{Limit: 20, Scores: []int8{0, 5, 0}},
+			wantWarnings: []urlvalues.Warning{
+				{FieldName: "Scores", Key: "scores", Message: "value -1 clamped to 0"},
+				{FieldName: "Scores", Key: "scores", Message: "value -3 clamped to 0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got      Target
+				warnings []urlvalues.Warning
+			)
+			err := urlvalues.Unmarshal(tt.in, &got, urlvalues.WithWarningHandler(func(w urlvalues.Warning) {
+				warnings = append(warnings, w)
+			}))
+			if err != nil {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+			}
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+			}
+			if diff := cmp.Diff(warnings, tt.wantWarnings); diff != "" {
+				t.Errorf("warnings -got +want\n%s", diff)
+			}
+		})
+	}
+
+	for _, tag := range []string{"clamp:1", "clamp:..", "clamp:a..b", "clamp:10..1", "clamp:0.5..10", "clamp:..9.5"} {
+		t.Run("invalid "+tag, func(t *testing.T) {
+			in := make(url.Values)
+			target := reflect.New(reflect.StructOf([]reflect.StructField{{
+				Name: "Limit",
+				Type: reflect.TypeFor[int](),
+				Tag:  reflect.StructTag(`urlvalue:"limit,` + tag + `"`),
+			}})).Interface()
+			if err := urlvalues.Unmarshal(in, target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, target)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_TimeBounds(t *testing.T) {
+	type Target struct {
+		Since time.Time   `urlvalue:"since,layout:DateOnly,after:2020-01-01,before:now"`
+		Until *time.Time  `urlvalue:"until,layout:RFC3339,before:2030-01-01"`
+		Dates []time.Time `urlvalue:"dates,layout:DateOnly,after:now-1d"`
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		in := url.Values{"since": {"2021-06-01"}, "until": {"2029-12-31T23:59:59Z"}, "dates": {time.Now().Format(time.DateOnly)}}
+		var target Target
+		if err := urlvalues.Unmarshal(in, &target); err != nil {
+			t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", in, &target, err)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		wantErr *urlvalues.ConstraintError
+	}{
+		{"not after", url.Values{"since": {"2020-01-01"}}, &urlvalues.ConstraintError{Constraint: "after", Limit: "2020-01-01"}},
+		{"not before now", url.Values{"since": {"2999-01-01"}}, &urlvalues.ConstraintError{Constraint: "before", Limit: "now"}},
+		{"not before", url.Values{"until": {"2030-01-01T00:00:00Z"}}, &urlvalues.ConstraintError{Constraint: "before", Limit: "2030-01-01"}},
+		{"element", url.Values{"dates": {"2000-01-01"}}, &urlvalues.ConstraintError{Constraint: "after", Limit: "now-1d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target Target
+			err := urlvalues.Unmarshal(tt.in, &target)
+
+			var got *urlvalues.ConstraintError
+			if !errors.As(err, &got) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &target, err, reflect.TypeOf(got).String())
+			}
+			if diff := cmp.Diff(got, tt.wantErr, cmpopts.IgnoreUnexported(urlvalues.ConstraintError{})); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		in := url.Values{"since": {"2019-12-31"}}
+		var target Target
+		err := urlvalues.Unmarshal(in, &target)
+		want := "error parsing value of since: got 2019-12-31T00:00:00Z, want after 2020-01-01T00:00:00Z"
+		if err == nil || err.Error() != want {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = %v, want %q", in, &target, err, want)
+		}
+	})
+
+	for _, tag := range []string{"before:tomorrow", "after:now+1x"} {
+		t.Run("invalid "+tag, func(t *testing.T) {
+			in := make(url.Values)
+			target := reflect.New(reflect.StructOf([]reflect.StructField{{
+				Name: "Since",
+				Type: reflect.TypeFor[time.Time](),
+				Tag:  reflect.StructTag(`urlvalue:"since,` + tag + `"`),
+			}})).Interface()
+			if err := urlvalues.Unmarshal(in, target); err == nil {
+				t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, target)
+			}
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Name string `urlvalue:"name,before:now"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
+
+func TestUnmarshal_DurationConstraints(t *testing.T) {
+	type Target struct {
+		Timeout time.Duration `urlvalue:"timeout,default:5s,min:100ms,max:1m"`
+	}
+
+	tests := []struct {
+		name    string
+		in      url.Values
+		want    Target
+		wantErr *urlvalues.ConstraintError
+		wantMsg string
+	}{
+		{"default", url.Values{}, Target{Timeout: 5 * time.Second}, nil, ""},
+		{"within bounds", url.Values{"timeout": {"100ms"}}, Target{Timeout: 100 * time.Millisecond}, nil, ""},
+		{
+			name:    "below min",
+			in:      url.Values{"timeout": {"1ms"}},
+			wantErr: &urlvalues.ConstraintError{Constraint: "min", Limit: "100ms"},
+			wantMsg: "error parsing value of timeout: got 1ms, want at least 100ms",
+		},
+		{
+			name:    "above max",
+			in:      url.Values{"timeout": {"1h"}},
+			wantErr: &urlvalues.ConstraintError{Constraint: "max", Limit: "1m0s"},
+			wantMsg: "error parsing value of timeout: got 1h0m0s, want at most 1m0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Target
+			err := urlvalues.Unmarshal(tt.in, &got)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("urlvalues.Unmarshal(%v, %v) = %q, want <nil>", tt.in, &got, err)
+				}
+				if diff := cmp.Diff(got, tt.want); diff != "" {
+					t.Errorf("urlvalues.Unmarshal(...) -got +want\n%s", diff)
+				}
+				return
+			}
+
+			var gotErr *urlvalues.ConstraintError
+			if !errors.As(err, &gotErr) {
+				t.Fatalf("urlvalues.Unmarshal(%v, %v) = %v, want %q", tt.in, &got, err, reflect.TypeOf(gotErr).String())
+			}
+			if diff := cmp.Diff(gotErr, tt.wantErr, cmpopts.IgnoreUnexported(urlvalues.ConstraintError{})); diff != "" {
+				t.Errorf("urlvalues.Unmarshal(...) error -got +want\n%s", diff)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("urlvalues.Unmarshal(...) = %q, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+
+	t.Run("duration bound of number field", func(t *testing.T) {
+		in := make(url.Values)
+		var target struct {
+			Count int `urlvalue:"count,min:1s"`
+		}
+		if err := urlvalues.Unmarshal(in, &target); err == nil {
+			t.Errorf("urlvalues.Unmarshal(%v, %v) = <nil>, want error", in, &target)
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package urlvalues
+
+import "fmt"
+
+// Warning describes a non-fatal event that occurred while decoding, such as
+// URL values being ignored.
+type Warning struct {
+	// Name of struct field, if the warning concerns a single field.
+	FieldName string
+	// Key into URL values.
+	Key string
+	// Human-readable description of the event.
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.FieldName != "" {
+		return fmt.Sprintf("urlvalues: warning for field %s (key %s): %s", w.FieldName, w.Key, w.Message)
+	}
+	return fmt.Sprintf("urlvalues: warning for key %s: %s", w.Key, w.Message)
+}
+
+// WarningHandlerFunc handles warnings emitted while decoding.
+type WarningHandlerFunc func(Warning)
+
+// warn passes w on to the warning handler, if any.
+func (o *ParseOptions) warn(w Warning) {
+	if o.warningHandler != nil {
+		o.warningHandler(w)
+	}
+}